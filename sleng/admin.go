@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+)
+
+// ————————————————————————
+//         Администрирование
+// ————————————————————————
+
+// Middleware для маршрутов /api/admin/: нужен действительный токен администратора.
+// Признак в токене проверяется первым, затем — в хранилище, чтобы снятие
+// прав действовало сразу, не дожидаясь истечения уже выданных токенов.
+func requireAdmin(s Store, next http.HandlerFunc) http.HandlerFunc {
+	return requireAuth(s, func(w http.ResponseWriter, r *http.Request) {
+		claims, err := parseToken(bearerToken(r))
+		if err != nil {
+			respondError(w, r, http.StatusUnauthorized, errCodeUnauthorized, trErr(r, err))
+			return
+		}
+		if !claims.Admin {
+			respondError(w, r, http.StatusForbidden, errCodeForbidden, tr(r, msgAdminOnly))
+			return
+		}
+		user, err := s.GetUser(claims.Subject)
+		if err != nil || !user.IsAdmin {
+			respondError(w, r, http.StatusForbidden, errCodeForbidden, tr(r, msgAdminOnly))
+			return
+		}
+		next(w, r)
+	})
+}
+
+// Пользователь в списке для администратора — без пароля
+type adminUserInfo struct {
+	Username string `json:"username"`
+	IsAdmin  bool   `json:"is_admin"`
+	Entries  int    `json:"entries"` // записей в словаре пользователя
+}
+
+// GET /api/admin/users
+func handleAdminUsers(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		slangData := loadSlangData(s)
+		users := make([]adminUserInfo, 0, len(slangData.Users))
+		for _, user := range slangData.Users {
+			users = append(users, adminUserInfo{
+				Username: user.Username,
+				IsAdmin:  user.IsAdmin,
+				Entries:  len(ownEntries(slangData.Entries, user.Username)),
+			})
+		}
+		respondJSON(w, r, http.StatusOK, users)
+	}
+}
+
+// DELETE /api/admin/entries/{id} — удаление записи любого пользователя (модерация).
+// Как и обычное удаление, понимает permanent и dryRun; remaining — записей у владельца.
+func handleAdminDeleteEntry(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var deleted []SlangEntry
+		var remaining int
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			index := findEntryByID(slangData.Entries, r.PathValue("id"))
+			if index < 0 {
+				return errEntryNotFound
+			}
+			entry := slangData.Entries[index]
+			deleted = []SlangEntry{entry}
+			removeEntry(slangData, index, r.URL.Query().Get("permanent") == "true")
+			remaining = len(ownEntries(slangData.Entries, entry.Owner))
+			return dryRunError(r)
+		})
+		if err == nil {
+			logger.Info("администратор удалил запись", "admin", usernameFromContext(r.Context()),
+				"id", deleted[0].ID, "word", deleted[0].Word, "owner", deleted[0].Owner)
+		}
+		respondDeleted(w, r, err, deleted, remaining)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// Токен администратора: пользователь помечается в хранилище, токен выдаётся с признаком admin
+func seedAdmin(t *testing.T, s Store, username, password string) string {
+	t.Helper()
+	seedUser(t, s, username, password)
+	user, err := s.GetUser(username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.IsAdmin = true
+	if err := s.SetUser(user); err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := issueToken(username, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAdminRoutes(t *testing.T) {
+	srv, store := newTestServer(t)
+	adminToken := seedAdmin(t, store, "root", "1234")
+	aliceToken := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии"},
+		SlangEntry{Word: "кринж", Meaning: "испанский стыд"},
+	)
+	crushID := loadSlangData(store).Entries[0].ID
+
+	// Без токена — 401, обычный пользователь — 403
+	expectError(t, doRequest(t, srv, "GET", "/api/admin/users", "", ""), http.StatusUnauthorized, errCodeUnauthorized)
+	expectError(t, doRequest(t, srv, "GET", "/api/admin/users", aliceToken, ""), http.StatusForbidden, errCodeForbidden)
+	expectError(t, doRequest(t, srv, "DELETE", "/api/admin/entries/"+crushID, aliceToken, ""), http.StatusForbidden, errCodeForbidden)
+
+	// Токен с признаком admin без прав в хранилище не подходит
+	forged, _, err := issueToken("alice", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, doRequest(t, srv, "GET", "/api/admin/users", forged, ""), http.StatusForbidden, errCodeForbidden)
+
+	resp := doRequest(t, srv, "GET", "/api/admin/users", adminToken, "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	var users []adminUserInfo
+	if err := json.Unmarshal(resp.body, &users); err != nil {
+		t.Fatal(err)
+	}
+	want := []adminUserInfo{{"root", true, 0}, {"alice", false, 2}}
+	if len(users) != len(want) || users[0] != want[0] || users[1] != want[1] {
+		t.Errorf("users = %+v, want %+v", users, want)
+	}
+
+	// Администратор удаляет чужую запись; она попадает в корзину владельца
+	resp = doRequest(t, srv, "DELETE", "/api/admin/entries/"+crushID, adminToken, "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("удаление: status = %d; body %s", resp.status, resp.body)
+	}
+	slangData := loadSlangData(store)
+	if len(slangData.Entries) != 1 || len(slangData.Trash) != 1 || slangData.Trash[0].Owner != "alice" {
+		t.Errorf("записей %d, в корзине %+v", len(slangData.Entries), slangData.Trash)
+	}
+	expectError(t, doRequest(t, srv, "DELETE", "/api/admin/entries/"+crushID, adminToken, ""), http.StatusNotFound, errCodeNotFound)
+}
+
+func TestFirstUserIsAdmin(t *testing.T) {
+	srv, store := newTestServer(t)
+	for _, username := range []string{"alice", "bob"} {
+		resp := doRequest(t, srv, "POST", "/api/register", "", `{"username":"`+username+`","password":"1234"}`)
+		if resp.status != http.StatusCreated {
+			t.Fatalf("%s: status = %d; body %s", username, resp.status, resp.body)
+		}
+	}
+	for username, want := range map[string]bool{"alice": true, "bob": false} {
+		user, err := store.GetUser(username)
+		if err != nil || user.IsAdmin != want {
+			t.Errorf("%s: IsAdmin = %v (%v), want %v", username, user.IsAdmin, err, want)
+		}
+	}
+}
+
+// Резервные копии откатывают данные всех пользователей, поэтому доступны только
+// администратору. С отключёнными копиями восстановление не оставляет лишних файлов.
+func TestBackupRoutesAdminOnly(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(filepath.Join(dir, "slang.json"))
+	store.backupDir = filepath.Join(dir, "backups")
+	srv := httptest.NewServer(newRouter(store))
+	t.Cleanup(srv.Close)
+	adminToken := seedAdmin(t, store, "root", "1234")
+	aliceToken := seedUser(t, store, "alice", "1234", SlangEntry{Word: "краш", Meaning: "объект симпатии"})
+
+	for _, route := range [][2]string{{"POST", "/api/backup"}, {"GET", "/api/backups"}, {"POST", "/api/restore"}} {
+		expectError(t, doRequest(t, srv, route[0], route[1], aliceToken, `{"name":"x"}`), http.StatusForbidden, errCodeForbidden)
+	}
+
+	resp := doRequest(t, srv, "POST", "/api/backup", adminToken, "")
+	if resp.status != http.StatusCreated {
+		t.Fatalf("копия: status = %d; body %s", resp.status, resp.body)
+	}
+	name := resp.field(t, "name")
+	seedUser(t, store, "bob", "1234", SlangEntry{Word: "кринж", Meaning: "испанский стыд"})
+
+	resp = doRequest(t, srv, "POST", "/api/restore", adminToken, `{"name":"`+name+`"}`)
+	if resp.status != http.StatusOK {
+		t.Fatalf("восстановление: status = %d; body %s", resp.status, resp.body)
+	}
+	if entries := loadSlangData(store).Entries; len(entries) != 1 || entries[0].Word != "краш" {
+		t.Errorf("после восстановления: %+v", entries)
+	}
+	backups, err := store.Backups()
+	if err != nil || len(backups) != 1 {
+		t.Errorf("копий %d (%v), want 1", len(backups), err)
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Хеш bcrypt всегда начинается с одного из этих префиксов
+func isBcryptHash(s string) bool {
+	return strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$")
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Проверка пароля пользователя. В старых файлах пароль мог храниться
+// в открытом виде — такие пароли сравниваются напрямую.
+func checkPassword(user User, password string) bool {
+	if user.legacyPassword {
+		return subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
+}
+
+// Одноразовая миграция: после успешного входа заменяем открытый пароль на хеш
+func upgradeLegacyPassword(s Store, user *User, password string) {
+	if !user.legacyPassword {
+		return
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return
+	}
+	user.Password = hash
+	user.legacyPassword = false
+	if err := s.SetUser(*user); err != nil {
+		logger.Error("не удалось сохранить хеш пароля", "user", user.Username, "error", err)
+	}
+}
+
+// Минимальная длина пароля по умолчанию (SLENG_PASSWORD_MIN_LENGTH)
+const minPasswordLength = 4
+
+// bcrypt не хеширует пароли длиннее 72 байт — такие отклоняются при проверке,
+// а ошибка hashPassword остаётся сбоем сервера
+const maxPasswordBytes = 72
+
+var errWrongPassword = newMsgError(msgWrongCurrentPassword)
+
+// Смена пароля после проверки текущего; данные меняются только в памяти
+func changePassword(user *User, current, newPassword string) error {
+	if !checkPassword(*user, current) {
+		return errWrongPassword
+	}
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+	hash, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	setPassword(user, hash)
+	return nil
+}
+
+// Новый хеш пароля после смены или сброса. Время смены отсекает токены, выданные
+// со старым паролем: после утечки пароля их нельзя использовать до истечения срока.
+func setPassword(user *User, hash string) {
+	user.Password = hash
+	user.legacyPassword = false
+	user.PasswordChangedAt = time.Now().UTC()
+}
+
+// Удаление пользователя; с withEntries удаляется и его словарь, иначе слова остаются
+// в общем словаре. Возвращает число удалённых записей.
+func deleteUser(slangData *SlangData, username string, withEntries bool) int {
+	username = normalizeUsername(username)
+	slangData.Users = slices.DeleteFunc(slangData.Users, func(u User) bool {
+		return normalizeUsername(u.Username) == username
+	})
+	if !withEntries {
+		return 0
+	}
+	before := len(slangData.Entries)
+	slangData.Entries = slices.DeleteFunc(slangData.Entries, func(e SlangEntry) bool {
+		if e.Owner == username {
+			forgetEntry(slangData, e.ID)
+			return true
+		}
+		return false
+	})
+	slangData.Trash = slices.DeleteFunc(slangData.Trash, func(t trashedEntry) bool {
+		if t.Owner == username {
+			forgetEntry(slangData, t.ID)
+			return true
+		}
+		return false
+	})
+	return before - len(slangData.Entries)
+}
+
+// Логины уникальны без учёта регистра и хранятся в нижнем регистре
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Поиск пользователя по логину; возвращает указатель на элемент slangData.Users
+func findUser(slangData *SlangData, username string) *User {
+	username = normalizeUsername(username)
+	if username == "" {
+		return nil
+	}
+	for i := range slangData.Users {
+		if normalizeUsername(slangData.Users[i].Username) == username {
+			return &slangData.Users[i]
+		}
+	}
+	return nil
+}
+
+// ————————————————————————
+//         JWT-токены
+// ————————————————————————
+
+// Время жизни токена, выданного при входе
+const tokenTTL = 24 * time.Hour
+
+// Секрет для подписи токенов берётся из переменной окружения SLENG_JWT_SECRET.
+// Если она не задана, генерируется случайный секрет, и токены перестают
+// действовать после перезапуска.
+var jwtSecret = loadJWTSecret()
+
+func loadJWTSecret() []byte {
+	if secret := os.Getenv("SLENG_JWT_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	// В stderr, чтобы не смешиваться с выводом подкоманд
+	fmt.Fprintln(os.Stderr, "⚠️  SLENG_JWT_SECRET не задан, используется случайный секрет")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return secret
+}
+
+type tokenClaims struct {
+	// Уникальный номер токена, по нему токен отзывается при выходе
+	ID      string `json:"jti"`
+	Subject string `json:"sub"`
+	// Администратор на момент входа; requireAdmin дополнительно сверяется с хранилищем
+	Admin bool `json:"admin,omitempty"`
+	// Секунды с долями (до микросекунды): токен, выданный в ту же секунду, но до
+	// смены пароля, должен отличаться от выданного сразу после неё
+	IssuedAt  float64 `json:"iat"`
+	ExpiresAt int64   `json:"exp"`
+}
+
+func (c tokenClaims) issuedAt() time.Time {
+	return time.UnixMicro(int64(math.Round(c.IssuedAt * 1e6)))
+}
+
+var (
+	errTokenInvalid = newMsgError(msgTokenInvalid)
+	errTokenExpired = newMsgError(msgTokenExpired)
+	errTokenRevoked = newMsgError(msgTokenRevoked)
+	errTokenOrphan  = newMsgError(msgTokenOrphan)
+	errTokenStale   = newMsgError(msgTokenStale)
+)
+
+func signToken(data string) string {
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Выпуск подписанного HS256 токена для пользователя
+func issueToken(username string, admin bool) (string, time.Time, error) {
+	now := time.Now()
+	expires := now.Add(tokenTTL)
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, err := json.Marshal(tokenClaims{
+		ID:        uuid.NewString(),
+		Subject:   username,
+		Admin:     admin,
+		IssuedAt:  float64(now.UnixMicro()) / 1e6,
+		ExpiresAt: expires.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + signToken(unsigned), expires, nil
+}
+
+// Проверка подписи, срока действия и того, что токен не отозван
+func parseToken(token string) (tokenClaims, error) {
+	var claims tokenClaims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, errTokenInvalid
+	}
+	expected := signToken(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return claims, errTokenInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, errTokenInvalid
+	}
+	// Токены без jti (выданные до появления выхода) отозвать нельзя, поэтому не принимаем их
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" || claims.ID == "" {
+		return claims, errTokenInvalid
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return claims, errTokenExpired
+	}
+	if revokedTokens.isRevoked(claims.ID) {
+		return claims, errTokenRevoked
+	}
+	return claims, nil
+}
+
+type contextKey string
+
+const usernameKey contextKey = "username"
+
+// Имя пользователя, прошедшего проверку токена
+func usernameFromContext(ctx context.Context) string {
+	username, _ := ctx.Value(usernameKey).(string)
+	return username
+}
+
+// Токен из заголовка Authorization: Bearer; пустая строка, если заголовка нет
+func bearerToken(r *http.Request) string {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Токен действует, только пока есть его пользователь: после удаления аккаунта
+// отказывают все его токены, а не только тот, которым аккаунт удалили. Токены,
+// выданные до регистрации, — прежнему владельцу того же логина — тоже не подходят.
+// Так же отказывают токены, выданные до последней смены пароля или в тот же момент.
+// iat точен до микросекунды, поэтому и время сравнивается с той же точностью.
+func checkTokenUser(s Store, claims tokenClaims) error {
+	user, err := s.GetUser(claims.Subject)
+	if errors.Is(err, errUserNotFound) {
+		return errTokenOrphan
+	}
+	if err != nil {
+		return err
+	}
+	issued := claims.issuedAt()
+	if issued.Before(user.CreatedAt.Truncate(time.Microsecond)) {
+		return errTokenOrphan
+	}
+	if !issued.After(user.PasswordChangedAt.Truncate(time.Microsecond)) {
+		return errTokenStale
+	}
+	return nil
+}
+
+// Пользователь из заголовка Authorization: Bearer; пустая строка, если заголовка нет
+func authenticate(s Store, r *http.Request) (string, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return "", nil
+	}
+	claims, err := parseToken(token)
+	if err != nil {
+		return "", err
+	}
+	if err := checkTokenUser(s, claims); err != nil {
+		return "", err
+	}
+	return claims.Subject, nil
+}
+
+// Ответ на ошибку authenticate: недействительный токен — 401, сбой хранилища
+// при поиске пользователя токена — 500
+func respondAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	var tokenErr *msgError
+	if errors.As(err, &tokenErr) {
+		respondError(w, r, http.StatusUnauthorized, errCodeUnauthorized, trErr(r, err))
+		return
+	}
+	respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgTokenCheckFailed))
+}
+
+// Middleware, пропускающий запрос только с действительным заголовком Authorization: Bearer
+func requireAuth(s Store, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := authenticate(s, r)
+		if err != nil {
+			respondAuthError(w, r, err)
+			return
+		}
+		if username == "" {
+			respondError(w, r, http.StatusUnauthorized, errCodeUnauthorized, tr(r, msgAuthRequired))
+			return
+		}
+		ctx := context.WithValue(r.Context(), usernameKey, username)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// Middleware для открытых маршрутов: токен необязателен, но если он передан,
+// то должен быть действительным, а пользователь попадает в контекст запроса
+func optionalAuth(s Store, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := authenticate(s, r)
+		if err != nil {
+			respondAuthError(w, r, err)
+			return
+		}
+		if username != "" {
+			r = r.WithContext(context.WithValue(r.Context(), usernameKey, username))
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Резервная копия файла словаря
+type backupInfo struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Хранилище, умеющее делать резервные копии (пока только JSON-файл)
+type backupStore interface {
+	Backup() (backupInfo, error)
+	Backups() ([]backupInfo, error)
+	Restore(name string) (SlangData, error)
+}
+
+var (
+	errNothingToBackup = errors.New("файл словаря ещё не создан")
+	errUnknownBackup   = newMsgError(msgUnknownBackup)
+	errBadBackup       = newMsgError(msgBadBackup)
+)
+
+// Метка времени в имени копии; с миллисекундами, чтобы частые сохранения не затирали друг друга,
+// и в таком виде, чтобы копии сортировались по имени
+const backupTimeFormat = "20060102-150405.000"
+
+// Имя копии: slang-20261016-145032.123.json
+func (s *FileStore) backupName(t time.Time) string {
+	ext := filepath.Ext(s.path)
+	base := strings.TrimSuffix(filepath.Base(s.path), ext)
+	return base + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// Время создания копии по её имени; ok == false, если файл — не копия этого словаря
+func (s *FileStore) backupTime(name string) (time.Time, bool) {
+	ext := filepath.Ext(s.path)
+	base := strings.TrimSuffix(filepath.Base(s.path), ext)
+	stamp, found := strings.CutPrefix(name, base+"-")
+	if !found {
+		return time.Time{}, false
+	}
+	stamp, found = strings.CutSuffix(stamp, ext)
+	if !found {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(backupTimeFormat, stamp)
+	return t, err == nil
+}
+
+// Копирование текущего файла в папку копий и удаление самых старых копий сверх keepBackups.
+// Вызывается под s.mu.
+func (s *FileStore) backupLocked() (backupInfo, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return backupInfo{}, errNothingToBackup
+	}
+	if err != nil {
+		return backupInfo{}, err
+	}
+	if err := os.MkdirAll(s.backupDir, 0755); err != nil {
+		return backupInfo{}, err
+	}
+
+	now := time.Now()
+	name := s.backupName(now)
+	if err := writeFileAtomic(filepath.Join(s.backupDir, name), data, 0644); err != nil {
+		return backupInfo{}, err
+	}
+	if err := s.pruneBackups(); err != nil {
+		return backupInfo{}, err
+	}
+	created, _ := s.backupTime(name)
+	return backupInfo{Name: name, Size: int64(len(data)), CreatedAt: created}, nil
+}
+
+// Удаление самых старых копий, если их больше keepBackups
+func (s *FileStore) pruneBackups() error {
+	if s.keepBackups <= 0 {
+		return nil
+	}
+	backups, err := s.listBackups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > s.keepBackups {
+		if err := os.Remove(filepath.Join(s.backupDir, backups[len(backups)-1].Name)); err != nil {
+			return err
+		}
+		backups = backups[:len(backups)-1]
+	}
+	return nil
+}
+
+// Копии от новых к старым
+func (s *FileStore) listBackups() ([]backupInfo, error) {
+	files, err := os.ReadDir(s.backupDir)
+	if os.IsNotExist(err) {
+		return []backupInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	backups := []backupInfo{}
+	for _, file := range files {
+		created, ok := s.backupTime(file.Name())
+		if file.IsDir() || !ok {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, backupInfo{Name: file.Name(), Size: info.Size(), CreatedAt: created})
+	}
+	slices.SortFunc(backups, func(a, b backupInfo) int {
+		return strings.Compare(b.Name, a.Name)
+	})
+	return backups, nil
+}
+
+func (s *FileStore) Backup() (backupInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backupLocked()
+}
+
+func (s *FileStore) Backups() ([]backupInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listBackups()
+}
+
+// Восстановление словаря из копии name. Если копии включены (keepBackups > 0), перед
+// заменой текущий файл тоже копируется, так что восстановление можно отменить.
+// Возвращает восстановленные данные.
+func (s *FileStore) Restore(name string) (SlangData, error) {
+	// Только имя файла из папки копий, без путей вроде ../../etc/passwd
+	if name != filepath.Base(name) {
+		return SlangData{}, errUnknownBackup
+	}
+	if _, ok := s.backupTime(name); !ok {
+		return SlangData{}, errUnknownBackup
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(s.backupDir, name))
+	if os.IsNotExist(err) {
+		return SlangData{}, errUnknownBackup
+	}
+	if err != nil {
+		return SlangData{}, err
+	}
+	var slangData SlangData
+	if err := json.Unmarshal(data, &slangData); err != nil {
+		return SlangData{}, errBadBackup
+	}
+
+	if s.keepBackups > 0 {
+		if _, err := s.backupLocked(); err != nil && !errors.Is(err, errNothingToBackup) {
+			return SlangData{}, fmt.Errorf("не удалось сохранить текущие данные: %w", err)
+		}
+	}
+	s.cache = nil
+	if err := writeFileAtomic(s.path, data, 0644); err != nil {
+		return SlangData{}, fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	return slangData, nil
+}
+
+// POST /api/backup — сделать резервную копию словаря прямо сейчас
+func handleBackup(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bs, ok := s.(backupStore)
+		if !ok {
+			respondError(w, r, http.StatusNotImplemented, errCodeNotImplemented, tr(r, msgBackupsUnsupported))
+			return
+		}
+		backup, err := bs.Backup()
+		if errors.Is(err, errNothingToBackup) {
+			respondError(w, r, http.StatusNotFound, errCodeNotFound, tr(r, msgNothingToBackup))
+			return
+		}
+		if err != nil {
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgBackupFailed, err))
+			return
+		}
+		respondJSON(w, r, http.StatusCreated, backup)
+	}
+}
+
+// GET /api/backups — список резервных копий, от новых к старым
+func handleListBackups(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bs, ok := s.(backupStore)
+		if !ok {
+			respondError(w, r, http.StatusNotImplemented, errCodeNotImplemented, tr(r, msgBackupsUnsupported))
+			return
+		}
+		backups, err := bs.Backups()
+		if err != nil {
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgBackupListFailed))
+			return
+		}
+		respondJSON(w, r, http.StatusOK, backups)
+	}
+}
+
+// POST /api/restore — восстановить словарь из резервной копии {"name": "slang-....json"}.
+// Клиенты /api/ws, /api/events и webhooks получают разницу между словарём до и после
+// восстановления, как после любого другого изменения.
+func handleRestore(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bs, ok := s.(backupStore)
+		if !ok {
+			respondError(w, r, http.StatusNotImplemented, errCodeNotImplemented, tr(r, msgBackupsUnsupported))
+			return
+		}
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := readJSON(w, r, &req); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+
+		before := loadSlangData(s).Entries
+		slangData, err := bs.Restore(req.Name)
+		if errors.Is(err, errUnknownBackup) || errors.Is(err, errBadBackup) {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+		if err != nil {
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgRestoreFailed, err))
+			return
+		}
+		// Копия могла остаться от старой версии: события — по данным после миграции
+		migrateSlangData(&slangData)
+		publishChanges(before, slangData.Entries)
+		respondJSON(w, r, http.StatusOK, map[string]any{
+			"message": tr(r, msgRestored, req.Name),
+			"entries": len(slangData.Entries),
+		})
+	}
+}
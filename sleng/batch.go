@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ————————————————————————
+//         Несколько записей за один запрос
+// ————————————————————————
+
+// Больше записей за раз не отдаём: для всего словаря есть GET /api/entries
+const maxBatchSize = 100
+
+// Ответ GET /api/entries/batch. Записи идут в порядке запроса; на месте
+// ненайденной — null, а сам ключ попадает в missing
+type entryBatch struct {
+	Entries []interface{} `json:"entries"` // запись или её поля из ?fields=
+	Missing []string      `json:"missing"`
+}
+
+// Ключи из ?ids= или ?indices= через запятую (ровно один из параметров)
+func parseBatchKeys(r *http.Request) (keys []string, byID bool, err error) {
+	query := r.URL.Query()
+	ids, indices := query.Get("ids"), query.Get("indices")
+	if (ids == "") == (indices == "") {
+		return nil, false, newMsgError(msgBatchKeysRequired)
+	}
+	raw := indices
+	if ids != "" {
+		raw, byID = ids, true
+	}
+	for _, key := range strings.Split(raw, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	switch {
+	case len(keys) == 0:
+		return nil, false, newMsgError(msgBatchKeysRequired)
+	case len(keys) > maxBatchSize:
+		return nil, false, newMsgError(msgBatchTooLarge, len(keys), maxBatchSize)
+	}
+	if !byID {
+		for _, key := range keys {
+			if n, err := strconv.Atoi(key); err != nil || n < 1 {
+				return nil, false, newMsgError(msgInvalidBatchIndex, key)
+			}
+		}
+	}
+	return keys, byID, nil
+}
+
+// GET /api/entries/batch?ids=a,b,c или ?indices=1,5,7 — несколько записей сразу.
+// Номера — как у GET /api/entries/{index} (свой словарь или с ?shared=true общий),
+// ID — как у GET /api/entry/{id}; ?fields= оставляет только указанные поля.
+func handleEntryBatch(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keys, byID, err := parseBatchKeys(r)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+		fields, err := parseFieldsParam(r)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+
+		slangData := loadSlangData(s)
+		username := usernameFromContext(r.Context())
+		visible := visibleIndexes(r, slangData.Entries)
+		batch := entryBatch{Entries: make([]interface{}, len(keys)), Missing: []string{}}
+		for n, key := range keys {
+			i := -1
+			if byID {
+				if i = findEntryByID(slangData.Entries, key); i >= 0 && !canSee(slangData.Entries[i], username) {
+					i = -1
+				}
+			} else if index, _ := strconv.Atoi(key); index <= len(visible) {
+				i = visible[index-1]
+			}
+			if i < 0 {
+				batch.Missing = append(batch.Missing, key)
+				continue
+			}
+			batch.Entries[n] = selectEntryFields(slangData.Entries[i], fields)
+		}
+		respondJSON(w, r, http.StatusOK, batch)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEntryBatch(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии"},
+		SlangEntry{Word: "рофл", Meaning: "шутка"},
+		SlangEntry{Word: "кринж", Meaning: "стыд", Private: true},
+	)
+	seedUser(t, store, "bob", "1234", SlangEntry{Word: "изи", Meaning: "легко", Private: true})
+	ids := map[string]string{}
+	for _, e := range loadSlangData(store).Entries {
+		ids[e.Word] = e.ID
+	}
+
+	words := func(resp testResponse) ([]string, []string) {
+		t.Helper()
+		var batch struct {
+			Entries []*SlangEntry `json:"entries"`
+			Missing []string      `json:"missing"`
+		}
+		if resp.status != http.StatusOK {
+			t.Fatalf("status = %d; body %s", resp.status, resp.body)
+		}
+		if err := json.Unmarshal(resp.body, &batch); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range batch.Entries {
+			if e == nil {
+				got = append(got, "")
+			} else {
+				got = append(got, e.Word)
+			}
+		}
+		return got, batch.Missing
+	}
+
+	tests := []struct {
+		path, token string
+		words       []string
+		missing     []string
+	}{
+		// Порядок — как в запросе, повторы сохраняются
+		{"/api/entries/batch?indices=3,1,1", token, []string{"кринж", "краш", "краш"}, []string{}},
+		{"/api/entries/batch?indices=2,9", token, []string{"рофл", ""}, []string{"9"}},
+		{"/api/entries/batch?ids=" + ids["рофл"] + ",нет," + ids["краш"], token, []string{"рофл", "", "краш"}, []string{"нет"}},
+		// Чужое личное слово по ID не видно, своё — видно
+		{"/api/entries/batch?ids=" + ids["изи"] + "," + ids["кринж"], token, []string{"", "кринж"}, []string{ids["изи"]}},
+		// Без токена номера — в общем словаре, где личных слов нет
+		{"/api/entries/batch?indices=1,2,3", "", []string{"краш", "рофл", ""}, []string{"3"}},
+	}
+	for _, tt := range tests {
+		got, missing := words(doRequest(t, srv, "GET", tt.path, tt.token, ""))
+		if strings.Join(got, ",") != strings.Join(tt.words, ",") || strings.Join(missing, ",") != strings.Join(tt.missing, ",") {
+			t.Errorf("%s: записи %q, missing %q; ожидались %q, %q", tt.path, got, missing, tt.words, tt.missing)
+		}
+	}
+
+	resp := doRequest(t, srv, "GET", "/api/entries/batch?indices=1&fields=word", token, "")
+	if resp.status != http.StatusOK || strings.TrimSpace(string(resp.body)) != `{"entries":[{"word":"краш"}],"missing":[]}` {
+		t.Errorf("с fields: status = %d; body %s", resp.status, resp.body)
+	}
+
+	tooMany := strings.Repeat("1,", maxBatchSize) + "1"
+	for _, path := range []string{
+		"/api/entries/batch",
+		"/api/entries/batch?ids=a&indices=1",
+		"/api/entries/batch?indices=,",
+		"/api/entries/batch?indices=1,x",
+		"/api/entries/batch?indices=0",
+		"/api/entries/batch?indices=" + tooMany,
+		"/api/entries/batch?ids=a&fields=nope",
+	} {
+		expectError(t, doRequest(t, srv, "GET", path, token, ""), http.StatusBadRequest, errCodeInvalidParameter)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// ————————————————————————
+//         Удаление нескольких записей
+// ————————————————————————
+
+// Результат массового удаления. С dryRun — что было бы удалено.
+type bulkDeleteResult struct {
+	DryRun    bool         `json:"dryRun,omitempty"`
+	Deleted   int          `json:"deleted"`
+	NotFound  int          `json:"notFound"`
+	Missing   []string     `json:"missing"` // ID и слова, которых нет в словаре пользователя
+	Entries   []SlangEntry `json:"entries"` // удалённые записи
+	Remaining int          `json:"remaining"`
+}
+
+// Номера (с 0, по возрастанию) записей владельца, заданных ID или словом,
+// и ключи, которым ничего не нашлось. Повторы одной записи считаются один раз.
+func resolveDeleteKeys(entries []SlangEntry, owner string, keys []string) (indexes []int, missing []string) {
+	missing = []string{}
+	seen := make(map[int]bool)
+	for _, key := range keys {
+		i := findEntryByID(entries, key)
+		if i < 0 || entries[i].Owner != owner {
+			i = findOwnEntryIndex(entries, owner, key)
+		}
+		if i < 0 {
+			missing = append(missing, key)
+			continue
+		}
+		if !seen[i] {
+			seen[i] = true
+			indexes = append(indexes, i)
+		}
+	}
+	slices.Sort(indexes)
+	return indexes, missing
+}
+
+// POST /api/entries/delete — удаляет записи по массиву ID или слов ["id", "краш", ...]
+// одним сохранением. Понимает permanent (насовсем, минуя корзину) и dryRun.
+func handleBulkDelete(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var keys []string
+		if err := readJSON(w, r, &keys); err != nil || len(keys) == 0 {
+			respondReadError(w, r, err, msgInvalidDeleteList)
+			return
+		}
+
+		username := usernameFromContext(r.Context())
+		permanent := r.URL.Query().Get("permanent") == "true"
+		var result bulkDeleteResult
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			indexes, missing := resolveDeleteKeys(slangData.Entries, username, keys)
+			result = bulkDeleteResult{
+				Deleted:  len(indexes),
+				NotFound: len(missing),
+				Missing:  missing,
+				Entries:  pickEntries(slangData.Entries, indexes),
+			}
+			// С конца, чтобы номера ещё не удалённых записей не сдвигались
+			for _, i := range slices.Backward(indexes) {
+				removeEntry(slangData, i, permanent)
+			}
+			result.Remaining = len(ownEntries(slangData.Entries, username))
+			if len(indexes) == 0 {
+				// Сохранять нечего: отменяем сохранение так же, как при dryRun
+				return errDryRun
+			}
+			return dryRunError(r)
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			respondModifyError(w, r, err)
+			return
+		}
+		result.DryRun = dryRunError(r) != nil
+		respondJSON(w, r, http.StatusOK, result)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestBulkDelete(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии"},
+		SlangEntry{Word: "кринж", Meaning: "испанский стыд"},
+		SlangEntry{Word: "рофл", Meaning: "шутка"},
+	)
+	seedUser(t, store, "bob", "1234", SlangEntry{Word: "изи", Meaning: "легко"})
+	seeded := loadSlangData(store)
+	bobEntry := seeded.Entries[3]
+
+	expectError(t, doRequest(t, srv, "POST", "/api/entries/delete", "", `["краш"]`), http.StatusUnauthorized, errCodeUnauthorized)
+	for _, body := range []string{`[]`, `{"ids":["1"]}`, `[1, 2]`} {
+		expectError(t, doRequest(t, srv, "POST", "/api/entries/delete", token, body), http.StatusBadRequest, errCodeInvalidJSON)
+	}
+
+	// ID и слово одной записи дают одно удаление; чужая запись не находится
+	body := `["` + seeded.Entries[0].ID + `", "Краш", "рофл", "нет-такого", "` + bobEntry.ID + `"]`
+	tests := []struct {
+		name        string
+		query       string
+		wantEntries int
+		wantTrash   int
+	}{
+		{"пробный запуск", "?dryRun=true", 4, 0},
+		{"удаление", "", 2, 2},
+	}
+	for _, tt := range tests {
+		resp := doRequest(t, srv, "POST", "/api/entries/delete"+tt.query, token, body)
+		if resp.status != http.StatusOK {
+			t.Fatalf("%s: status = %d; body %s", tt.name, resp.status, resp.body)
+		}
+		var result bulkDeleteResult
+		if err := json.Unmarshal(resp.body, &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Deleted != 2 || result.NotFound != 2 || result.Remaining != 1 || result.DryRun != (tt.query != "") {
+			t.Errorf("%s: %+v", tt.name, result)
+		}
+		if len(result.Entries) != 2 || result.Entries[0].Word != "краш" || result.Entries[1].Word != "рофл" {
+			t.Errorf("%s: удалённые записи %+v", tt.name, result.Entries)
+		}
+		if len(result.Missing) != 2 || result.Missing[0] != "нет-такого" || result.Missing[1] != bobEntry.ID {
+			t.Errorf("%s: не найдены %v", tt.name, result.Missing)
+		}
+
+		slangData := loadSlangData(store)
+		if len(slangData.Entries) != tt.wantEntries || len(slangData.Trash) != tt.wantTrash {
+			t.Errorf("%s: записей %d, в корзине %d; want %d, %d", tt.name,
+				len(slangData.Entries), len(slangData.Trash), tt.wantEntries, tt.wantTrash)
+		}
+	}
+
+	resp := doRequest(t, srv, "POST", "/api/entries/delete?permanent=true", token, `["кринж"]`)
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	if slangData := loadSlangData(store); len(slangData.Entries) != 1 || len(slangData.Trash) != 2 {
+		t.Errorf("после удаления насовсем: записи %+v, корзина %+v", slangData.Entries, slangData.Trash)
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ————————————————————————
+//         Подкоманды для скриптов
+// ————————————————————————
+
+// sleng [флаги] <команда> [флаги команды] — работа со словарём без меню и без
+// запуска API. Команды обращаются к хранилищу напрямую, поэтому пароль не нужен:
+// кто может запустить sleng с файлом словаря, тот может и прочитать файл.
+// Результат — по строке на запись: номер, слово и значение через табуляцию.
+// Номера те же, что в меню и в /api/entries/{index}.
+var commands = map[string]func(s Store, args []string, out, errOut io.Writer) int{
+	"list":   cmdList,
+	"search": cmdSearch,
+	"add":    cmdAdd,
+	"delete": cmdDelete,
+}
+
+// Вывод консоли в JSON (флаг -json): записи и результаты команд — в stdout,
+// ошибки команд — {"error": "..."} в stderr. Задаётся при запуске.
+var jsonOutput bool
+
+// Коды выхода подкоманд
+const (
+	exitOK      = 0
+	exitFailure = 1 // операция не удалась или поиск ничего не нашёл
+	exitUsage   = 2 // неизвестная команда или неверные флаги, как и у флагов запуска
+)
+
+func runCommand(s Store, args []string, out, errOut io.Writer) int {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		commandError(errOut, t(msgUnknownCommand, args[0], strings.Join(names, ", ")))
+		return exitUsage
+	}
+	return cmd(s, args[1:], out, errOut)
+}
+
+// Флаги команды; --user общий для всех: у каждого пользователя свой словарь
+func commandFlags(name string, errOut io.Writer) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	if jsonOutput {
+		// Ошибку разбора флагов выводит parseCommand, уже в JSON
+		fs.SetOutput(io.Discard)
+	}
+	user := fs.String("user", os.Getenv("SLENG_USER"), "чей словарь (SLENG_USER)")
+	return fs, user
+}
+
+// Разбор флагов и проверка пользователя; при ошибке возвращает код выхода
+func parseCommand(s Store, fs *flag.FlagSet, user *string, args []string, errOut io.Writer) (string, int) {
+	if err := fs.Parse(args); err != nil {
+		if jsonOutput {
+			commandError(errOut, err.Error())
+		}
+		return "", exitUsage
+	}
+	username := normalizeUsername(*user)
+	if username == "" {
+		commandError(errOut, t(msgCommandUserRequired))
+		return "", exitUsage
+	}
+	if _, err := s.GetUser(username); err != nil {
+		commandError(errOut, tErr(err))
+		return "", exitFailure
+	}
+	return username, exitOK
+}
+
+// Сообщение об ошибке команды: текстом или, с -json, объектом {"error": "..."}
+func commandError(errOut io.Writer, parts ...any) {
+	if jsonOutput {
+		writeCLIJSON(errOut, map[string]string{"error": strings.TrimSuffix(fmt.Sprintln(parts...), "\n")})
+		return
+	}
+	fmt.Fprintln(errOut, parts...)
+}
+
+// Запись в JSON-выводе: номер и все поля записи
+type numberedEntryJSON struct {
+	Number int `json:"number"`
+	SlangEntry
+}
+
+func writeCLIJSON(out io.Writer, v any) {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+// Список записей: строка на запись или, с -json, массив
+func printEntries(out io.Writer, entries []numberedEntry) {
+	if jsonOutput {
+		list := make([]numberedEntryJSON, len(entries))
+		for i, e := range entries {
+			list[i] = numberedEntryJSON{Number: e.number, SlangEntry: e.entry}
+		}
+		writeCLIJSON(out, list)
+		return
+	}
+	for _, e := range entries {
+		fmt.Fprintf(out, "%d\t%s\t%s\n", e.number, e.entry.Word, e.entry.Meaning)
+	}
+}
+
+// Одна запись — результат add и delete; в JSON — объект, а не массив
+func printEntryLine(out io.Writer, number int, entry SlangEntry) {
+	if jsonOutput {
+		writeCLIJSON(out, numberedEntryJSON{Number: number, SlangEntry: entry})
+		return
+	}
+	printEntries(out, []numberedEntry{{number: number, entry: entry}})
+}
+
+// sleng list --user NAME
+func cmdList(s Store, args []string, out, errOut io.Writer) int {
+	fs, user := commandFlags("list", errOut)
+	username, code := parseCommand(s, fs, user, args, errOut)
+	if code != exitOK {
+		return code
+	}
+	slangData := loadSlangData(s)
+	own := pickEntries(slangData.Entries, ownEntries(slangData.Entries, username))
+	printEntries(out, numberEntries(own, own))
+	return exitOK
+}
+
+// sleng search --user NAME --q QUERY — как GET /api/search: подстрока
+// в слове, значении или примере без учёта регистра
+func cmdSearch(s Store, args []string, out, errOut io.Writer) int {
+	fs, user := commandFlags("search", errOut)
+	query := fs.String("q", "", "что искать")
+	username, code := parseCommand(s, fs, user, args, errOut)
+	if code != exitOK {
+		return code
+	}
+	if strings.TrimSpace(*query) == "" {
+		commandError(errOut, t(msgQueryRequired))
+		return exitUsage
+	}
+	slangData := loadSlangData(s)
+	own := pickEntries(slangData.Entries, ownEntries(slangData.Entries, username))
+	found := searchEntries(own, *query, defaultSearchFields)
+	printEntries(out, numberEntries(own, found))
+	if len(found) == 0 {
+		commandError(errOut, t(msgNothingFoundCLI, *query))
+		return exitFailure
+	}
+	return exitOK
+}
+
+// sleng add --user NAME --word W --meaning M [--example --origin --synonyms a,b --tags x,y].
+// Проверки те же, что у POST /api/entries, включая настроенные валидаторы.
+func cmdAdd(s Store, args []string, out, errOut io.Writer) int {
+	fs, user := commandFlags("add", errOut)
+	var entry SlangEntry
+	var synonyms, tags string
+	fs.StringVar(&entry.Word, "word", "", "слово")
+	fs.StringVar(&entry.Meaning, "meaning", "", "значение")
+	fs.StringVar(&entry.Example, "example", "", "пример использования")
+	fs.StringVar(&entry.Origin, "origin", "", "происхождение")
+	fs.StringVar(&synonyms, "synonyms", "", "похожие слова через запятую")
+	fs.StringVar(&tags, "tags", "", "теги через запятую")
+	username, code := parseCommand(s, fs, user, args, errOut)
+	if code != exitOK {
+		return code
+	}
+
+	entry.Synonyms = strings.Split(synonyms, ",")
+	entry.Tags = strings.Split(tags, ",")
+	sanitizeEntry(&entry)
+	entry.Synonyms = normalizeSynonyms(entry.Synonyms)
+	entry.Tags = normalizeTags(entry.Tags)
+	if err := ValidateEntry(entry); err != nil {
+		commandError(errOut, t(msgWordNotAdded), tErr(err))
+		return exitFailure
+	}
+	if reasons := validateNewEntry(entry); len(reasons) > 0 {
+		for _, reason := range reasons {
+			commandError(errOut, t(msgWordNotAdded), tErr(reason))
+		}
+		return exitFailure
+	}
+
+	var number int
+	err := modifySlangData(s, func(slangData *SlangData) error {
+		if ownerWordExists(slangData.Entries, username, entry.Word, -1) {
+			return errWordExists
+		}
+		entry.ID = newEntryID()
+		entry.Owner = username
+		entry.Author = username
+		entry.CreatedAt = time.Now().UTC()
+		entry.UpdatedAt = entry.CreatedAt
+		slangData.Entries = append(slangData.Entries, entry)
+		number = len(ownEntries(slangData.Entries, username))
+		return nil
+	})
+	if err != nil {
+		commandError(errOut, t(msgWordNotAdded), tErr(err))
+		return exitFailure
+	}
+	printEntryLine(out, number, entry)
+	return exitOK
+}
+
+// sleng delete --user NAME --index N — запись уходит в корзину, как из меню
+func cmdDelete(s Store, args []string, out, errOut io.Writer) int {
+	fs, user := commandFlags("delete", errOut)
+	index := fs.Int("index", 0, "номер записи (с 1), как в sleng list")
+	username, code := parseCommand(s, fs, user, args, errOut)
+	if code != exitOK {
+		return code
+	}
+	if *index < 1 {
+		commandError(errOut, t(msgCommandIndexRequired))
+		return exitUsage
+	}
+
+	var deleted SlangEntry
+	err := modifySlangData(s, func(slangData *SlangData) error {
+		own := ownEntries(slangData.Entries, username)
+		if *index > len(own) {
+			return errEntryNotFound
+		}
+		deleted = slangData.Entries[own[*index-1]]
+		removeEntry(slangData, own[*index-1], false)
+		return nil
+	})
+	if err != nil {
+		commandError(errOut, t(msgWordDeleteFailed), tErr(err))
+		return exitFailure
+	}
+	printEntryLine(out, *index, deleted)
+	return exitOK
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommands(t *testing.T) {
+	store := NewMemoryStore(SlangData{})
+	seedUser(t, store, "alice", "1234", SlangEntry{Word: "краш", Meaning: "объект симпатии"})
+
+	run := func(args ...string) (int, string) {
+		var out, errOut bytes.Buffer
+		code := runCommand(store, args, &out, &errOut)
+		return code, out.String()
+	}
+
+	tests := []struct {
+		args []string
+		code int
+		out  string
+	}{
+		{[]string{"add", "--user", "alice", "--word", "изи", "--meaning", "легко и просто", "--tags", "оценка"}, exitOK, "2\tизи\tлегко и просто\n"},
+		{[]string{"add", "--user", "alice", "--word", "Изи", "--meaning", "повтор слова"}, exitFailure, ""},
+		{[]string{"add", "--user", "alice", "--word", "вайб"}, exitFailure, ""},
+		{[]string{"list", "--user", "alice"}, exitOK, "1\tкраш\tобъект симпатии\n2\tизи\tлегко и просто\n"},
+		{[]string{"search", "--user", "alice", "--q", "ПРОСТО"}, exitOK, "2\tизи\tлегко и просто\n"},
+		{[]string{"search", "--user", "alice", "--q", "нет такого"}, exitFailure, ""},
+		{[]string{"search", "--user", "alice"}, exitUsage, ""},
+		{[]string{"delete", "--user", "alice", "--index", "1"}, exitOK, "1\tкраш\tобъект симпатии\n"},
+		{[]string{"delete", "--user", "alice", "--index", "5"}, exitFailure, ""},
+		{[]string{"delete", "--user", "alice"}, exitUsage, ""},
+		{[]string{"list", "--user", "alice"}, exitOK, "1\tизи\tлегко и просто\n"},
+		{[]string{"list", "--user", "bob"}, exitFailure, ""},
+		{[]string{"list"}, exitUsage, ""},
+		{[]string{"list", "--bad-flag"}, exitUsage, ""},
+		{[]string{"frobnicate"}, exitUsage, ""},
+	}
+	for _, tt := range tests {
+		code, out := run(tt.args...)
+		if code != tt.code || out != tt.out {
+			t.Errorf("sleng %s: код %d, вывод %q; want %d, %q", strings.Join(tt.args, " "), code, out, tt.code, tt.out)
+		}
+	}
+
+	data, _ := store.Load()
+	if len(data.Trash) != 1 || data.Trash[0].Word != "краш" {
+		t.Errorf("корзина: %+v", data.Trash)
+	}
+}
+
+func TestCommandsJSON(t *testing.T) {
+	store := NewMemoryStore(SlangData{})
+	seedUser(t, store, "alice", "1234", SlangEntry{Word: "краш", Meaning: "объект симпатии"})
+	jsonOutput = true
+	defer func() { jsonOutput = false }()
+
+	var out, errOut bytes.Buffer
+	if code := runCommand(store, []string{"list", "--user", "alice"}, &out, &errOut); code != exitOK {
+		t.Fatalf("list: код %d, stderr %s", code, errOut.String())
+	}
+	var list []numberedEntryJSON
+	if err := json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatalf("list: %v; вывод %s", err, out.String())
+	}
+	if len(list) != 1 || list[0].Number != 1 || list[0].Word != "краш" || list[0].Owner != "alice" {
+		t.Errorf("list: %+v", list)
+	}
+
+	out.Reset()
+	if code := runCommand(store, []string{"search", "--user", "alice", "--q", "zzz"}, &out, &errOut); code != exitFailure {
+		t.Errorf("search: код %d", code)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("search: вывод %q", out.String())
+	}
+
+	for _, args := range [][]string{{"frobnicate"}, {"list", "--bad-flag"}} {
+		errOut.Reset()
+		runCommand(store, args, &out, &errOut)
+		var e map[string]string
+		if err := json.Unmarshal(errOut.Bytes(), &e); err != nil || e["error"] == "" {
+			t.Errorf("sleng %s: stderr %q", strings.Join(args, " "), errOut.String())
+		}
+	}
+}
+
+// С -json в stdout попадает только JSON: служебные сообщения уходят в журнал
+// (stderr), а поиск из меню ничего не спрашивает
+func TestJSONStdoutClean(t *testing.T) {
+	store := NewMemoryStore(SlangData{})
+	seedUser(t, store, "alice", "1234", SlangEntry{Word: "краш", Meaning: "объект симпатии"})
+	modifySlangData(store, func(slangData *SlangData) error {
+		slangData.Trash = append(slangData.Trash, trashedEntry{
+			SlangEntry: SlangEntry{ID: newEntryID(), Owner: "alice", Word: "кек"},
+			DeletedAt:  time.Now().AddDate(0, 0, -60),
+		})
+		return nil
+	})
+	jsonOutput = true
+	defer func() { jsonOutput = false }()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	purgeTrashOnStartup(store, 30)
+	runCommand(store, []string{"list", "--user", "alice"}, os.Stdout, io.Discard)
+	slangData := loadSlangData(store)
+	searchEntriesCLI(store, &slangData, "alice", "краш")
+	// Без запроса — ошибка в stderr, в stdout ничего
+	searchEntriesCLI(store, &slangData, "alice", "")
+	w.Close()
+	os.Stdout = stdout
+
+	dec := json.NewDecoder(r)
+	var values int
+	for {
+		var list []numberedEntryJSON
+		if err := dec.Decode(&list); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("stdout не JSON: %v", err)
+		}
+		if len(list) != 1 || list[0].Word != "краш" {
+			t.Errorf("вывод %+v", list)
+		}
+		values++
+	}
+	if values != 2 {
+		t.Errorf("в stdout %d массивов, want 2", values)
+	}
+	if data, _ := store.Load(); len(data.Trash) != 0 {
+		t.Errorf("корзина не очищена: %+v", data.Trash)
+	}
+}
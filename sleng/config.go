@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"time"
+)
+
+// Настройки запуска. Значения по умолчанию совпадают с прежним поведением,
+// переменные окружения их переопределяют, а флаги командной строки — переопределяют всё.
+type config struct {
+	Addr     string // адрес HTTP API
+	DataFile string // путь к JSON-файлу словаря
+	DBFile   string // путь к базе SQLite
+	Storage  string // json, sqlite или memory
+	Strict   bool   // не запускаться с повреждённым JSON-файлом вместо его восстановления
+
+	TrashDays   int    // сколько дней удалённые записи хранятся в корзине
+	BackupDir   string // папка резервных копий JSON-файла
+	KeepBackups int    // сколько последних копий хранить, 0 — не делать копий
+
+	Validators      string // проверки новых записей через запятую: quality, caps, banned
+	BannedWordsFile string // файл со списком запрещённых слов
+
+	// Таймауты HTTP-сервера: медленный или злонамеренный клиент не держит соединение вечно.
+	// Потоки событий (/api/events, /api/ws) снимают их для своего соединения сами.
+	ReadHeaderTimeout time.Duration // на чтение заголовков запроса
+	ReadTimeout       time.Duration // на чтение всего запроса вместе с телом
+	WriteTimeout      time.Duration // на ответ, считая от конца чтения заголовков
+	IdleTimeout       time.Duration // сколько keep-alive соединение ждёт следующего запроса
+
+	Webhooks      string // адреса webhooks через запятую
+	WebhookSecret string // секрет для подписи webhooks, только из SLENG_WEBHOOK_SECRET
+
+	JSON    bool     // вывод консоли в JSON: списки, результаты и ошибки подкоманд
+	Command []string // подкоманда и её аргументы — всё после флагов запуска; пусто — меню
+}
+
+func loadConfig(args []string) (config, error) {
+	var cfg config
+	fs := flag.NewFlagSet("sleng", flag.ContinueOnError)
+	fs.StringVar(&cfg.Addr, "addr", envOrDefault("SLENG_ADDR", ":8080"), "адрес HTTP API (SLENG_ADDR)")
+	fs.StringVar(&cfg.DataFile, "data", envOrDefault("SLENG_DATA_FILE", "slang.json"), "путь к JSON-файлу словаря (SLENG_DATA_FILE)")
+	fs.StringVar(&cfg.DBFile, "db", envOrDefault("SLENG_DB_FILE", "slang.db"), "путь к базе SQLite (SLENG_DB_FILE)")
+	fs.StringVar(&cfg.Storage, "storage", envOrDefault("SLENG_STORAGE", "json"), "хранилище: json, sqlite или memory (SLENG_STORAGE)")
+	fs.StringVar(&cfg.Storage, "store", cfg.Storage, "то же, что -storage")
+	fs.BoolVar(&cfg.Strict, "strict", os.Getenv("SLENG_STRICT") == "true", "не запускаться, если JSON-файл повреждён (SLENG_STRICT)")
+	fs.IntVar(&cfg.TrashDays, "trash-days", envInt("SLENG_TRASH_DAYS", 30), "сколько дней хранить корзину (SLENG_TRASH_DAYS)")
+	fs.StringVar(&cfg.BackupDir, "backup-dir", envOrDefault("SLENG_BACKUP_DIR", "backups"), "папка резервных копий (SLENG_BACKUP_DIR)")
+	fs.IntVar(&cfg.KeepBackups, "backups", envInt("SLENG_BACKUPS", 10), "сколько копий хранить, 0 — отключить (SLENG_BACKUPS)")
+	fs.StringVar(&cfg.Validators, "validators", envOrDefault("SLENG_VALIDATORS", "quality,caps,banned"), "проверки новых записей, none — отключить (SLENG_VALIDATORS)")
+	fs.StringVar(&cfg.BannedWordsFile, "banned-words", os.Getenv("SLENG_BANNED_WORDS"), "файл со списком запрещённых слов (SLENG_BANNED_WORDS)")
+	fs.DurationVar(&cfg.ReadHeaderTimeout, "read-header-timeout", envDuration("SLENG_READ_HEADER_TIMEOUT", 5*time.Second), "таймаут чтения заголовков (SLENG_READ_HEADER_TIMEOUT)")
+	fs.DurationVar(&cfg.ReadTimeout, "read-timeout", envDuration("SLENG_READ_TIMEOUT", 15*time.Second), "таймаут чтения запроса (SLENG_READ_TIMEOUT)")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", envDuration("SLENG_WRITE_TIMEOUT", 30*time.Second), "таймаут ответа (SLENG_WRITE_TIMEOUT)")
+	fs.DurationVar(&cfg.IdleTimeout, "idle-timeout", envDuration("SLENG_IDLE_TIMEOUT", 2*time.Minute), "таймаут простоя keep-alive соединения (SLENG_IDLE_TIMEOUT)")
+	fs.StringVar(&cfg.Webhooks, "webhooks", os.Getenv("SLENG_WEBHOOKS"), "адреса webhooks через запятую (SLENG_WEBHOOKS)")
+	fs.BoolVar(&cfg.JSON, "json", os.Getenv("SLENG_JSON") == "true", "вывод в JSON для скриптов (SLENG_JSON)")
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+	// Секрет не принимается флагом, чтобы он не был виден в списке процессов
+	cfg.WebhookSecret = os.Getenv("SLENG_WEBHOOK_SECRET")
+	cfg.Command = fs.Args()
+	return cfg, nil
+}
+
+// Адрес API для вывода в консоль: ":8080" превращается в http://localhost:8080
+func (c config) apiURL() string {
+	if strings.HasPrefix(c.Addr, ":") {
+		return "http://localhost" + c.Addr
+	}
+	return "http://" + c.Addr
+}
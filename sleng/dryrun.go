@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ————————————————————————
+//         Пробный запуск (dryRun)
+// ————————————————————————
+
+// Функция-изменение из modifySlangData возвращает errDryRun, когда всё уже
+// посчитано: Modify отменяет сохранение, а события об изменениях не рассылаются
+var errDryRun = errors.New("пробный запуск, изменения не сохранены")
+
+// errDryRun для запроса с ?dryRun=true, иначе nil
+func dryRunError(r *http.Request) error {
+	if r.URL.Query().Get("dryRun") == "true" {
+		return errDryRun
+	}
+	return nil
+}
+
+// Ответ на пробное удаление: что было бы удалено и сколько записей осталось бы
+type deletionPreview struct {
+	DryRun    bool         `json:"dryRun"`
+	Message   string       `json:"message"`
+	Deleted   []SlangEntry `json:"deleted"`
+	Permanent bool         `json:"permanent"` // насовсем или в корзину
+	Remaining int          `json:"remaining"` // записей в словаре пользователя после удаления
+}
+
+// Ответ на удаление записей: обычный при сохранении, deletionPreview при dryRun
+func respondDeleted(w http.ResponseWriter, r *http.Request, err error, deleted []SlangEntry, remaining int) {
+	switch {
+	case errors.Is(err, errDryRun):
+		respondJSON(w, r, http.StatusOK, deletionPreview{
+			DryRun:    true,
+			Message:   tr(r, msgDryRunDelete, len(deleted)),
+			Deleted:   deleted,
+			Permanent: r.URL.Query().Get("permanent") == "true",
+			Remaining: remaining,
+		})
+	case err != nil:
+		respondModifyError(w, r, err)
+	default:
+		respondJSON(w, r, http.StatusOK, map[string]string{"message": tr(r, msgWordDeleted)})
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ————————————————————————
+//         Повторяющиеся значения
+// ————————————————————————
+
+// Порог сходства значений по умолчанию: 1 − расстояние Левенштейна / длина
+// более длинного значения. 0.85 ловит опечатки и лишнее слово, но не разные определения.
+const defaultDuplicateThreshold = 0.85
+
+// Наименьший допустимый порог. При низком пороге почти любая пара проходит
+// предварительную проверку длин и считается полностью.
+const minDuplicateThreshold = 0.5
+
+// Сколько первых символов нормализованного значения сравнивается: на длинных
+// определениях различия видны и в начале, а расстояние считается за O(длина²)
+const maxDuplicateCompareRunes = 300
+
+// Группа записей с одинаковыми или почти одинаковыми значениями
+type duplicateGroup struct {
+	Exact      bool         `json:"exact"`      // значения совпадают после нормализации
+	Similarity float64      `json:"similarity"` // наименьшее сходство, по которому записи попали в группу
+	Entries    []SlangEntry `json:"entries"`
+}
+
+// Значение для сравнения: нижний регистр, ё как е, без знаков препинания
+// и лишних пробелов. «Объект симпатии!» и «объект  симпатии» совпадают.
+func normalizeMeaning(meaning string) string {
+	meaning = strings.ReplaceAll(strings.ToLower(meaning), "ё", "е")
+	meaning = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, meaning)
+	return strings.Join(strings.Fields(meaning), " ")
+}
+
+// Расстояние Левенштейна, если оно не больше limit, иначе limit+1. Считается только
+// полоса шириной 2·limit+1 вокруг диагонали, и счёт обрывается, как только вся
+// строка полосы превысила limit: O(длина·limit) вместо O(длина²).
+func levenshteinWithin(a, b []rune, limit int) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	over := limit + 1
+	if len(a)-len(b) > limit {
+		return over
+	}
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = min(j, over)
+	}
+	for i := 1; i <= len(a); i++ {
+		lo, hi := max(1, i-limit), min(len(b), i+limit)
+		curr[0] = min(i, over)
+		if lo > 1 {
+			curr[lo-1] = over
+		}
+		rowMin := curr[lo-1]
+		for j := lo; j <= hi; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost, over)
+			rowMin = min(rowMin, curr[j])
+		}
+		if hi < len(b) {
+			curr[hi+1] = over
+		}
+		if rowMin >= over {
+			return over
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// Группы записей, значения которых совпадают или похожи не меньше чем на threshold.
+// Сходство транзитивно: если A похоже на B, а B на C, все три попадают в одну группу.
+// Записи с пустым значением не сравниваются, у длинных значений сравниваются первые
+// maxDuplicateCompareRunes символов. Первыми идут самые большие группы.
+func findDuplicateMeanings(entries []SlangEntry, threshold float64) []duplicateGroup {
+	meanings := make([]string, len(entries))
+	compared := make([][]rune, len(entries))
+	for i, entry := range entries {
+		meanings[i] = normalizeMeaning(entry.Meaning)
+		compared[i] = []rune(meanings[i])
+		if len(compared[i]) > maxDuplicateCompareRunes {
+			compared[i] = compared[i][:maxDuplicateCompareRunes]
+		}
+	}
+
+	// Объединение записей в группы (система непересекающихся множеств)
+	parent := make([]int, len(entries))
+	similarity := make([]float64, len(entries))
+	for i := range parent {
+		parent[i] = i
+		similarity[i] = 1
+	}
+	var root func(int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range entries {
+		if meanings[i] == "" {
+			continue
+		}
+		for j := i + 1; j < len(entries); j++ {
+			if meanings[j] == "" {
+				continue
+			}
+			// Сходство 1 − расстояние / длина более длинного значения не ниже порога,
+			// только если расстояние не больше limit; дальше него расстояние не считается
+			longest := max(len(compared[i]), len(compared[j]))
+			limit := int((1-threshold)*float64(longest) + 1e-9)
+			d := levenshteinWithin(compared[i], compared[j], limit)
+			if d > limit {
+				continue
+			}
+			sim := 1 - float64(d)/float64(longest)
+			if sim < threshold {
+				continue
+			}
+			ri, rj := root(i), root(j)
+			if ri != rj {
+				parent[rj] = ri
+				similarity[ri] = min(similarity[ri], similarity[rj])
+			}
+			similarity[ri] = min(similarity[ri], sim)
+		}
+	}
+
+	byRoot := map[int]*duplicateGroup{}
+	var order []int
+	for i, entry := range entries {
+		r := root(i)
+		group, ok := byRoot[r]
+		if !ok {
+			group = &duplicateGroup{Exact: true, Similarity: math.Round(similarity[r]*100) / 100}
+			byRoot[r] = group
+			order = append(order, r)
+		}
+		if meanings[i] != meanings[r] {
+			group.Exact = false
+		}
+		group.Entries = append(group.Entries, entry)
+	}
+
+	groups := []duplicateGroup{}
+	for _, r := range order {
+		if group := byRoot[r]; len(group.Entries) > 1 {
+			groups = append(groups, *group)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].Entries) > len(groups[j].Entries)
+	})
+	return groups
+}
+
+// GET /api/entries/duplicates[?threshold=0.85] — только отчёт, данные не меняются.
+// Сравниваются записи словаря пользователя: сравнение попарное, и на всём общем
+// словаре один запрос занимал бы процессор надолго. threshold=1 — только точные
+// совпадения после нормализации.
+func handleDuplicates(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		threshold := defaultDuplicateThreshold
+		if raw := r.URL.Query().Get("threshold"); raw != "" {
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsNaN(value) || value < minDuplicateThreshold || value > 1 {
+				respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidThreshold))
+				return
+			}
+			threshold = value
+		}
+
+		entries := loadSlangData(s).Entries
+		entries = pickEntries(entries, ownEntries(entries, usernameFromContext(r.Context())))
+		respondJSON(w, r, http.StatusOK, findDuplicateMeanings(entries, threshold))
+	}
+}
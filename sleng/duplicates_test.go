@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeMeaning(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Объект симпатии", "объект симпатии"},
+		{"  объект,   симпатии!  ", "объект симпатии"},
+		{"Всё — ок", "все ок"},
+		{"...", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeMeaning(tt.in); got != tt.want {
+			t.Errorf("normalizeMeaning(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFindDuplicateMeanings(t *testing.T) {
+	entries := []SlangEntry{
+		{Word: "краш", Meaning: "Объект симпатии"},
+		{Word: "кринж", Meaning: "испанский стыд"},
+		{Word: "симпа", Meaning: "объект симпатии!"},
+		{Word: "рофл", Meaning: "шутка"},
+		{Word: "зашквар", Meaning: "испанский стыдд"},
+		{Word: "пустое", Meaning: ""},
+		{Word: "пустое2", Meaning: "  "},
+	}
+
+	groups := findDuplicateMeanings(entries, defaultDuplicateThreshold)
+	if len(groups) != 2 {
+		t.Fatalf("групп %d, want 2: %+v", len(groups), groups)
+	}
+	exact, near := groups[0], groups[1]
+	if !exact.Exact || exact.Similarity != 1 || len(exact.Entries) != 2 ||
+		exact.Entries[0].Word != "краш" || exact.Entries[1].Word != "симпа" {
+		t.Errorf("точные совпадения: %+v", exact)
+	}
+	if near.Exact || near.Similarity >= 1 || near.Similarity < defaultDuplicateThreshold || len(near.Entries) != 2 ||
+		near.Entries[0].Word != "кринж" || near.Entries[1].Word != "зашквар" {
+		t.Errorf("похожие значения: %+v", near)
+	}
+
+	// Порог 1 — только точные совпадения
+	if groups := findDuplicateMeanings(entries, 1); len(groups) != 1 || !groups[0].Exact {
+		t.Errorf("threshold=1: %+v", groups)
+	}
+}
+
+func TestHandleDuplicates(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии"},
+		SlangEntry{Word: "симпа", Meaning: "Объект симпатии."},
+		SlangEntry{Word: "рофл", Meaning: "шутка"},
+	)
+	// Записи других пользователей в отчёт не попадают
+	seedUser(t, store, "bob", "1234", SlangEntry{Word: "краш", Meaning: "объект симпатии"})
+	before := len(loadSlangData(store).Entries)
+
+	resp := doRequest(t, srv, "GET", "/api/entries/duplicates", token, "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	var groups []duplicateGroup
+	if err := json.Unmarshal(resp.body, &groups); err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || len(groups[0].Entries) != 2 {
+		t.Errorf("группы: %+v", groups)
+	}
+	if after := len(loadSlangData(store).Entries); after != before {
+		t.Errorf("записей %d, было %d", after, before)
+	}
+
+	// Без входа отчёт не строится: сравнение попарное и дорогое
+	expectError(t, doRequest(t, srv, "GET", "/api/entries/duplicates", "", ""), http.StatusUnauthorized, errCodeUnauthorized)
+
+	// Слишком низкий порог отключал бы отсев пар по длине
+	for _, threshold := range []string{"0", "0.01", "0.49", "1.5", "abc", "NaN"} {
+		expectError(t, doRequest(t, srv, "GET", "/api/entries/duplicates?threshold="+threshold, token, ""),
+			http.StatusBadRequest, errCodeInvalidParameter)
+	}
+}
+
+func TestLevenshteinWithin(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+	}{
+		{"испанский стыд", "испанский стыдд", 2},
+		{"объект симпатии", "симпатия объекта", 3},
+		{"объект симпатии", "симпатия объекта", 20},
+		{"шутка", "", 4},
+		{"шутка", "", 5},
+		{"", "", 0},
+		{"кринж", "крінж", 0},
+	}
+	for _, tt := range tests {
+		want := min(levenshtein(tt.a, tt.b), tt.limit+1)
+		if got := levenshteinWithin([]rune(tt.a), []rune(tt.b), tt.limit); got != want {
+			t.Errorf("levenshteinWithin(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Машиночитаемый код ошибки. Клиенты различают ошибки по нему, а не по тексту
+// сообщения, который может меняться и переводиться. Значения менять нельзя.
+type errorCode string
+
+const (
+	errCodeInvalidJSON          errorCode = "INVALID_JSON"           // тело запроса — не JSON нужного вида
+	errCodeInvalidParameter     errorCode = "INVALID_PARAMETER"      // неверный параметр пути или запроса
+	errCodeValidationFailed     errorCode = "VALIDATION_FAILED"      // поля не прошли проверку
+	errCodeEntryRejected        errorCode = "ENTRY_REJECTED"         // запись отклонена проверкой качества
+	errCodeDuplicateWord        errorCode = "DUPLICATE_WORD"         // такое слово уже есть
+	errCodeUserExists           errorCode = "USER_EXISTS"            // логин занят
+	errCodeNotFound             errorCode = "NOT_FOUND"              // запись, версия, пользователь и т.п. не найдены
+	errCodeUnauthorized         errorCode = "UNAUTHORIZED"           // нет токена или он недействителен
+	errCodeForbidden            errorCode = "FORBIDDEN"              // нужны права администратора
+	errCodeInvalidCredentials   errorCode = "INVALID_CREDENTIALS"    // неверный логин или пароль
+	errCodePreconditionFailed   errorCode = "PRECONDITION_FAILED"    // запись изменилась, If-Match не совпал
+	errCodePreconditionRequired errorCode = "PRECONDITION_REQUIRED"  // нужен заголовок If-Match (SLENG_REQUIRE_IF_MATCH)
+	errCodePayloadTooLarge      errorCode = "PAYLOAD_TOO_LARGE"      // тело запроса больше SLENG_MAX_BODY_BYTES
+	errCodeUnsupportedMediaType errorCode = "UNSUPPORTED_MEDIA_TYPE" // тело прислано не с Content-Type: application/json
+	errCodeIdempotencyKeyReused errorCode = "IDEMPOTENCY_KEY_REUSED" // Idempotency-Key уже использован с другим телом запроса
+	errCodeRateLimited          errorCode = "RATE_LIMITED"           // слишком много запросов
+	errCodeAccountLocked        errorCode = "ACCOUNT_LOCKED"         // аккаунт временно заблокирован после неудачных входов
+	errCodeInvalidResetToken    errorCode = "INVALID_RESET_TOKEN"    // токен сброса пароля неверный, истёк или уже использован
+	errCodeNotImplemented       errorCode = "NOT_IMPLEMENTED"        // не поддерживается текущим хранилищем
+	errCodeInternal             errorCode = "INTERNAL_ERROR"         // ошибка сервера
+)
+
+// Тело ответа с ошибкой
+type errorResponse struct {
+	Error     string    `json:"error"`
+	Code      int       `json:"code"`
+	ErrorCode errorCode `json:"errorCode"`
+}
+
+// Ответ с ошибкой в JSON ({"error": "...", "code": N, "errorCode": "..."}) вместо
+// text/plain от http.Error, чтобы клиенту не приходилось разбирать два формата
+func respondError(w http.ResponseWriter, r *http.Request, code int, errCode errorCode, message string) {
+	respondJSON(w, r, code, errorResponse{Error: message, Code: code, ErrorCode: errCode})
+}
+
+// Ошибки, которыми функция-изменение из modifySlangData отменяет сохранение
+var (
+	errEntryNotFound = newMsgError(msgWordNotFound)
+	errWordExists    = newMsgError(msgWordExists)
+	errUserExists    = newMsgError(msgUserExists)
+	errUserNotFound  = newMsgError(msgUserNotRegistered)
+
+	errVersionNotFound = newMsgError(msgVersionNotFound)
+	errTrashNotFound   = newMsgError(msgTrashNotFound)
+
+	// Запись изменилась после того, как клиент её прочитал (If-Match не совпал),
+	// или If-Match не прислан, хотя обязателен
+	errPreconditionFailed   = newMsgError(msgEntryChanged)
+	errPreconditionRequired = newMsgError(msgIfMatchRequired)
+)
+
+// Ответ на ошибку modifySlangData: отказ самого изменения (нет записи, дубликат,
+// занятый логин, неверные поля, не совпавший If-Match) или сбой хранилища
+func respondModifyError(w http.ResponseWriter, r *http.Request, err error) {
+	var invalid *ValidationError
+	switch {
+	case errors.Is(err, errEntryNotFound), errors.Is(err, errVersionNotFound), errors.Is(err, errTrashNotFound):
+		respondError(w, r, http.StatusNotFound, errCodeNotFound, trErr(r, err))
+	case errors.Is(err, errWordExists):
+		respondError(w, r, http.StatusConflict, errCodeDuplicateWord, trErr(r, err))
+	case errors.Is(err, errUserExists):
+		respondError(w, r, http.StatusConflict, errCodeUserExists, trErr(r, err))
+	case errors.Is(err, errPreconditionFailed):
+		respondError(w, r, http.StatusPreconditionFailed, errCodePreconditionFailed, trErr(r, err))
+	case errors.Is(err, errPreconditionRequired):
+		respondError(w, r, http.StatusPreconditionRequired, errCodePreconditionRequired, trErr(r, err))
+	case errors.Is(err, errUserNotFound):
+		respondError(w, r, http.StatusNotFound, errCodeNotFound, trErr(r, err))
+	case errors.As(err, &invalid):
+		respondError(w, r, http.StatusBadRequest, errCodeValidationFailed, trErr(r, err))
+	default:
+		respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgSaveFailed))
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ETag по содержимому ответа: одинаковые данные дают одинаковый тег и после
+// перезапуска. Тег слабый (W/), потому что pretty=true меняет только отступы.
+func contentETag(payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// Совпадает ли ETag с одним из значений If-None-Match (сравнение слабое, как требует RFC 9110)
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Ответ с ETag: если клиент прислал тот же тег в If-None-Match, отдаём 304 без тела
+func respondJSONWithETag(w http.ResponseWriter, r *http.Request, payload interface{}) {
+	etag, err := contentETag(payload)
+	if err != nil {
+		respondJSON(w, r, http.StatusOK, payload)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	// С токеном и без него список разный (свой словарь или общий)
+	w.Header().Add("Vary", "Authorization")
+	if inm := r.Header.Get("If-None-Match"); inm != "" && etagMatches(inm, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	respondJSON(w, r, http.StatusOK, payload)
+}
+
+// Изменение записи по If-Match требуют всегда, а не только когда заголовок прислан:
+// SLENG_REQUIRE_IF_MATCH=true. По умолчанию выключено, чтобы не ломать старых клиентов.
+var requireIfMatch = envBool("SLENG_REQUIRE_IF_MATCH")
+
+// ETag одной записи — по содержимому и времени изменения, без рейтинга: голос
+// за запись не должен мешать её правке. Тег сильный, потому что If-Match
+// сравнивает теги строго и слабые не принимает (RFC 9110).
+func entryETag(entry SlangEntry) string {
+	data, _ := json.Marshal(revisionOf(entry))
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Проверка If-Match перед изменением записи: тег должен совпасть с текущим,
+// иначе запись изменили после того, как клиент её прочитал. "*" подходит к любой записи.
+func checkIfMatch(r *http.Request, entry SlangEntry) error {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		if requireIfMatch {
+			return errPreconditionRequired
+		}
+		return nil
+	}
+	etag := entryETag(entry)
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if candidate = strings.TrimSpace(candidate); candidate == "*" || candidate == etag {
+			return nil
+		}
+	}
+	return errPreconditionFailed
+}
+
+// Ответ с записью и её ETag, который клиент пришлёт в If-Match при следующей правке
+func respondEntry(w http.ResponseWriter, r *http.Request, entry SlangEntry, payload interface{}) {
+	w.Header().Set("ETag", entryETag(entry))
+	respondJSON(w, r, http.StatusOK, payload)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// ————————————————————————
+//         Живые обновления
+// ————————————————————————
+
+// Событие об изменении словаря. Для deleted вместо записи передаются её ID и
+// номер в общем словаре до удаления (у личных записей номера нет).
+type entryEvent struct {
+	Type  string      `json:"type"` // added, updated, deleted
+	ID    string      `json:"id"`
+	Index int         `json:"index,omitempty"`
+	Entry *SlangEntry `json:"entry,omitempty"`
+
+	// Кому можно показать событие: личные записи видит только владелец
+	owner   string
+	private bool
+	// Слово — для текста webhook, в том числе для удалённых записей
+	word string
+	// Изменился только счёт (проголосовали): клиентам /api/ws и /api/events это
+	// обычное updated, а на webhooks не отправляется, чтобы не засыпать чат
+	// сообщением на каждый голос
+	scoreOnly bool
+}
+
+// События об изменениях записей: записи сравниваются по ID. Удаления идут первыми
+// и от больших номеров к меньшим, чтобы клиент мог удалять записи по очереди.
+func entryChanges(before, after []SlangEntry) []entryEvent {
+	var events []entryEvent
+
+	remaining := make(map[string]bool, len(after))
+	for _, e := range after {
+		remaining[e.ID] = true
+	}
+	shared := 0
+	var deleted []entryEvent
+	for _, e := range before {
+		index := 0
+		if !e.Private {
+			shared++
+			index = shared
+		}
+		if e.ID != "" && !remaining[e.ID] {
+			deleted = append(deleted, entryEvent{Type: "deleted", ID: e.ID, Index: index, owner: e.Owner, private: e.Private, word: e.Word})
+		}
+	}
+	slices.Reverse(deleted)
+	events = append(events, deleted...)
+
+	previous := make(map[string]SlangEntry, len(before))
+	for _, e := range before {
+		previous[e.ID] = e
+	}
+	for _, e := range after {
+		if e.ID == "" {
+			continue
+		}
+		old, existed := previous[e.ID]
+		eventType := "added"
+		scoreOnly := false
+		if existed {
+			sameContent := sameEntryContent(old, e)
+			if sameContent && old.Score == e.Score {
+				continue
+			}
+			eventType = "updated"
+			scoreOnly = sameContent
+		}
+		entry := e
+		events = append(events, entryEvent{Type: eventType, ID: e.ID, Entry: &entry, owner: e.Owner, private: e.Private,
+			word: e.Word, scoreOnly: scoreOnly})
+	}
+	return events
+}
+
+// Нужно ли кому-то знать об изменениях: есть подписчики или настроены webhooks
+func changesWanted() bool {
+	return webhooks != nil || liveEvents.hasSubscribers()
+}
+
+// Рассылка изменений записей клиентам /api/ws и /api/events и на webhooks
+func publishChanges(before, after []SlangEntry) {
+	events := entryChanges(before, after)
+	liveEvents.publish(events)
+	webhooks.dispatch(events)
+}
+
+// Событие с порядковым номером и готовым JSON
+type hubMessage struct {
+	id    int64
+	event entryEvent
+	data  []byte
+}
+
+// Сколько событий может ждать отправки одному клиенту. Клиент, который не
+// успевает их забирать, отключается — обработчики HTTP его не ждут.
+const subscriberBuffer = 64
+
+// Сколько последних событий хранится для клиентов SSE, переподключающихся с Last-Event-ID
+const recentEvents = 256
+
+// Подписчик на события: соединение /api/ws или /api/events
+type subscriber struct {
+	send     chan hubMessage
+	username string
+}
+
+func (sub *subscriber) canSee(msg hubMessage) bool {
+	return !msg.event.private || msg.event.owner == sub.username
+}
+
+// Подключённые клиенты и последние события
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]bool
+	lastID      int64
+	recent      []hubMessage
+}
+
+var liveEvents = &eventHub{subscribers: make(map[*subscriber]bool)}
+
+// Новый подписчик и события после afterID, которые он пропустил
+// (afterID < 0 — пропущенные события не нужны)
+func (h *eventHub) subscribe(username string, afterID int64) (*subscriber, []hubMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub := &subscriber{send: make(chan hubMessage, subscriberBuffer), username: username}
+	h.subscribers[sub] = true
+
+	var missed []hubMessage
+	if afterID >= 0 {
+		for _, msg := range h.recent {
+			if msg.id > afterID && sub.canSee(msg) {
+				missed = append(missed, msg)
+			}
+		}
+	}
+	return sub, missed
+}
+
+func (h *eventHub) hasSubscribers() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers) > 0
+}
+
+// Удаление подписчика; закрытый канал send говорит писателю завершить соединение
+func (h *eventHub) unsubscribe(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unsubscribeLocked(sub)
+}
+
+func (h *eventHub) unsubscribeLocked(sub *subscriber) {
+	if h.subscribers[sub] {
+		delete(h.subscribers, sub)
+		close(sub.send)
+	}
+}
+
+// Рассылка событий всем, кто может видеть запись. Не блокируется:
+// если очередь подписчика заполнена, он отключается.
+func (h *eventHub) publish(events []entryEvent) {
+	if len(events) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		h.lastID++
+		msg := hubMessage{id: h.lastID, event: event, data: data}
+		h.recent = append(h.recent, msg)
+		if len(h.recent) > recentEvents {
+			h.recent = slices.Delete(h.recent, 0, len(h.recent)-recentEvents)
+		}
+		for sub := range h.subscribers {
+			if !sub.canSee(msg) {
+				continue
+			}
+			select {
+			case sub.send <- msg:
+			default:
+				logger.Warn("клиент не успевает получать события, отключаем", "username", sub.username)
+				h.unsubscribeLocked(sub)
+			}
+		}
+	}
+}
+
+// Пользователь потока событий. Токен необязателен; браузер не может передать
+// заголовок Authorization из WebSocket и EventSource, поэтому токен принимается и в ?token=.
+// Проверки те же, что в requireAuth: токен удалённого аккаунта или выданный до смены
+// пароля поток не открывает.
+func streamUser(s Store, r *http.Request) (string, error) {
+	token := bearerToken(r)
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if token == "" {
+		return "", nil
+	}
+	claims, err := parseToken(token)
+	if err != nil {
+		return "", err
+	}
+	if err := checkTokenUser(s, claims); err != nil {
+		return "", err
+	}
+	return claims.Subject, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Метка порядка байтов UTF-8: без неё Excel открывает CSV с кириллицей как кракозябры
+const utf8BOM = "\xef\xbb\xbf"
+
+// Экранирование символов разметки Markdown в пользовательском тексте
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "{", `\{`, "}", `\}`,
+	"[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "#", `\#`, "+", `\+`,
+	"-", `\-`, "!", `\!`, "|", `\|`, "<", `\<`, ">", `\>`,
+)
+
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(strings.TrimSpace(text))
+}
+
+// Словарь в виде Markdown-страницы: по разделу на слово, слова по алфавиту
+func renderMarkdown(entries []SlangEntry) string {
+	sorted, _ := sortEntries(entries, "word")
+
+	var b strings.Builder
+	b.WriteString("# Словарь сленга\n")
+	for _, entry := range sorted {
+		b.WriteString("\n## " + escapeMarkdown(entry.Word) + "\n\n")
+		b.WriteString(escapeMarkdown(entry.Meaning) + "\n")
+		if entry.Example != "" {
+			// Каждая строка примера должна остаться внутри цитаты
+			b.WriteString("\n> " + strings.ReplaceAll(escapeMarkdown(entry.Example), "\n", "\n> ") + "\n")
+		}
+		if entry.Origin != "" {
+			b.WriteString("\n*Происхождение:* " + escapeMarkdown(entry.Origin) + "\n")
+		}
+		if len(entry.Synonyms) > 0 {
+			synonyms := make([]string, len(entry.Synonyms))
+			for i, synonym := range entry.Synonyms {
+				synonyms[i] = escapeMarkdown(synonym)
+			}
+			b.WriteString("\n*Синонимы:* " + strings.Join(synonyms, ", ") + "\n")
+		}
+		if len(entry.Tags) > 0 {
+			tags := make([]string, len(entry.Tags))
+			for i, tag := range entry.Tags {
+				tags[i] = escapeMarkdown(tag)
+			}
+			b.WriteString("\n*Теги:* " + strings.Join(tags, ", ") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// GET /api/entries/export?format=json|csv|markdown[&bom=true]
+func handleExport(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := strings.ToLower(r.URL.Query().Get("format"))
+		if format == "" {
+			format = "json"
+		}
+		withBOM := r.URL.Query().Get("bom") == "true"
+
+		entries := visibleEntries(r, loadSlangData(s).Entries)
+
+		switch format {
+		case "json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="slang.json"`)
+			data, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgSerializeFailed))
+				return
+			}
+			w.Write(data)
+		case "csv":
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="slang.csv"`)
+			if withBOM {
+				w.Write([]byte(utf8BOM))
+			}
+			writer := csv.NewWriter(w)
+			writer.Write([]string{"Word", "Meaning", "Example", "Origin", "Synonyms", "Tags"})
+			for _, entry := range entries {
+				writer.Write([]string{
+					entry.Word,
+					entry.Meaning,
+					entry.Example,
+					entry.Origin,
+					strings.Join(entry.Synonyms, ";"),
+					strings.Join(entry.Tags, ";"),
+				})
+			}
+			writer.Flush()
+		case "markdown", "md":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="slang.md"`)
+			w.Write([]byte(renderMarkdown(entries)))
+		default:
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgUnknownFormat, format))
+		}
+	}
+}
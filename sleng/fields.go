@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ————————————————————————
+//         Выбор полей ответа (?fields=word,meaning)
+// ————————————————————————
+
+// Имена полей записи в JSON — то, что можно перечислить в fields
+var entryFieldNames = jsonFieldNames(reflect.TypeFor[SlangEntry]())
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// Запись только с выбранными полями. Пустые поля с omitempty
+// не выводятся, как и в полном ответе.
+type entryProjection map[string]json.RawMessage
+
+// Страница списка с урезанными записями: поле Entries внешней структуры
+// при кодировании в JSON заменяет одноимённое поле entriesPage
+type projectedPage struct {
+	entriesPage
+	Entries []entryProjection `json:"entries"`
+}
+
+// Поля из параметра fields; nil — параметр не задан, нужны все поля
+func parseFieldsParam(r *http.Request) ([]string, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !entryFieldNames[field] {
+			return nil, newMsgError(msgUnknownEntryField, field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func projectEntry(entry SlangEntry, fields []string) entryProjection {
+	// SlangEntry всегда кодируется без ошибок
+	data, _ := json.Marshal(entry)
+	var all entryProjection
+	json.Unmarshal(data, &all)
+	projection := make(entryProjection, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			projection[field] = value
+		}
+	}
+	return projection
+}
+
+// Ответ со списком: сами записи или, если заданы fields, их проекции
+func selectEntriesFields(entries []SlangEntry, fields []string) interface{} {
+	if fields == nil {
+		return entries
+	}
+	projections := make([]entryProjection, len(entries))
+	for i, entry := range entries {
+		projections[i] = projectEntry(entry, fields)
+	}
+	return projections
+}
+
+// Ответ с одной записью: запись целиком или её проекция
+func selectEntryFields(entry SlangEntry, fields []string) interface{} {
+	if fields == nil {
+		return entry
+	}
+	return projectEntry(entry, fields)
+}
+
+// Ответ со страницей: страница как есть или страница с проекциями записей
+func selectPageFields(page entriesPage, fields []string) interface{} {
+	if fields == nil {
+		return page
+	}
+	projected := projectedPage{entriesPage: page, Entries: make([]entryProjection, len(page.Entries))}
+	for i, entry := range page.Entries {
+		projected.Entries[i] = projectEntry(entry, fields)
+	}
+	return projected
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Сколько прежних версий хранится для каждой записи
+const maxHistory = 20
+
+// Прежняя версия записи: только содержимое и время, когда она была записана
+type entryRevision struct {
+	Word      string    `json:"word"`
+	Meaning   string    `json:"meaning"`
+	Example   string    `json:"example"`
+	Origin    string    `json:"origin,omitempty"`
+	Synonyms  []string  `json:"synonyms,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Private   bool      `json:"private,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func revisionOf(entry SlangEntry) entryRevision {
+	return entryRevision{
+		Word:      entry.Word,
+		Meaning:   entry.Meaning,
+		Example:   entry.Example,
+		Origin:    entry.Origin,
+		Synonyms:  entry.Synonyms,
+		Tags:      entry.Tags,
+		Private:   entry.Private,
+		UpdatedAt: entry.UpdatedAt,
+	}
+}
+
+// Сохранение версии записи перед изменением; старейшие версии сверх maxHistory отбрасываются
+func recordHistory(slangData *SlangData, old SlangEntry) {
+	if slangData.History == nil {
+		slangData.History = make(map[string][]entryRevision)
+	}
+	revisions := append(slangData.History[old.ID], revisionOf(old))
+	if len(revisions) > maxHistory {
+		revisions = slices.Clone(revisions[len(revisions)-maxHistory:])
+	}
+	slangData.History[old.ID] = revisions
+}
+
+// Версия в ответе: номер (с 1, от старой к новой) и содержимое
+type historyItem struct {
+	Version int `json:"version"`
+	entryRevision
+}
+
+// GET /api/entries/{index}/history — прежние версии записи
+func handleHistory(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, ok := parseEntryIndex(r)
+		if !ok {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidIndex))
+			return
+		}
+
+		slangData := loadSlangData(s)
+		visible := visibleIndexes(r, slangData.Entries)
+		if index > len(visible) {
+			respondError(w, r, http.StatusNotFound, errCodeNotFound, tr(r, msgWordNotFound))
+			return
+		}
+
+		revisions := slangData.History[slangData.Entries[visible[index-1]].ID]
+		items := make([]historyItem, len(revisions))
+		for n, revision := range revisions {
+			items[n] = historyItem{Version: n + 1, entryRevision: revision}
+		}
+		respondJSON(w, r, http.StatusOK, items)
+	}
+}
+
+// POST /api/entries/{index}/revert?version=N — восстановление версии N из истории.
+// Текущее содержимое при этом само попадает в историю, так что откат можно отменить.
+func handleRevert(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, ok := parseEntryIndex(r)
+		if !ok {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidIndex))
+			return
+		}
+		version, err := strconv.Atoi(r.URL.Query().Get("version"))
+		if err != nil || version < 1 {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidVersion))
+			return
+		}
+
+		username := usernameFromContext(r.Context())
+		var entry SlangEntry
+		err = modifySlangData(s, func(slangData *SlangData) error {
+			own := ownEntries(slangData.Entries, username)
+			if index > len(own) {
+				return errEntryNotFound
+			}
+			i := own[index-1]
+
+			revisions := slangData.History[slangData.Entries[i].ID]
+			if version > len(revisions) {
+				return errVersionNotFound
+			}
+			revision := revisions[version-1]
+			// Слово могли за это время добавить заново отдельной записью
+			if ownerWordExists(slangData.Entries, username, revision.Word, i) {
+				return errWordExists
+			}
+
+			entry = slangData.Entries[i]
+			entry.Word = revision.Word
+			entry.Meaning = revision.Meaning
+			entry.Example = revision.Example
+			entry.Origin = revision.Origin
+			entry.Synonyms = revision.Synonyms
+			entry.Tags = revision.Tags
+			entry.Private = revision.Private
+			entry = updateEntry(slangData, i, entry, username)
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondJSON(w, r, http.StatusOK, entry)
+	}
+}
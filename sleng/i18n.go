@@ -0,0 +1,534 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Язык сообщений. По умолчанию — русский, как и раньше.
+type lang string
+
+const (
+	langRU lang = "ru"
+	langEN lang = "en"
+
+	defaultLang = langRU
+)
+
+// Язык по коду вроде "en", "en-US" или "ru_RU.UTF-8"; ok == false, если язык не поддерживается
+func parseLang(code string) (lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_."); i >= 0 {
+		code = code[:i]
+	}
+	switch l := lang(code); l {
+	case langRU, langEN:
+		return l, true
+	}
+	return "", false
+}
+
+// Идентификатор сообщения в каталоге. Тексты живут только в каталоге,
+// в коде на них ссылаются по идентификатору.
+type msgID string
+
+// Сообщения API
+const (
+	msgInvalidJSON            msgID = "invalid_json"
+	msgUnknownJSONField       msgID = "unknown_json_field"
+	msgBodyTooLarge           msgID = "body_too_large"
+	msgContentTypeNotJSON     msgID = "content_type_not_json"
+	msgIdempotencyKeyTooLong  msgID = "idempotency_key_too_long"
+	msgIdempotencyKeyReused   msgID = "idempotency_key_reused"
+	msgEntryChanged           msgID = "entry_changed"
+	msgIfMatchRequired        msgID = "if_match_required"
+	msgInvalidJSONArray       msgID = "invalid_json_array"
+	msgInvalidUrbanExport     msgID = "invalid_urban_export"
+	msgUnknownImportSource    msgID = "unknown_import_source"
+	msgInvalidIndex           msgID = "invalid_index"
+	msgInvalidBatchIndex      msgID = "invalid_batch_index"
+	msgBatchKeysRequired      msgID = "batch_keys_required"
+	msgBatchTooLarge          msgID = "batch_too_large"
+	msgUnknownSearchField     msgID = "unknown_search_field"
+	msgUnknownFormat          msgID = "unknown_format"
+	msgQueryRequired          msgID = "query_required"
+	msgSearchArgRequired      msgID = "search_arg_required"
+	msgSearchModeConflict     msgID = "search_mode_conflict"
+	msgInvalidSeed            msgID = "invalid_seed"
+	msgInvalidSince           msgID = "invalid_since"
+	msgInvalidLastEventID     msgID = "invalid_last_event_id"
+	msgWebhookAdded           msgID = "webhook_added"
+	msgWebhookUpdated         msgID = "webhook_updated"
+	msgWebhookDeleted         msgID = "webhook_deleted"
+	msgInvalidVersion         msgID = "invalid_version"
+	msgInvalidDirection       msgID = "invalid_direction"
+	msgWordAndMeaningRequired msgID = "word_and_meaning_required"
+	msgWordExists             msgID = "word_exists"
+	msgUserExists             msgID = "user_exists"
+	msgTokenIssueFailed       msgID = "token_issue_failed"
+	msgBackupListFailed       msgID = "backup_list_failed"
+	msgSaveFailed             msgID = "save_failed"
+	msgPasswordSaveFailed     msgID = "password_save_failed"
+	msgSerializeFailed        msgID = "serialize_failed"
+	msgBackupFailed           msgID = "backup_failed"
+	msgRestoreFailed          msgID = "restore_failed"
+	msgTrashNotFound          msgID = "trash_not_found"
+	msgNothingToBackup        msgID = "nothing_to_backup"
+	msgUserNotRegistered      msgID = "user_not_registered"
+	msgDictionaryEmpty        msgID = "dictionary_empty"
+	msgWordNotFound           msgID = "word_not_found"
+	msgVersionNotFound        msgID = "version_not_found"
+	msgBackupsUnsupported     msgID = "backups_unsupported"
+	msgTooManyLogins          msgID = "too_many_logins"
+	msgAccountLocked          msgID = "account_locked"
+	msgAdminOnly              msgID = "admin_only"
+	msgInvalidLogin           msgID = "invalid_login"
+	msgWrongPassword          msgID = "wrong_password"
+	msgRegisterFirst          msgID = "register_first"
+	msgAuthRequired           msgID = "auth_required"
+	msgWordAdded              msgID = "word_added"
+	msgWordDeleted            msgID = "word_deleted"
+	msgDryRunDelete           msgID = "dry_run_delete"
+	msgInvalidDeleteList      msgID = "invalid_delete_list"
+	msgWordDeletedForever     msgID = "word_deleted_forever"
+	msgPasswordChanged        msgID = "password_changed"
+	msgAccountDeleted         msgID = "account_deleted"
+	msgRegistered             msgID = "registered"
+	msgLoggedIn               msgID = "logged_in"
+	msgLoggedOut              msgID = "logged_out"
+	msgRestored               msgID = "restored"
+	msgEntryRejected          msgID = "entry_rejected"
+	msgEntryRejectedReasons   msgID = "entry_rejected_reasons"
+)
+
+// Тексты ошибок проверки
+const (
+	msgWrongCurrentPassword msgID = "wrong_current_password"
+	msgTokenInvalid         msgID = "token_invalid"
+	msgTokenExpired         msgID = "token_expired"
+	msgTokenRevoked         msgID = "token_revoked"
+	msgTokenOrphan          msgID = "token_orphan"
+	msgTokenStale           msgID = "token_stale"
+	msgTokenCheckFailed     msgID = "token_check_failed"
+	msgUnknownBackup        msgID = "unknown_backup"
+	msgBadBackup            msgID = "bad_backup"
+	msgInvalidNonNegative   msgID = "invalid_non_negative"
+	msgInvalidBoolParam     msgID = "invalid_bool_param"
+	msgInvalidThreshold     msgID = "invalid_threshold"
+	msgUnknownSortKey       msgID = "unknown_sort_key"
+	msgUnknownEntryField    msgID = "unknown_entry_field"
+	msgFieldTooLong         msgID = "field_too_long"
+	msgTooManySynonyms      msgID = "too_many_synonyms"
+	msgSynonymTooLong       msgID = "synonym_too_long"
+	msgTooManyTags          msgID = "too_many_tags"
+	msgTagTooLong           msgID = "tag_too_long"
+	msgMeaningTooShort      msgID = "meaning_too_short"
+	msgMeaningRepeatsWord   msgID = "meaning_repeats_word"
+	msgMeaningAllCaps       msgID = "meaning_all_caps"
+	msgBannedWords          msgID = "banned_words"
+)
+
+// Консоль
+const (
+	msgAppTitle                msgID = "app_title"
+	msgAPIStarting             msgID = "api_starting"
+	msgServerStartFailed       msgID = "server_start_failed"
+	msgStoppingServer          msgID = "stopping_server"
+	msgStoreOpenFailed         msgID = "store_open_failed"
+	msgMainMenu                msgID = "main_menu"
+	msgMenuRegister            msgID = "menu_register"
+	msgMenuLogin               msgID = "menu_login"
+	msgMenuQuit                msgID = "menu_quit"
+	msgChooseAction            msgID = "choose_action"
+	msgRegisteredCLI           msgID = "registered_cli"
+	msgGoodbye                 msgID = "goodbye"
+	msgInvalidChoice           msgID = "invalid_choice"
+	msgPromptNewUsername       msgID = "prompt_new_username"
+	msgUsernameEmpty           msgID = "username_empty"
+	msgUsernameTaken           msgID = "username_taken"
+	msgPromptNewPassword       msgID = "prompt_new_password"
+	msgPasswordMinLength       msgID = "password_min_length"
+	msgPasswordTooLong         msgID = "password_too_long"
+	msgPasswordNeedsDigit      msgID = "password_needs_digit"
+	msgPasswordNeedsLetter     msgID = "password_needs_letter"
+	msgPasswordNeedsMixedCase  msgID = "password_needs_mixed_case"
+	msgResetRequested          msgID = "reset_requested"
+	msgResetTokenInvalid       msgID = "reset_token_invalid"
+	msgResetTokenExpired       msgID = "reset_token_expired"
+	msgPasswordSaveFailedErr   msgID = "password_save_failed_err"
+	msgUserSaveFailed          msgID = "user_save_failed"
+	msgUserRegistered          msgID = "user_registered"
+	msgMustRegister            msgID = "must_register"
+	msgPromptUsername          msgID = "prompt_username"
+	msgPromptPassword          msgID = "prompt_password"
+	msgWelcome                 msgID = "welcome"
+	msgWordsLoaded             msgID = "words_loaded"
+	msgLoginAttemptsLeft       msgID = "login_attempts_left"
+	msgLoginFailedCLI          msgID = "login_failed_cli"
+	msgWhatToDo                msgID = "what_to_do"
+	msgMenuList                msgID = "menu_list"
+	msgMenuSearch              msgID = "menu_search"
+	msgMenuAdd                 msgID = "menu_add"
+	msgMenuEdit                msgID = "menu_edit"
+	msgMenuDelete              msgID = "menu_delete"
+	msgMenuChangePassword      msgID = "menu_change_password"
+	msgMenuDeleteAccount       msgID = "menu_delete_account"
+	msgMenuExit                msgID = "menu_exit"
+	msgYourChoice              msgID = "your_choice"
+	msgNoSuchOption            msgID = "no_such_option"
+	msgDictionaryEmptyCLI      msgID = "dictionary_empty_cli"
+	msgTotalWords              msgID = "total_words"
+	msgPagePrompt              msgID = "page_prompt"
+	msgPromptSearchQuery       msgID = "prompt_search_query"
+	msgNothingFoundCLI         msgID = "nothing_found_cli"
+	msgUnknownCommand          msgID = "unknown_command"
+	msgCommandUserRequired     msgID = "command_user_required"
+	msgCommandIndexRequired    msgID = "command_index_required"
+	msgPromptOpenFound         msgID = "prompt_open_found"
+	msgPromptEntryAction       msgID = "prompt_entry_action"
+	msgEntryWord               msgID = "entry_word"
+	msgEntryMeaning            msgID = "entry_meaning"
+	msgEntryExample            msgID = "entry_example"
+	msgEntryOrigin             msgID = "entry_origin"
+	msgEntrySynonyms           msgID = "entry_synonyms"
+	msgEntryTags               msgID = "entry_tags"
+	msgAddingWord              msgID = "adding_word"
+	msgPromptWord              msgID = "prompt_word"
+	msgWordAlreadyInDictionary msgID = "word_already_in_dictionary"
+	msgPromptMeaning           msgID = "prompt_meaning"
+	msgPromptExample           msgID = "prompt_example"
+	msgPromptOrigin            msgID = "prompt_origin"
+	msgPromptSynonyms          msgID = "prompt_synonyms"
+	msgPromptTags              msgID = "prompt_tags"
+	msgWordNotAdded            msgID = "word_not_added"
+	msgWordSaveFailed          msgID = "word_save_failed"
+	msgWordAddedCLI            msgID = "word_added_cli"
+	msgNothingToDelete         msgID = "nothing_to_delete"
+	msgPromptDeleteIndex       msgID = "prompt_delete_index"
+	msgNothingToEdit           msgID = "nothing_to_edit"
+	msgPromptEditIndex         msgID = "prompt_edit_index"
+	msgEditingWord             msgID = "editing_word"
+	msgCurrentValue            msgID = "current_value"
+	msgWordNotChanged          msgID = "word_not_changed"
+	msgWordUnchangedCLI        msgID = "word_unchanged_cli"
+	msgWordUpdatedCLI          msgID = "word_updated_cli"
+	msgNoSuchNumber            msgID = "no_such_number"
+	msgInputCancelled          msgID = "input_cancelled"
+	msgConfirmDelete           msgID = "confirm_delete"
+	msgWordDeleteFailed        msgID = "word_delete_failed"
+	msgWordMovedToTrash        msgID = "word_moved_to_trash"
+	msgWordGoneCLI             msgID = "word_gone_cli"
+	msgDeletionCancelled       msgID = "deletion_cancelled"
+	msgPromptCurrentPassword   msgID = "prompt_current_password"
+	msgPromptChangedPassword   msgID = "prompt_changed_password"
+	msgUserNotFound            msgID = "user_not_found"
+	msgPasswordNotChanged      msgID = "password_not_changed"
+	msgAccountWillBeDeleted    msgID = "account_will_be_deleted"
+	msgConfirmUsername         msgID = "confirm_username"
+	msgWrongPasswordCancelled  msgID = "wrong_password_cancelled"
+	msgConfirmDeleteWords      msgID = "confirm_delete_words"
+	msgAccountDeleteFailed     msgID = "account_delete_failed"
+	msgAccountDeletedCLI       msgID = "account_deleted_cli"
+)
+
+type translation struct {
+	ru, en string
+}
+
+// Каталог сообщений. Тексты с %s, %d и т.п. форматируются аргументами t и tr.
+var messages = map[msgID]translation{
+	msgInvalidJSON:            {"Неверный JSON", "Invalid JSON"},
+	msgUnknownJSONField:       {"Неизвестное поле: %s", "Unknown field: %s"},
+	msgBodyTooLarge:           {"Тело запроса больше %d байт", "Request body is larger than %d bytes"},
+	msgContentTypeNotJSON:     {"Тело запроса должно быть JSON с заголовком Content-Type: application/json", "Request body must be JSON sent with Content-Type: application/json"},
+	msgIdempotencyKeyTooLong:  {"Idempotency-Key длиннее %d символов", "Idempotency-Key is longer than %d characters"},
+	msgIdempotencyKeyReused:   {"Idempotency-Key уже использован для запроса с другим телом", "Idempotency-Key was already used for a request with a different body"},
+	msgEntryChanged:           {"Запись изменилась после того, как вы её загрузили: загрузите её заново", "The entry has changed since you loaded it: load it again"},
+	msgIfMatchRequired:        {"Нужен заголовок If-Match с ETag записи", "The If-Match header with the entry ETag is required"},
+	msgInvalidJSONArray:       {"Неверный JSON: ожидается массив записей", "Invalid JSON: expected an array of entries"},
+	msgInvalidUrbanExport:     {"Неверный JSON: ожидается экспорт Urban Dictionary ({\"list\": [...]} или массив определений)", "Invalid JSON: expected an Urban Dictionary export ({\"list\": [...]} or an array of definitions)"},
+	msgUnknownImportSource:    {"Неизвестный источник импорта: допустимы json и urban", "Unknown import source: use json or urban"},
+	msgInvalidIndex:           {"Неверный индекс", "Invalid index"},
+	msgInvalidBatchIndex:      {"Неверный номер записи: %s", "Invalid entry index: %s"},
+	msgBatchKeysRequired:      {"Укажите ids или indices — записи через запятую", "Specify ids or indices as a comma-separated list"},
+	msgBatchTooLarge:          {"Запрошено %d записей, можно не больше %d", "%d entries requested, at most %d allowed"},
+	msgUnknownSearchField:     {"Неизвестное поле для поиска: %s", "Unknown search field: %s"},
+	msgUnknownFormat:          {"Неизвестный формат: %s", "Unknown format: %s"},
+	msgQueryRequired:          {"Параметр q обязателен", "Parameter q is required"},
+	msgSearchArgRequired:      {"С -json запрос пишется сразу после номера пункта: 2 кринж", "With -json, put the query right after the option number: 2 cringe"},
+	msgSearchModeConflict:     {"Параметры fuzzy и phonetic нельзя использовать вместе", "Parameters fuzzy and phonetic cannot be used together"},
+	msgInvalidSeed:            {"Параметр seed должен быть неотрицательным числом", "Parameter seed must be a non-negative number"},
+	msgInvalidSince:           {"Параметр since должен быть в формате RFC3339", "Parameter since must be in RFC3339 format"},
+	msgInvalidLastEventID:     {"Last-Event-ID должен быть неотрицательным числом", "Last-Event-ID must be a non-negative number"},
+	msgWebhookAdded:           {"Добавлено слово «%s»: %s", "Word added: \"%s\" — %s"},
+	msgWebhookUpdated:         {"Изменено слово «%s»", "Word updated: \"%s\""},
+	msgWebhookDeleted:         {"Удалено слово «%s»", "Word deleted: \"%s\""},
+	msgInvalidVersion:         {"Параметр version должен быть номером версии (с 1)", "Parameter version must be a version number (starting from 1)"},
+	msgInvalidDirection:       {"Поле direction должно быть \"up\" или \"down\"", "Field direction must be \"up\" or \"down\""},
+	msgWordAndMeaningRequired: {"Слово и значение обязательны", "Word and meaning are required"},
+	msgWordExists:             {"Слово уже существует", "Word already exists"},
+	msgUserExists:             {"Пользователь с таким логином уже существует", "A user with this username already exists"},
+	msgTokenIssueFailed:       {"Не удалось выдать токен", "Failed to issue a token"},
+	msgBackupListFailed:       {"Не удалось прочитать список копий", "Failed to read the list of backups"},
+	msgSaveFailed:             {"Не удалось сохранить данные", "Failed to save data"},
+	msgPasswordSaveFailed:     {"Не удалось сохранить пароль", "Failed to save the password"},
+	msgSerializeFailed:        {"Ошибка при сериализации", "Serialization error"},
+	msgBackupFailed:           {"Не удалось сделать копию: %v", "Failed to create a backup: %v"},
+	msgRestoreFailed:          {"Не удалось восстановить копию: %v", "Failed to restore the backup: %v"},
+	msgTrashNotFound:          {"В корзине нет такой записи", "No such entry in the trash"},
+	msgNothingToBackup:        {"Нечего сохранять: файл словаря ещё не создан", "Nothing to back up: the dictionary file has not been created yet"},
+	msgUserNotRegistered:      {"Пользователь не зарегистрирован", "User is not registered"},
+	msgDictionaryEmpty:        {"Словарь пуст", "The dictionary is empty"},
+	msgWordNotFound:           {"Слово не найдено", "Word not found"},
+	msgVersionNotFound:        {"Такой версии нет", "No such version"},
+	msgBackupsUnsupported:     {"Резервные копии поддерживаются только для JSON-хранилища", "Backups are only supported for JSON storage"},
+	msgTooManyLogins:          {"Слишком много попыток входа, попробуйте позже", "Too many login attempts, try again later"},
+	msgAccountLocked:          {"Слишком много неудачных попыток входа, аккаунт заблокирован. Попробуйте через %d мин.", "Too many failed login attempts, the account is locked. Try again in %d min"},
+	msgAdminOnly:              {"Доступно только администратору", "Administrator access required"},
+	msgInvalidLogin:           {"Неверный логин или пароль", "Invalid username or password"},
+	msgWrongPassword:          {"Неверный пароль", "Wrong password"},
+	msgRegisterFirst:          {"Сначала зарегистрируйтесь", "Please register first"},
+	msgAuthRequired:           {"Требуется авторизация", "Authorization required"},
+	msgWordAdded:              {"Слово добавлено", "Word added"},
+	msgWordDeleted:            {"Слово удалено", "Word deleted"},
+	msgDryRunDelete:           {"Пробный запуск: будет удалено записей: %d. Ничего не изменено", "Dry run: %d entries would be deleted. Nothing was changed"},
+	msgInvalidDeleteList:      {"Неверный JSON: ожидается непустой массив ID или слов", "Invalid JSON: expected a non-empty array of IDs or words"},
+	msgWordDeletedForever:     {"Слово удалено насовсем", "Word deleted permanently"},
+	msgPasswordChanged:        {"Пароль изменён", "Password changed"},
+	msgAccountDeleted:         {"Аккаунт удалён", "Account deleted"},
+	msgRegistered:             {"Регистрация успешна", "Registration successful"},
+	msgLoggedIn:               {"Успешный вход", "Logged in successfully"},
+	msgLoggedOut:              {"Вы вышли из системы", "You have logged out"},
+	msgRestored:               {"Словарь восстановлен из %s", "Dictionary restored from %s"},
+	msgEntryRejected:          {"Запись отклонена", "Entry rejected"},
+	msgEntryRejectedReasons:   {"Запись отклонена: %s", "Entry rejected: %s"},
+
+	msgWrongCurrentPassword: {"неверный текущий пароль", "wrong current password"},
+	msgTokenInvalid:         {"неверный токен", "invalid token"},
+	msgTokenExpired:         {"срок действия токена истёк", "the token has expired"},
+	msgTokenRevoked:         {"токен отозван, войдите заново", "the token has been revoked, please log in again"},
+	msgTokenOrphan:          {"аккаунт этого токена удалён, войдите заново", "the account of this token has been deleted, please log in again"},
+	msgTokenStale:           {"пароль сменился после выдачи токена, войдите заново", "the password has changed since the token was issued, please log in again"},
+	msgTokenCheckFailed:     {"Не удалось проверить токен", "Failed to verify the token"},
+	msgUnknownBackup:        {"нет такой резервной копии", "no such backup"},
+	msgBadBackup:            {"резервная копия повреждена", "the backup is corrupted"},
+	msgInvalidNonNegative:   {"параметр %s должен быть неотрицательным числом", "parameter %s must be a non-negative number"},
+	msgInvalidBoolParam:     {"Параметр %s должен быть true или false", "Parameter %s must be true or false"},
+	msgInvalidThreshold:     {"Параметр threshold должен быть числом от 0.5 до 1", "Parameter threshold must be a number from 0.5 to 1"},
+	msgUnknownSortKey:       {"неизвестный ключ сортировки: %s", "unknown sort key: %s"},
+	msgUnknownEntryField:    {"неизвестное поле записи: %s", "unknown entry field: %s"},
+	msgFieldTooLong:         {"поле %s слишком длинное: %d символов, максимум %d", "field %s is too long: %d characters, maximum %d"},
+	msgTooManySynonyms:      {"поле synonyms: слишком много синонимов (%d), максимум %d", "field synonyms: too many synonyms (%d), maximum %d"},
+	msgSynonymTooLong:       {"поле synonyms: синоним %q слишком длинный (%d символов), максимум %d", "field synonyms: synonym %q is too long (%d characters), maximum %d"},
+	msgTooManyTags:          {"поле tags: слишком много тегов (%d), максимум %d", "field tags: too many tags (%d), maximum %d"},
+	msgTagTooLong:           {"поле tags: тег %q слишком длинный (%d символов), максимум %d", "field tags: tag %q is too long (%d characters), maximum %d"},
+	msgMeaningTooShort:      {"значение слишком короткое: нужно хотя бы %d буквы", "the meaning is too short: at least %d letters are required"},
+	msgMeaningRepeatsWord:   {"значение не должно повторять само слово", "the meaning must not repeat the word itself"},
+	msgMeaningAllCaps:       {"значение написано капсом", "the meaning is written in all caps"},
+	msgBannedWords:          {"запись содержит запрещённые слова", "the entry contains banned words"},
+
+	msgAppTitle:                {"Словарь современного сленга", "Modern slang dictionary"},
+	msgAPIStarting:             {"\n🔧 Запуск API на", "\n🔧 Starting API at"},
+	msgServerStartFailed:       {"❌ Ошибка запуска сервера: %v", "❌ Failed to start the server: %v"},
+	msgStoppingServer:          {"\nОстанавливаем сервер...", "\nStopping the server..."},
+	msgStoreOpenFailed:         {"❌ Не удалось открыть хранилище:", "❌ Failed to open the storage:"},
+	msgMainMenu:                {"\n=== ГЛАВНОЕ МЕНЮ ===", "\n=== MAIN MENU ==="},
+	msgMenuRegister:            {"1. Регистрация", "1. Register"},
+	msgMenuLogin:               {"2. Вход", "2. Log in"},
+	msgMenuQuit:                {"3. Выход", "3. Quit"},
+	msgChooseAction:            {"Выберите действие: ", "Choose an action: "},
+	msgRegisteredCLI:           {"Регистрация успешна! Теперь войдите в систему.", "Registration successful! Now log in."},
+	msgGoodbye:                 {"До свидания!", "Goodbye!"},
+	msgInvalidChoice:           {"Неверный выбор, попробуйте еще раз", "Invalid choice, try again"},
+	msgPromptNewUsername:       {"Придумайте логин: ", "Choose a username: "},
+	msgUsernameEmpty:           {"Логин не может быть пустым", "Username cannot be empty"},
+	msgUsernameTaken:           {"Такой логин уже занят. Придумайте другой или используйте вход.", "This username is taken. Choose another one or log in."},
+	msgPromptNewPassword:       {"Придумайте пароль: ", "Choose a password: "},
+	msgPasswordMinLength:       {"Пароль должен быть не короче %d символов", "The password must be at least %d characters long"},
+	msgPasswordTooLong:         {"Пароль должен быть не длиннее %d байт", "The password must be at most %d bytes long"},
+	msgPasswordNeedsDigit:      {"Пароль должен содержать хотя бы одну цифру", "The password must contain at least one digit"},
+	msgPasswordNeedsLetter:     {"Пароль должен содержать хотя бы одну букву", "The password must contain at least one letter"},
+	msgPasswordNeedsMixedCase:  {"Пароль должен содержать и строчные, и заглавные буквы", "The password must contain both lowercase and uppercase letters"},
+	msgResetRequested:          {"Запрос на сброс пароля принят. Токен для сброса выдаст администратор", "Password reset requested. An administrator will give you the reset token"},
+	msgResetTokenInvalid:       {"Токен сброса пароля неверный или уже использован", "The password reset token is invalid or has already been used"},
+	msgResetTokenExpired:       {"Срок действия токена сброса пароля истёк, запросите новый", "The password reset token has expired, request a new one"},
+	msgPasswordSaveFailedErr:   {"Не удалось сохранить пароль:", "Failed to save the password:"},
+	msgUserSaveFailed:          {"Не удалось сохранить пользователя:", "Failed to save the user:"},
+	msgUserRegistered:          {"Пользователь '%s' успешно зарегистрирован!", "User '%s' registered successfully!"},
+	msgMustRegister:            {"Сначала необходимо зарегистрироваться!", "You need to register first!"},
+	msgPromptUsername:          {"Логин: ", "Username: "},
+	msgPromptPassword:          {"Пароль: ", "Password: "},
+	msgWelcome:                 {"Добро пожаловать, %s!", "Welcome, %s!"},
+	msgWordsLoaded:             {"Загружено слов: %d", "Words loaded: %d"},
+	msgLoginAttemptsLeft:       {"Неверный логин или пароль. Осталось попыток: %d", "Invalid username or password. Attempts left: %d"},
+	msgLoginFailedCLI:          {"Неверный логин или пароль. Попробуйте начать с главного меню.", "Invalid username or password. Try again from the main menu."},
+	msgWhatToDo:                {"Что будем делать?", "What shall we do?"},
+	msgMenuList:                {"1. Посмотреть все слова", "1. Show all words"},
+	msgMenuSearch:              {"2. Поиск слова", "2. Search for a word"},
+	msgMenuAdd:                 {"3. Добавить новое слово", "3. Add a new word"},
+	msgMenuEdit:                {"4. Изменить слово", "4. Edit a word"},
+	msgMenuDelete:              {"5. Удалить слово", "5. Delete a word"},
+	msgMenuChangePassword:      {"6. Сменить пароль", "6. Change password"},
+	msgMenuDeleteAccount:       {"7. Удалить аккаунт", "7. Delete account"},
+	msgMenuExit:                {"8. Выйти из приложения", "8. Exit the application"},
+	msgYourChoice:              {"Твой выбор: ", "Your choice: "},
+	msgNoSuchOption:            {"Такого варианта нет, попробуй еще раз", "No such option, try again"},
+	msgDictionaryEmptyCLI:      {"В словаре пока ничего нет", "The dictionary is empty so far"},
+	msgTotalWords:              {"\nВсего слов: %d", "\nTotal words: %d"},
+	msgPagePrompt:              {"Страница %d из %d. Enter — дальше, b — назад, q — закончить просмотр: ", "Page %d of %d. Enter for next, b for back, q to stop: "},
+	msgPromptSearchQuery:       {"Что искать (слово, значение или пример): ", "Search for (word, meaning or example): "},
+	msgNothingFoundCLI:         {"По запросу «%s» ничего не найдено", "Nothing found for \"%s\""},
+	msgUnknownCommand:          {"Неизвестная команда %q, есть: %s", "Unknown command %q, available: %s"},
+	msgCommandUserRequired:     {"Укажите пользователя: --user или SLENG_USER", "Specify the user: --user or SLENG_USER"},
+	msgCommandIndexRequired:    {"Укажите номер записи: --index N (с 1, как в sleng list)", "Specify the entry number: --index N (from 1, as in sleng list)"},
+	msgPromptOpenFound:         {"\nНомер слова, чтобы открыть его (Enter — вернуться в меню): ", "\nNumber of the word to open (Enter to return to the menu): "},
+	msgPromptEntryAction:       {"1 — изменить, 2 — удалить, Enter — вернуться в меню: ", "1 to edit, 2 to delete, Enter to return to the menu: "},
+	msgEntryWord:               {"%d. Слово: %s", "%d. Word: %s"},
+	msgEntryMeaning:            {"   Значение: %s", "   Meaning: %s"},
+	msgEntryExample:            {"   Пример: %s", "   Example: %s"},
+	msgEntryOrigin:             {"   Откуда: %s", "   Origin: %s"},
+	msgEntrySynonyms:           {"   Похожие слова: %s", "   Similar words: %s"},
+	msgEntryTags:               {"   Теги: %s", "   Tags: %s"},
+	msgAddingWord:              {"\nДобавляем новое слово", "\nAdding a new word"},
+	msgPromptWord:              {"Какое слово? ", "Which word? "},
+	msgWordAlreadyInDictionary: {"Слово '%s' уже есть в словаре", "The word '%s' is already in the dictionary"},
+	msgPromptMeaning:           {"Что оно означает? ", "What does it mean? "},
+	msgPromptExample:           {"Приведи пример использования: ", "Give an example of usage: "},
+	msgPromptOrigin:            {"Откуда оно произошло (можно пропустить)? ", "Where does it come from (optional)? "},
+	msgPromptSynonyms:          {"Какие есть похожие слова (через запятую, можно пропустить)? ", "Similar words (comma-separated, optional)? "},
+	msgPromptTags:              {"Теги, например gaming, gen-z (через запятую, можно пропустить)? ", "Tags, e.g. gaming, gen-z (comma-separated, optional)? "},
+	msgWordNotAdded:            {"Слово не добавлено:", "The word was not added:"},
+	msgWordSaveFailed:          {"Не удалось сохранить слово:", "Failed to save the word:"},
+	msgWordAddedCLI:            {"Отлично! Слово '%s' добавлено в словарь", "Great! The word '%s' has been added to the dictionary"},
+	msgNothingToDelete:         {"В словаре ничего нет, удалять нечего", "The dictionary is empty, nothing to delete"},
+	msgPromptDeleteIndex:       {"\nКакое слово удаляем (введи номер)? ", "\nWhich word should be deleted (enter its number)? "},
+	msgNothingToEdit:           {"В словаре ничего нет, изменять нечего", "The dictionary is empty, nothing to edit"},
+	msgPromptEditIndex:         {"\nКакое слово изменяем (введи номер)? ", "\nWhich word should be edited (enter its number)? "},
+	msgEditingWord:             {"\nИзменяем слово '%s'. Enter — оставить как есть, «-» — очистить поле", "\nEditing the word '%s'. Enter keeps the current value, \"-\" clears the field"},
+	msgCurrentValue:            {"   Сейчас: %s", "   Current: %s"},
+	msgWordNotChanged:          {"Слово не изменено:", "The word was not changed:"},
+	msgWordUnchangedCLI:        {"Ничего не поменялось", "Nothing has changed"},
+	msgWordUpdatedCLI:          {"Готово! Слово '%s' изменено", "Done! The word '%s' has been updated"},
+	msgNoSuchNumber:            {"Нет такого номера", "No such number"},
+	msgInputCancelled:          {"Ввод прерван, действие отменено", "Input ended, the action was cancelled"},
+	msgConfirmDelete:           {"Точно удалить '%s'? (да/нет): ", "Really delete '%s'? (yes/no): "},
+	msgWordDeleteFailed:        {"Не удалось удалить слово:", "Failed to delete the word:"},
+	msgWordMovedToTrash:        {"Слово '%s' перенесено в корзину", "The word '%s' has been moved to the trash"},
+	msgWordGoneCLI:             {"Слова '%s' уже нет в словаре: его удалили через API или другую консоль", "The word '%s' is no longer in the dictionary: it was deleted via the API or another console"},
+	msgDeletionCancelled:       {"Удаление отменено", "Deletion cancelled"},
+	msgPromptCurrentPassword:   {"Текущий пароль: ", "Current password: "},
+	msgPromptChangedPassword:   {"Новый пароль: ", "New password: "},
+	msgUserNotFound:            {"Пользователь не найден", "User not found"},
+	msgPasswordNotChanged:      {"Пароль не изменён:", "Password not changed:"},
+	msgAccountWillBeDeleted:    {"Аккаунт '%s' будет удалён без возможности восстановления.", "Account '%s' will be deleted permanently."},
+	msgConfirmUsername:         {"Для подтверждения введите свой логин: ", "Type your username to confirm: "},
+	msgWrongPasswordCancelled:  {"Неверный пароль, удаление отменено", "Wrong password, deletion cancelled"},
+	msgConfirmDeleteWords:      {"Удалить и все ваши слова? (да/нет): ", "Delete all your words too? (yes/no): "},
+	msgAccountDeleteFailed:     {"Не удалось удалить аккаунт:", "Failed to delete the account:"},
+	msgAccountDeletedCLI:       {"Аккаунт удалён (удалено слов: %d). До свидания!", "Account deleted (words deleted: %d). Goodbye!"},
+}
+
+// Текст сообщения на языке l; если перевода нет — по-русски
+func translate(l lang, id msgID, args ...any) string {
+	m, ok := messages[id]
+	if !ok {
+		return string(id)
+	}
+	text := m.ru
+	if l == langEN && m.en != "" {
+		text = m.en
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Язык консоли задаётся переменной SLENG_LANG (ru или en)
+var cliLang = envLang("SLENG_LANG")
+
+func envLang(name string) lang {
+	if l, ok := parseLang(os.Getenv(name)); ok {
+		return l
+	}
+	return defaultLang
+}
+
+// Сообщение для консоли
+func t(id msgID, args ...any) string {
+	return translate(cliLang, id, args...)
+}
+
+// Язык ответа API по заголовку Accept-Language: выбирается поддерживаемый язык
+// с наибольшим весом q, при равном весе — первый по порядку
+func requestLang(r *http.Request) lang {
+	best, bestQ := defaultLang, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		l, ok := parseLang(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > bestQ {
+			best, bestQ = l, q
+		}
+	}
+	return best
+}
+
+// Сообщение для ответа API
+func tr(r *http.Request, id msgID, args ...any) string {
+	return translate(requestLang(r), id, args...)
+}
+
+// Ошибка с переводимым текстом; Error() возвращает текст на языке по умолчанию
+type msgError struct {
+	id   msgID
+	args []any
+}
+
+func newMsgError(id msgID, args ...any) error {
+	return &msgError{id: id, args: args}
+}
+
+func (e *msgError) Error() string {
+	return translate(defaultLang, e.id, e.args...)
+}
+
+// Текст ошибки на языке l: переводимые ошибки переводятся, остальные выводятся как есть
+func errorText(l lang, err error) string {
+	var me *msgError
+	if errors.As(err, &me) {
+		return translate(l, me.id, me.args...)
+	}
+	return err.Error()
+}
+
+// Текст ошибки для ответа API
+func trErr(r *http.Request, err error) string {
+	return errorText(requestLang(r), err)
+}
+
+// Текст ошибки для консоли
+func tErr(err error) string {
+	return errorText(cliLang, err)
+}
+
+// Согласие в консоли: «да» по-русски или «yes» по-английски, в любом регистре
+func isYes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "да", "д", "yes", "y":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ————————————————————————
+//         Повтор запроса с Idempotency-Key
+// ————————————————————————
+
+// Клиент, не дождавшийся ответа из-за сети, повторяет POST /api/entries с тем же
+// заголовком Idempotency-Key и получает исходный ответ вместо 409 «слово уже
+// существует». Ключи у каждого пользователя свои и хранятся в памяти ttl.
+// Запоминаются только успешные ответы: после ошибки запрос с тем же ключом
+// выполняется заново.
+type idempotencyCache struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	keys map[string]*idempotentResult
+}
+
+type idempotentResult struct {
+	bodyHash    [sha256.Size]byte
+	done        chan struct{} // закрывается, когда первый запрос с ключом обработан
+	saved       bool          // ответ успешный и запомнен
+	status      int
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+// Ключ длиннее не принимается, чтобы в памяти не копились произвольно большие строки
+const maxIdempotencyKeyLength = 255
+
+// Ключи добавления записей живут SLENG_IDEMPOTENCY_TTL (по умолчанию 10 минут) —
+// этого хватает на повторы после обрыва связи
+var entryIdempotency = newIdempotencyCache(envDuration("SLENG_IDEMPOTENCY_TTL", 10*time.Minute), time.Minute)
+
+// Создание хранилища ключей; раз в cleanupEvery из памяти удаляются истёкшие
+func newIdempotencyCache(ttl, cleanupEvery time.Duration) *idempotencyCache {
+	c := &idempotencyCache{
+		ttl:  ttl,
+		keys: make(map[string]*idempotentResult),
+	}
+	go func() {
+		for range time.Tick(cleanupEvery) {
+			c.cleanup()
+		}
+	}()
+	return c
+}
+
+// Запись для ключа: уже известная (first == false) или новая, которую
+// вызывающий должен завершить через finish
+func (c *idempotencyCache) begin(key string, bodyHash [sha256.Size]byte) (result *idempotentResult, first bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if result, ok := c.keys[key]; ok && (result.expires.IsZero() || time.Now().Before(result.expires)) {
+		return result, false
+	}
+	result = &idempotentResult{bodyHash: bodyHash, done: make(chan struct{})}
+	c.keys[key] = result
+	return result, true
+}
+
+// Завершение первого запроса: успешный ответ запоминается на ttl, иначе ключ забывается
+func (c *idempotencyCache) finish(key string, result *idempotentResult, capture *responseCapture) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if capture.status >= 200 && capture.status < 300 {
+		result.saved = true
+		result.status = capture.status
+		result.contentType = capture.Header().Get("Content-Type")
+		result.body = capture.body.Bytes()
+		result.expires = time.Now().Add(c.ttl)
+	} else {
+		delete(c.keys, key)
+	}
+	close(result.done)
+}
+
+func (c *idempotencyCache) cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, result := range c.keys {
+		// Ключи запросов, которые ещё обрабатываются, не трогаем
+		if !result.expires.IsZero() && time.Now().After(result.expires) {
+			delete(c.keys, key)
+		}
+	}
+}
+
+// Middleware: без заголовка Idempotency-Key запрос обрабатывается как обычно.
+// Повтор с тем же ключом и тем же телом получает сохранённый ответ с заголовком
+// Idempotent-Replayed: true; если первый запрос ещё обрабатывается, повтор его ждёт.
+// Тот же ключ с другим телом — ошибка клиента: 422 IDEMPOTENCY_KEY_REUSED.
+// Ставится после requireAuth: ключи различаются по пользователю.
+func (c *idempotencyCache) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgIdempotencyKeyTooLong, maxIdempotencyKeyLength))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		bodyHash := sha256.Sum256(body)
+		key = usernameFromContext(r.Context()) + "\x00" + key
+
+		for {
+			result, first := c.begin(key, bodyHash)
+			if result.bodyHash != bodyHash {
+				respondError(w, r, http.StatusUnprocessableEntity, errCodeIdempotencyKeyReused, tr(r, msgIdempotencyKeyReused))
+				return
+			}
+			if first {
+				capture := &responseCapture{ResponseWriter: w}
+				defer c.finish(key, result, capture)
+				next(capture, r)
+				return
+			}
+			<-result.done
+			if result.saved {
+				h := w.Header()
+				h.Set("Content-Type", result.contentType)
+				h.Set("Idempotent-Replayed", "true")
+				w.WriteHeader(result.status)
+				w.Write(result.body)
+				return
+			}
+			// Первый запрос не удался — этот выполняется заново
+		}
+	}
+}
+
+// ResponseWriter, который кроме отправки клиенту запоминает код и тело ответа
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(code int) {
+	if c.status == 0 {
+		c.status = code
+	}
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *responseCapture) Write(p []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	c.body.Write(p)
+	return c.ResponseWriter.Write(p)
+}
+
+func (c *responseCapture) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	srv, store := newTestServer(t)
+	alice := seedUser(t, store, "alice", "1234")
+	bob := seedUser(t, store, "bob", "1234")
+	// Хранилище ключей общее для всех тестов, поэтому ключи уникальны по имени теста
+	key := func(name string) http.Header {
+		return http.Header{"Idempotency-Key": {t.Name() + "-" + name}}
+	}
+	const rizz = `{"word": "ризз", "meaning": "обаяние"}`
+
+	first := doRequestWithHeaders(t, srv, "POST", "/api/entries", alice, rizz, key("rizz"))
+	if first.status != http.StatusCreated || first.header.Get("Idempotent-Replayed") != "" {
+		t.Fatalf("первый запрос: status = %d, replayed %q", first.status, first.header.Get("Idempotent-Replayed"))
+	}
+	// Повтор с тем же ключом — исходный ответ, а не 409
+	retry := doRequestWithHeaders(t, srv, "POST", "/api/entries", alice, rizz, key("rizz"))
+	if retry.status != http.StatusCreated || retry.header.Get("Idempotent-Replayed") != "true" || string(retry.body) != string(first.body) {
+		t.Errorf("повтор: status = %d, replayed %q, body %s", retry.status, retry.header.Get("Idempotent-Replayed"), retry.body)
+	}
+	if n := len(loadSlangData(store).Entries); n != 1 {
+		t.Errorf("после повтора записей %d, ожидалась 1", n)
+	}
+
+	// Без ключа повтор — обычный дубликат
+	expectError(t, doRequest(t, srv, "POST", "/api/entries", alice, rizz), http.StatusConflict, errCodeDuplicateWord)
+	// Тот же ключ с другим телом — ошибка клиента
+	expectError(t, doRequestWithHeaders(t, srv, "POST", "/api/entries", alice, `{"word": "изи", "meaning": "легко"}`, key("rizz")),
+		http.StatusUnprocessableEntity, errCodeIdempotencyKeyReused)
+	// У другого пользователя тот же ключ свой
+	if resp := doRequestWithHeaders(t, srv, "POST", "/api/entries", bob, rizz, key("rizz")); resp.status != http.StatusCreated || resp.header.Get("Idempotent-Replayed") != "" {
+		t.Errorf("ключ другого пользователя: status = %d, replayed %q", resp.status, resp.header.Get("Idempotent-Replayed"))
+	}
+
+	// Неудачный ответ не запоминается: после исправления запрос выполняется заново
+	expectError(t, doRequestWithHeaders(t, srv, "POST", "/api/entries", alice, `{"word": "изи"}`, key("easy")),
+		http.StatusBadRequest, errCodeValidationFailed)
+	if resp := doRequestWithHeaders(t, srv, "POST", "/api/entries", alice, `{"word": "изи", "meaning": "легко"}`, key("easy")); resp.status != http.StatusCreated {
+		t.Errorf("запрос после ошибки: status = %d; body %s", resp.status, resp.body)
+	}
+
+	expectError(t, doRequestWithHeaders(t, srv, "POST", "/api/entries", alice, rizz, http.Header{"Idempotency-Key": {strings.Repeat("k", maxIdempotencyKeyLength+1)}}),
+		http.StatusBadRequest, errCodeInvalidParameter)
+}
+
+// Одновременные запросы с одним ключом: запись добавляется один раз, и все получают 201
+func TestIdempotencyKeyConcurrent(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234")
+	header := http.Header{"Idempotency-Key": {t.Name()}}
+
+	var wg sync.WaitGroup
+	statuses := make([]int, 10)
+	for i := range statuses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// doRequest вызывает t.Fatal, а в горутине это нельзя
+			req, _ := http.NewRequest("POST", srv.URL+"/api/entries", strings.NewReader(`{"word": "краш", "meaning": "симпатия"}`))
+			req.Header = header.Clone()
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			if res, err := srv.Client().Do(req); err == nil {
+				statuses[i] = res.StatusCode
+				res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	for i, status := range statuses {
+		if status != http.StatusCreated {
+			t.Errorf("запрос %d: status = %d", i, status)
+		}
+	}
+	if n := len(loadSlangData(store).Entries); n != 1 {
+		t.Errorf("записей %d, ожидалась 1", n)
+	}
+}
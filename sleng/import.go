@@ -0,0 +1,109 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Ошибка импорта отдельной записи (index — позиция в присланном массиве, с 0)
+type importError struct {
+	Index int    `json:"index"`
+	Word  string `json:"word,omitempty"`
+	Error string `json:"error"`
+}
+
+// Итог импорта
+type importResult struct {
+	Added   int           `json:"added"`
+	Skipped int           `json:"skipped"`
+	Errors  []importError `json:"errors"`
+}
+
+// Добавление пачки записей в словарь пользователя: неверные и повторяющиеся
+// пропускаются с описанием причины
+func importEntries(slangData *SlangData, owner string, entries []SlangEntry, l lang) importResult {
+	result := importResult{Errors: []importError{}}
+	now := time.Now().UTC()
+
+	for i, entry := range entries {
+		sanitizeEntry(&entry)
+		entry.Synonyms = normalizeSynonyms(entry.Synonyms)
+		entry.Tags = normalizeTags(entry.Tags)
+		if err := ValidateEntry(entry); err != nil {
+			result.Skipped++
+			result.Errors = append(result.Errors, importError{Index: i, Word: entry.Word, Error: errorText(l, err)})
+			continue
+		}
+
+		if reasons := validateNewEntry(entry); len(reasons) > 0 {
+			result.Skipped++
+			result.Errors = append(result.Errors, importError{Index: i, Word: entry.Word, Error: translate(l, msgEntryRejectedReasons, strings.Join(reasonTexts(l, reasons), "; "))})
+			continue
+		}
+
+		// Проверяем и по словарю, и по уже добавленным из этой же пачки
+		if ownerWordExists(slangData.Entries, owner, entry.Word, -1) {
+			result.Skipped++
+			result.Errors = append(result.Errors, importError{Index: i, Word: entry.Word, Error: translate(l, msgWordExists)})
+			continue
+		}
+
+		entry.ID = newEntryID()
+		entry.Owner = owner
+		entry.Author = owner
+		entry.LastEditedBy = ""
+		entry.Score = 0
+		entry.CreatedAt = now
+		entry.UpdatedAt = now
+		slangData.Entries = append(slangData.Entries, entry)
+		result.Added++
+	}
+	return result
+}
+
+// POST /api/entries/import — принимает JSON-массив записей и сохраняет их одним разом.
+// С ?source=urban принимает экспорт Urban Dictionary (см. parseUrbanExport).
+func handleImport(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var entries []SlangEntry
+		switch r.URL.Query().Get("source") {
+		case "", "json":
+			if err := readJSON(w, r, &entries); err != nil {
+				respondReadError(w, r, err, msgInvalidJSONArray)
+				return
+			}
+		case "urban":
+			var data []byte
+			err := requireJSONContentType(r)
+			if err == nil {
+				data, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+			}
+			if err == nil {
+				entries, err = parseUrbanExport(data)
+			}
+			if err != nil {
+				respondReadError(w, r, err, msgInvalidUrbanExport)
+				return
+			}
+		default:
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgUnknownImportSource))
+			return
+		}
+
+		var result importResult
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			result = importEntries(slangData, usernameFromContext(r.Context()), entries, requestLang(r))
+			if result.Added == 0 {
+				return errNoChanges
+			}
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondJSON(w, r, http.StatusOK, result)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// ————————————————————————
+//         Блокировка аккаунта после неудачных входов
+// ————————————————————————
+
+// В отличие от loginLimiter, который ограничивает один IP-адрес, блокировка
+// защищает аккаунт от подбора пароля с разных адресов: после maxFailures
+// неудачных попыток подряд вход запрещён на cooldown, даже с верным паролем.
+type loginLockout struct {
+	mu          sync.Mutex
+	maxFailures int
+	cooldown    time.Duration
+	accounts    map[string]*lockoutState
+}
+
+type lockoutState struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// Порог и длительность настраиваются: SLENG_LOCKOUT_THRESHOLD (по умолчанию 5 попыток)
+// и SLENG_LOCKOUT_MINUTES (по умолчанию 15 минут)
+var accountLockout = newLoginLockout(
+	envInt("SLENG_LOCKOUT_THRESHOLD", 5),
+	time.Duration(envInt("SLENG_LOCKOUT_MINUTES", 15))*time.Minute,
+	time.Minute,
+)
+
+// Создание учёта блокировок; раз в cleanupEvery из памяти удаляются аккаунты,
+// у которых блокировка закончилась или последняя ошибка была давно
+func newLoginLockout(maxFailures int, cooldown, cleanupEvery time.Duration) *loginLockout {
+	l := &loginLockout{
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		accounts:    make(map[string]*lockoutState),
+	}
+	go func() {
+		for range time.Tick(cleanupEvery) {
+			l.cleanup()
+		}
+	}()
+	return l
+}
+
+// Сколько ещё действует блокировка аккаунта; 0 — вход разрешён.
+// Когда блокировка заканчивается, счётчик ошибок начинается заново.
+func (l *loginLockout) lockedFor(username string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.accounts[normalizeUsername(username)]
+	if !ok || state.lockedUntil.IsZero() {
+		return 0
+	}
+	if left := time.Until(state.lockedUntil); left > 0 {
+		return left
+	}
+	delete(l.accounts, normalizeUsername(username))
+	return 0
+}
+
+// Учёт неудачной попытки. Считаются и несуществующие логины, чтобы
+// по блокировке нельзя было узнать, есть ли такой пользователь.
+func (l *loginLockout) fail(username string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	username = normalizeUsername(username)
+	state, ok := l.accounts[username]
+	if !ok {
+		state = &lockoutState{}
+		l.accounts[username] = state
+	}
+	state.failures++
+	state.lastFailure = time.Now()
+	if state.failures >= l.maxFailures {
+		state.lockedUntil = state.lastFailure.Add(l.cooldown)
+		logger.Warn("аккаунт временно заблокирован после неудачных входов", "user", username, "failures", state.failures)
+	}
+}
+
+// Успешный вход обнуляет счётчик ошибок
+func (l *loginLockout) succeed(username string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.accounts, normalizeUsername(username))
+}
+
+// Ошибки без блокировки забываются через cooldown после последней из них
+func (l *loginLockout) cleanup() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for username, state := range l.accounts {
+		if now.After(state.lockedUntil) && now.Sub(state.lastFailure) > l.cooldown {
+			delete(l.accounts, username)
+		}
+	}
+}
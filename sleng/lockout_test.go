@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoginLockout(t *testing.T) {
+	defaults := accountLockout
+	t.Cleanup(func() { accountLockout = defaults })
+	accountLockout = newLoginLockout(3, time.Hour, time.Hour)
+
+	// Обработчик без loginLimiter: иначе тест упёрся бы в ограничение по IP
+	store := NewMemoryStore(emptySlangData())
+	seedUser(t, store, "alice", "1234")
+	srv := httptest.NewServer(handleLogin(store))
+	t.Cleanup(srv.Close)
+	login := func(username, password string) testResponse {
+		return doRequest(t, srv, "POST", "/", "", `{"username":"`+username+`","password":"`+password+`"}`)
+	}
+
+	// Успешный вход обнуляет счётчик: две ошибки, вход, ещё две ошибки — блокировки нет
+	for range 2 {
+		expectError(t, login("alice", "0000"), http.StatusUnauthorized, errCodeInvalidCredentials)
+	}
+	if resp := login("alice", "1234"); resp.status != http.StatusOK {
+		t.Fatalf("вход: status = %d; body %s", resp.status, resp.body)
+	}
+	for range 3 {
+		expectError(t, login("Alice", "0000"), http.StatusUnauthorized, errCodeInvalidCredentials)
+	}
+
+	// После трёх ошибок подряд не подходит и верный пароль
+	resp := login("alice", "1234")
+	expectError(t, resp, http.StatusLocked, errCodeAccountLocked)
+	if resp.header.Get("Retry-After") == "" {
+		t.Error("нет Retry-After")
+	}
+	// Другие аккаунты не затронуты; несуществующий логин блокируется так же
+	for range 3 {
+		expectError(t, login("ghost", "0000"), http.StatusUnauthorized, errCodeInvalidCredentials)
+	}
+	expectError(t, login("ghost", "0000"), http.StatusLocked, errCodeAccountLocked)
+
+	// Когда блокировка закончилась, вход снова работает
+	accountLockout.mu.Lock()
+	accountLockout.accounts["alice"].lockedUntil = time.Now().Add(-time.Second)
+	accountLockout.mu.Unlock()
+	if resp := login("alice", "1234"); resp.status != http.StatusOK {
+		t.Errorf("после блокировки: status = %d; body %s", resp.status, resp.body)
+	}
+}
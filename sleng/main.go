@@ -1,462 +1,1856 @@
-package main
-
-import (
-	"bufio"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"os"
-	"strconv"
-	"strings"
-	"sync"
-	"time"
-)
-
-// Структуры остаются без изменений
-type SlangEntry struct {
-	Word     string   `json:"word"`
-	Meaning  string   `json:"meaning"`
-	Example  string   `json:"example"`
-	Origin   string   `json:"origin,omitempty"`
-	Synonyms []string `json:"synonyms,omitempty"`
-}
-
-type User struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-}
-
-type SlangData struct {
-	User     User         `json:"user"`
-	Version  string       `json:"version"`
-	Entries  []SlangEntry `json:"entries"`
-}
-
-const (
-	dataFile = "slang.json"
-)
-
-// Глобальный мьютекс для безопасного доступа к данным из нескольких горутин
-var mu sync.RWMutex
-
-// Загрузка и сохранение остаются почти без изменений
-func loadSlangData() SlangData {
-	mu.RLock()
-	defer mu.RUnlock()
-
-	var slangData SlangData
-	if _, err := os.Stat(dataFile); os.IsNotExist(err) {
-		return SlangData{Version: "1.0", Entries: []SlangEntry{}, User: User{}}
-	}
-	data, err := os.ReadFile(dataFile)
-	if err != nil {
-		fmt.Println("Ошибка чтения файла:", err)
-		return SlangData{Version: "1.0", Entries: []SlangEntry{}, User: User{}}
-	}
-	err = json.Unmarshal(data, &slangData)
-	if err != nil {
-		fmt.Println("Ошибка парсинга JSON:", err)
-		return SlangData{Version: "1.0", Entries: []SlangEntry{}, User: User{}}
-	}
-	return slangData
-}
-
-func saveSlangData(slangData SlangData) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	data, err := json.MarshalIndent(slangData, "", "  ")
-	if err != nil {
-		fmt.Println("Ошибка при сериализации:", err)
-		return
-	}
-	if err := os.WriteFile(dataFile, data, 0644); err != nil {
-		fmt.Println("Ошибка записи файла:", err)
-	}
-}
-
-// ————————————————————————
-//         HTTP API
-// ————————————————————————
-
-// Вспомогательная функция для отправки JSON-ответа
-func respondJSON(w http.ResponseWriter, code int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(payload)
-}
-
-// Вспомогательная функция для чтения JSON из тела запроса
-func readJSON(r *http.Request, dst interface{}) error {
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-	return decoder.Decode(dst)
-}
-
-// GET /api/entries
-func handleGetEntries(w http.ResponseWriter, r *http.Request) {
-	slangData := loadSlangData()
-	respondJSON(w, http.StatusOK, slangData.Entries)
-}
-
-// POST /api/entries
-func handleAddEntry(w http.ResponseWriter, r *http.Request) {
-	var entry SlangEntry
-	if err := readJSON(r, &entry); err != nil {
-		http.Error(w, "Неверный JSON", http.StatusBadRequest)
-		return
-	}
-
-	if strings.TrimSpace(entry.Word) == "" || strings.TrimSpace(entry.Meaning) == "" {
-		http.Error(w, "Слово и значение обязательны", http.StatusBadRequest)
-		return
-	}
-
-	slangData := loadSlangData()
-
-	// Проверка дубликата
-	for _, e := range slangData.Entries {
-		if strings.EqualFold(e.Word, entry.Word) {
-			http.Error(w, "Слово уже существует", http.StatusConflict)
-			return
-		}
-	}
-
-	slangData.Entries = append(slangData.Entries, entry)
-	saveSlangData(slangData)
-	respondJSON(w, http.StatusCreated, map[string]string{"message": "Слово добавлено"})
-}
-
-// DELETE /api/entries/{index}
-func handleDeleteEntry(w http.ResponseWriter, r *http.Request) {
-	indexStr := strings.TrimPrefix(r.URL.Path, "/api/entries/")
-	index, err := strconv.Atoi(indexStr)
-	if err != nil || index < 1 {
-		http.Error(w, "Неверный индекс", http.StatusBadRequest)
-		return
-	}
-
-	slangData := loadSlangData()
-	if index > len(slangData.Entries) {
-		http.Error(w, "Слово не найдено", http.StatusNotFound)
-		return
-	}
-
-	slangData.Entries = append(slangData.Entries[:index-1], slangData.Entries[index:]...)
-	saveSlangData(slangData)
-	respondJSON(w, http.StatusOK, map[string]string{"message": "Слово удалено"})
-}
-
-// GET /api/user
-func handleGetUser(w http.ResponseWriter, r *http.Request) {
-	slangData := loadSlangData()
-	if slangData.User.Username == "" {
-		http.Error(w, "Пользователь не зарегистрирован", http.StatusUnauthorized)
-		return
-	}
-	// Не возвращаем пароль!
-	respondJSON(w, http.StatusOK, map[string]string{"username": slangData.User.Username})
-}
-
-// POST /api/register
-func handleRegister(w http.ResponseWriter, r *http.Request) {
-	type Req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-	}
-	var req Req
-	if err := readJSON(r, &req); err != nil {
-		http.Error(w, "Неверный JSON", http.StatusBadRequest)
-		return
-	}
-
-	if req.Username == "" || len(req.Password) < 4 {
-		http.Error(w, "Логин не может быть пустым, пароль — минимум 4 символа", http.StatusBadRequest)
-		return
-	}
-
-	slangData := loadSlangData()
-	if slangData.User.Username != "" {
-		http.Error(w, "Пользователь уже зарегистрирован", http.StatusConflict)
-		return
-	}
-
-	slangData.User = User{Username: req.Username, Password: req.Password}
-	saveSlangData(slangData)
-	respondJSON(w, http.StatusCreated, map[string]string{"message": "Регистрация успешна"})
-}
-
-// POST /api/login
-func handleLogin(w http.ResponseWriter, r *http.Request) {
-	type Req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-	}
-	var req Req
-	if err := readJSON(r, &req); err != nil {
-		http.Error(w, "Неверный JSON", http.StatusBadRequest)
-		return
-	}
-
-	slangData := loadSlangData()
-	if slangData.User.Username == "" {
-		http.Error(w, "Сначала зарегистрируйтесь", http.StatusUnauthorized)
-		return
-	}
-
-	if req.Username == slangData.User.Username && req.Password == slangData.User.Password {
-		respondJSON(w, http.StatusOK, map[string]string{
-			"message":  "Успешный вход",
-			"username": slangData.User.Username,
-		})
-	} else {
-		http.Error(w, "Неверный логин или пароль", http.StatusUnauthorized)
-	}
-}
-
-// ————————————————————————
-//         Запуск API сервера
-// ————————————————————————
-
-func startAPIServer() {
-	http.HandleFunc("/api/entries", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			handleGetEntries(w, r)
-		case http.MethodPost:
-			handleAddEntry(w, r)
-		default:
-			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
-		}
-	})
-
-	// DELETE по пути /api/entries/123
-	http.HandleFunc("/api/entries/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodDelete {
-			handleDeleteEntry(w, r)
-		} else {
-			http.Error(w, "Только DELETE разрешён для этого пути", http.StatusMethodNotAllowed)
-		}
-	})
-
-	http.HandleFunc("/api/user", handleGetUser)
-	http.HandleFunc("/api/register", handleRegister)
-	http.HandleFunc("/api/login", handleLogin)
-
-	fmt.Println("\n🔧 Запуск API на http://localhost:8080")
-	go func() {
-		if err := http.ListenAndServe(":8080", nil); err != nil {
-			fmt.Printf("❌ Ошибка запуска сервера: %v\n", err)
-		}
-	}()
-}
-
-// ————————————————————————
-//         Основная программа
-// ————————————————————————
-
-func main() {
-	fmt.Println("Словарь современного сленга")
-	fmt.Println("---------------------------")
-
-	startAPIServer()
-
-	for {
-		fmt.Println("\n=== ГЛАВНОЕ МЕНЮ ===")
-		fmt.Println("1. Регистрация")
-		fmt.Println("2. Вход")
-		fmt.Println("3. Выход")
-		fmt.Print("Выберите действие: ")
-
-		var choice string
-		fmt.Scanln(&choice)
-
-		switch choice {
-		case "1":
-			if register() {
-				fmt.Println("Регистрация успешна! Теперь войдите в систему.")
-			}
-		case "2":
-			if login() {
-				runDictionaryApp()
-				return
-			}
-		case "3":
-			fmt.Println("До свидания!")
-			// Добавим небольшую паузу, чтобы API успел завершить работу (опционально)
-			time.Sleep(100 * time.Millisecond)
-			return
-		default:
-			fmt.Println("Неверный выбор, попробуйте еще раз")
-		}
-	}
-}
-
-
-func register() bool {
-	reader := bufio.NewReader(os.Stdin)
-	slangData := loadSlangData()
-	if slangData.User.Username != "" {
-		fmt.Println("Пользователь уже зарегистрирован. Используйте вход.")
-		return false
-	}
-	fmt.Print("Придумайте логин: ")
-	username, _ := reader.ReadString('\n')
-	username = strings.TrimSpace(username)
-	if username == "" {
-		fmt.Println("Логин не может быть пустым")
-		return false
-	}
-	fmt.Print("Придумайте пароль: ")
-	password, _ := reader.ReadString('\n')
-	password = strings.TrimSpace(password)
-	if len(password) < 4 {
-		fmt.Println("Пароль должен содержать минимум 4 символа")
-		return false
-	}
-	slangData.User = User{Username: username, Password: password}
-	saveSlangData(slangData)
-	fmt.Printf("Пользователь '%s' успешно зарегистрирован!\n", username)
-	return true
-}
-
-func login() bool {
-	reader := bufio.NewReader(os.Stdin)
-	slangData := loadSlangData()
-	if slangData.User.Username == "" {
-		fmt.Println("Сначала необходимо зарегистрироваться!")
-		return false
-	}
-	for attempts := 3; attempts > 0; attempts-- {
-		fmt.Print("Логин: ")
-		username, _ := reader.ReadString('\n')
-		username = strings.TrimSpace(username)
-		fmt.Print("Пароль: ")
-		password, _ := reader.ReadString('\n')
-		password = strings.TrimSpace(password)
-		if username == slangData.User.Username && password == slangData.User.Password {
-			fmt.Printf("Добро пожаловать, %s!\n", username)
-			fmt.Printf("Загружено слов: %d\n", len(slangData.Entries))
-			return true
-		}
-		if attempts > 1 {
-			fmt.Printf("Неверный логин или пароль. Осталось попыток: %d\n", attempts-1)
-		} else {
-			fmt.Println("Неверный логин или пароль. Попробуйте начать с главного меню.")
-		}
-	}
-	return false
-}
-
-func runDictionaryApp() {
-	slangData := loadSlangData()
-	for {
-		fmt.Println("")
-		fmt.Println("Что будем делать?")
-		fmt.Println("1. Посмотреть все слова")
-		fmt.Println("2. Добавить новое слово")
-		fmt.Println("3. Удалить слово")
-		fmt.Println("4. Выйти из приложения")
-		fmt.Print("Твой выбор: ")
-
-		var choice string
-		fmt.Scanln(&choice)
-
-		switch choice {
-		case "1":
-			showAllEntries(slangData)
-		case "2":
-			addNewEntry(&slangData)
-		case "3":
-			deleteEntry(&slangData)
-		case "4":
-			fmt.Println("До свидания!")
-			return
-		default:
-			fmt.Println("Такого варианта нет, попробуй еще раз")
-		}
-	}
-}
-
-func showAllEntries(slangData SlangData) {
-	if len(slangData.Entries) == 0 {
-		fmt.Println("В словаре пока ничего нет")
-		return
-	}
-	fmt.Printf("\nВсего слов: %d\n", len(slangData.Entries))
-	fmt.Println("==========================================")
-	for i, entry := range slangData.Entries {
-		fmt.Printf("%d. Слово: %s\n", i+1, entry.Word)
-		fmt.Printf("   Значение: %s\n", entry.Meaning)
-		fmt.Printf("   Пример: %s\n", entry.Example)
-		if entry.Origin != "" {
-			fmt.Printf("   Откуда: %s\n", entry.Origin)
-		}
-		if len(entry.Synonyms) > 0 {
-			fmt.Printf("   Похожие слова: %s\n", strings.Join(entry.Synonyms, ", "))
-		}
-		fmt.Println("------------------------------------------")
-	}
-}
-
-func addNewEntry(slangData *SlangData) {
-	reader := bufio.NewReader(os.Stdin)
-	var entry SlangEntry
-	fmt.Println("\nДобавляем новое слово")
-	fmt.Print("Какое слово? ")
-	word, _ := reader.ReadString('\n')
-	entry.Word = strings.TrimSpace(word)
-	for _, e := range slangData.Entries {
-		if strings.EqualFold(e.Word, entry.Word) {
-			fmt.Printf("Слово '%s' уже есть в словаре\n", entry.Word)
-			return
-		}
-	}
-	fmt.Print("Что оно означает? ")
-	meaning, _ := reader.ReadString('\n')
-	entry.Meaning = strings.TrimSpace(meaning)
-	fmt.Print("Приведи пример использования: ")
-	example, _ := reader.ReadString('\n')
-	entry.Example = strings.TrimSpace(example)
-	fmt.Print("Откуда оно произошло (можно пропустить)? ")
-	origin, _ := reader.ReadString('\n')
-	entry.Origin = strings.TrimSpace(origin)
-	fmt.Print("Какие есть похожие слова (через запятую, можно пропустить)? ")
-	synonyms, _ := reader.ReadString('\n')
-	synonyms = strings.TrimSpace(synonyms)
-	if synonyms != "" {
-		entry.Synonyms = strings.Split(synonyms, ",")
-		for i := range entry.Synonyms {
-			entry.Synonyms[i] = strings.TrimSpace(entry.Synonyms[i])
-		}
-	}
-	slangData.Entries = append(slangData.Entries, entry)
-	saveSlangData(*slangData)
-	fmt.Printf("Отлично! Слово '%s' добавлено в словарь\n", entry.Word)
-}
-
-func deleteEntry(slangData *SlangData) {
-	if len(slangData.Entries) == 0 {
-		fmt.Println("В словаре ничего нет, удалять нечего")
-		return
-	}
-	showAllEntries(*slangData)
-	var index int
-	fmt.Print("\nКакое слово удаляем (введи номер)? ")
-	_, err := fmt.Scanln(&index)
-	if err != nil || index < 1 || index > len(slangData.Entries) {
-		fmt.Println("Нет такого номера")
-		return
-	}
-	wordToDelete := slangData.Entries[index-1].Word
-	fmt.Printf("Точно удалить '%s'? (да/нет): ", wordToDelete)
-	var confirm string
-	fmt.Scanln(&confirm)
-	if strings.ToLower(confirm) == "да" || strings.ToLower(confirm) == "д" || strings.ToLower(confirm) == "y" {
-		slangData.Entries = append(slangData.Entries[:index-1], slangData.Entries[index:]...)
-		saveSlangData(*slangData)
-		fmt.Printf("Слово '%s' удалено\n", wordToDelete)
-	} else {
-		fmt.Println("Удаление отменено")
-	}
-}
\ No newline at end of file
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Структуры остаются без изменений
+type SlangEntry struct {
+	// Постоянный идентификатор (UUID), в отличие от номера не меняется
+	ID   string `json:"id"`
+	Word string `json:"word"`
+	// Каноническая форма слова (см. normalizeWord) для проверки уникальности и поиска
+	// по слову; Word хранит написание, как его ввёл пользователь. Заполняется при
+	// каждом сохранении, присланное клиентом значение не используется.
+	CanonicalWord string   `json:"canonical_word,omitempty"`
+	Meaning       string   `json:"meaning"`
+	Example       string   `json:"example"`
+	Origin        string   `json:"origin,omitempty"`
+	Synonyms      []string `json:"synonyms,omitempty"`
+	// Категории: gaming, finance, gen-z и т.п. (в нижнем регистре)
+	Tags []string `json:"tags,omitempty"`
+	// Рейтинг: сумма голосов за (+1) и против (-1), меняется только через /vote
+	Score int `json:"score"`
+
+	// Владелец записи: у каждого пользователя свой словарь
+	Owner string `json:"owner,omitempty"`
+	// Личное слово не попадает в общий словарь (?shared=true и запросы без токена)
+	Private bool `json:"private,omitempty"`
+	// Кто добавил запись и кто последним менял её содержимое. У записей, добавленных
+	// до появления этих полей, автор пустой; LastEditedBy пуст, пока запись не меняли.
+	Author       string `json:"author,omitempty"`
+	LastEditedBy string `json:"last_edited_by,omitempty"`
+
+	// Время добавления; у записей из старых файлов — нулевое значение
+	CreatedAt time.Time `json:"created_at"`
+	// Время последнего изменения содержимого записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Совпадает ли содержимое двух записей (без учёта служебных полей)
+func sameEntryContent(a, b SlangEntry) bool {
+	return a.Word == b.Word && a.Meaning == b.Meaning && a.Example == b.Example &&
+		a.Origin == b.Origin && slices.Equal(a.Synonyms, b.Synonyms) && slices.Equal(a.Tags, b.Tags) &&
+		a.Private == b.Private
+}
+
+type User struct {
+	Username string `json:"username"`
+	Password string `json:"password"` // bcrypt-хеш пароля
+	// Администратор: видит всех пользователей и удаляет чужие записи (/api/admin/...)
+	IsAdmin bool `json:"is_admin,omitempty"`
+	// Время регистрации: токены, выданные раньше, не принимаются (см. checkTokenUser)
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	// Последняя смена или сброс пароля: токены, выданные раньше, тоже не принимаются
+	PasswordChangedAt time.Time `json:"password_changed_at,omitzero"`
+
+	// Пароль из старого файла, сохранённый в открытом виде
+	legacyPassword bool
+}
+
+type SlangData struct {
+	Users   []User       `json:"users"`
+	Version string       `json:"version"`
+	Entries []SlangEntry `json:"entries"`
+	// Голоса: ID записи -> логин -> +1 (за) или -1 (против)
+	Votes map[string]map[string]int `json:"votes,omitempty"`
+	// Прежние версии записей: ID записи -> версии от старой к новой
+	History map[string][]entryRevision `json:"history,omitempty"`
+	// Корзина: удалённые записи, которые ещё можно восстановить
+	Trash []trashedEntry `json:"trash,omitempty"`
+
+	// Единственный пользователь из файлов старого формата, переносится в Users при загрузке
+	LegacyUser *User `json:"user,omitempty"`
+}
+
+// Загрузка данных из хранилища с миграцией старых форматов
+func loadSlangData(s Store) SlangData {
+	slangData, err := s.Load()
+	if err != nil {
+		logger.Error("ошибка загрузки данных", "error", err)
+		return emptySlangData()
+	}
+	// Обновлённые данные сразу сохраняем, чтобы выданные при миграции ID не менялись
+	if migrateSlangData(&slangData) {
+		if err := s.Save(slangData); err != nil {
+			logger.Error("не удалось сохранить обновлённые данные", "error", err)
+		}
+	}
+	return slangData
+}
+
+// Функция-изменение из modifySlangData возвращает errNoChanges, когда менять нечего
+// (повторный голос, пустой импорт): сохранение пропускается, а modifySlangData возвращает nil
+var errNoChanges = errors.New("изменений нет")
+
+// Изменение данных под блокировкой хранилища (см. Store.Modify). fn получает
+// данные после тех же миграций, что и в loadSlangData; ошибка fn возвращается как есть.
+// После сохранения изменения записей рассылаются (см. publishChanges).
+func modifySlangData(s Store, fn func(*SlangData) error) error {
+	var before, after []SlangEntry
+	err := s.Modify(func(slangData *SlangData) error {
+		migrateSlangData(slangData)
+		before = slices.Clone(slangData.Entries)
+		if err := fn(slangData); err != nil {
+			return err
+		}
+		setCanonicalWords(slangData.Entries)
+		after = slangData.Entries
+		return nil
+	})
+	if errors.Is(err, errNoChanges) {
+		return nil
+	}
+	if err == nil {
+		publishChanges(before, after)
+	}
+	return err
+}
+
+func newEntryID() string {
+	return uuid.NewString()
+}
+
+// Номер записи (с 0) с указанным ID или -1
+func findEntryByID(entries []SlangEntry, id string) int {
+	for i, e := range entries {
+		if e.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Сохранение данных целиком; ошибку должен обработать вызывающий код.
+// Если изменения кому-то нужны (см. changesWanted), рассылаются изменения записей
+// по сравнению с тем, что было в хранилище перед сохранением.
+func saveSlangData(s Store, slangData SlangData) error {
+	var before SlangData
+	if changesWanted() {
+		before, _ = s.Load()
+	}
+	setCanonicalWords(slangData.Entries)
+	if err := s.Save(slangData); err != nil {
+		return err
+	}
+	if before.Entries != nil {
+		publishChanges(before.Entries, slangData.Entries)
+	}
+	return nil
+}
+
+// ————————————————————————
+//         HTTP API
+// ————————————————————————
+
+// Вспомогательная функция для отправки JSON-ответа. По умолчанию JSON компактный,
+// с ?pretty=true — с отступами, чтобы его было удобно читать глазами
+func respondJSON(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	encoder := json.NewEncoder(w)
+	if r.URL.Query().Get("pretty") == "true" {
+		encoder.SetIndent("", "  ")
+	}
+	encoder.Encode(payload)
+}
+
+// Наибольший размер тела запроса (SLENG_MAX_BODY_BYTES, по умолчанию 1 МБ):
+// огромный POST не должен занимать память сервера
+var maxBodyBytes = int64(envInt("SLENG_MAX_BODY_BYTES", 1<<20))
+
+// Вспомогательная функция для чтения JSON из тела запроса. Тело длиннее
+// maxBodyBytes не читается до конца: ошибка *http.MaxBytesError, см. respondReadError.
+func readJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	if err := requireJSONContentType(r); err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(dst)
+}
+
+// Тело запроса принимается только как JSON: без этой проверки форма или текст
+// давали бы невнятную ошибку разбора вместо указания на заголовок
+var errNotJSONBody = newMsgError(msgContentTypeNotJSON)
+
+// Content-Type должен быть application/json; параметры вроде charset=utf-8 допускаются
+func requireJSONContentType(r *http.Request) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return errNotJSONBody
+	}
+	return nil
+}
+
+// Ответ на ошибку чтения тела: 415, если тело не JSON по Content-Type;
+// 413, если тело больше maxBodyBytes; 400 с именем
+// поля, если в JSON есть поле, которого нет у запроса (опечатка вроде "meening");
+// иначе 400 с сообщением msg (в том числе при err == nil — тело прочитано, но не подходит)
+func respondReadError(w http.ResponseWriter, r *http.Request, err error, msg msgID) {
+	if errors.Is(err, errNotJSONBody) {
+		respondError(w, r, http.StatusUnsupportedMediaType, errCodeUnsupportedMediaType, trErr(r, err))
+		return
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondError(w, r, http.StatusRequestEntityTooLarge, errCodePayloadTooLarge, tr(r, msgBodyTooLarge, tooLarge.Limit))
+		return
+	}
+	if field, ok := unknownJSONField(err); ok {
+		respondError(w, r, http.StatusBadRequest, errCodeInvalidJSON, tr(r, msgUnknownJSONField, field))
+		return
+	}
+	respondError(w, r, http.StatusBadRequest, errCodeInvalidJSON, tr(r, msg))
+}
+
+// Имя лишнего поля из ошибки DisallowUnknownFields. Отдельного типа для неё
+// в encoding/json нет, поэтому имя берётся из текста: json: unknown field "meening"
+func unknownJSONField(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	field, err := strconv.Unquote(quoted)
+	return field, err == nil
+}
+
+// Параметры постраничного вывода списка записей
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// Ответ со страницей записей (когда передан limit или offset)
+type entriesPage struct {
+	Total   int          `json:"total"`
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+	Entries []SlangEntry `json:"entries"`
+}
+
+// Чтение неотрицательного целого параметра запроса
+func parseNonNegativeParam(r *http.Request, name string, def int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, newMsgError(msgInvalidNonNegative, name)
+	}
+	return value, nil
+}
+
+// Сортировка записей по параметру sort: word, -word, created, -created, score, -score
+// ("-" означает обратный порядок). Пустой ключ оставляет порядок добавления.
+func sortEntries(entries []SlangEntry, key string) ([]SlangEntry, error) {
+	desc := strings.HasPrefix(key, "-")
+	field := strings.TrimPrefix(key, "-")
+
+	sorted := make([]SlangEntry, len(entries))
+	copy(sorted, entries)
+
+	switch field {
+	case "":
+		return sorted, nil
+	case "word":
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := normalizeWord(sorted[i].Word), normalizeWord(sorted[j].Word)
+			if desc {
+				return a > b
+			}
+			return a < b
+		})
+	case "score":
+		// По популярности: score — сначала самые высоко оценённые, -score — наоборот
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if desc {
+				return sorted[i].Score < sorted[j].Score
+			}
+			return sorted[i].Score > sorted[j].Score
+		})
+	case "created":
+		// Записи без времени создания (из старых файлов) идут первыми в порядке добавления
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if desc {
+				return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+			}
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		})
+	default:
+		return nil, newMsgError(msgUnknownSortKey, key)
+	}
+	return sorted, nil
+}
+
+// Фильтры по наличию необязательных полей: ?hasExample=false — записи без примера
+// (например, для списка «что доработать»). Несколько фильтров действуют вместе.
+var presenceFilters = []struct {
+	param string
+	has   func(SlangEntry) bool
+}{
+	{"hasExample", func(e SlangEntry) bool { return strings.TrimSpace(e.Example) != "" }},
+	{"hasOrigin", func(e SlangEntry) bool { return strings.TrimSpace(e.Origin) != "" }},
+	{"hasSynonyms", func(e SlangEntry) bool { return len(e.Synonyms) > 0 }},
+	{"hasTags", func(e SlangEntry) bool { return len(e.Tags) > 0 }},
+}
+
+// Записи, прошедшие фильтры presenceFilters из запроса. Значение — только true или false.
+func filterByPresence(entries []SlangEntry, query url.Values) ([]SlangEntry, error) {
+	for _, filter := range presenceFilters {
+		if !query.Has(filter.param) {
+			continue
+		}
+		var want bool
+		switch query.Get(filter.param) {
+		case "true":
+			want = true
+		case "false":
+			want = false
+		default:
+			return nil, newMsgError(msgInvalidBoolParam, filter.param)
+		}
+		has := filter.has
+		entries = filterEntries(entries, func(e SlangEntry) bool { return has(e) == want })
+	}
+	return entries, nil
+}
+
+// Записи, удовлетворяющие условию
+func filterEntries(entries []SlangEntry, keep func(SlangEntry) bool) []SlangEntry {
+	filtered := []SlangEntry{}
+	for _, entry := range entries {
+		if keep(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// GET /api/entries
+// Без параметров возвращает массив всех записей. Если передан limit или offset,
+// возвращает объект {"total", "limit", "offset", "entries"}.
+func handleGetEntries(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		paginated := query.Has("limit") || query.Has("offset")
+
+		limit, err := parseNonNegativeParam(r, "limit", defaultPageLimit)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+		offset, err := parseNonNegativeParam(r, "offset", 0)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+		fields, err := parseFieldsParam(r)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+
+		var since time.Time
+		if raw := query.Get("since"); raw != "" {
+			since, err = time.Parse(time.RFC3339, raw)
+			if err != nil {
+				respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidSince))
+				return
+			}
+		}
+
+		slangData := loadSlangData(s)
+		entries := visibleEntries(r, slangData.Entries)
+		if !since.IsZero() {
+			entries = filterEntries(entries, func(e SlangEntry) bool { return e.UpdatedAt.After(since) })
+		}
+		if tag := query.Get("tag"); tag != "" {
+			entries = filterEntries(entries, func(e SlangEntry) bool { return hasTag(e, tag) })
+		}
+		if author := query.Get("author"); author != "" {
+			author = normalizeUsername(author)
+			entries = filterEntries(entries, func(e SlangEntry) bool { return e.Author == author })
+		}
+		entries, err = filterByPresence(entries, query)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+
+		entries, err = sortEntries(entries, query.Get("sort"))
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+
+		if !paginated {
+			respondJSONWithETag(w, r, selectEntriesFields(entries, fields))
+			return
+		}
+
+		start := min(offset, len(entries))
+		end := min(start+limit, len(entries))
+		respondJSONWithETag(w, r, selectPageFields(entriesPage{
+			Total:   len(entries),
+			Limit:   limit,
+			Offset:  offset,
+			Entries: entries[start:end],
+		}, fields))
+	}
+}
+
+// POST /api/entries
+func handleAddEntry(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var entry SlangEntry
+		if err := readJSON(w, r, &entry); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+		sanitizeEntry(&entry)
+
+		entry.Synonyms = normalizeSynonyms(entry.Synonyms)
+		entry.Tags = normalizeTags(entry.Tags)
+		if err := ValidateEntry(entry); err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeValidationFailed, trErr(r, err))
+			return
+		}
+		if reasons := validateNewEntry(entry); len(reasons) > 0 {
+			respondRejected(w, r, reasons)
+			return
+		}
+
+		username := usernameFromContext(r.Context())
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			// Проверка дубликата в словаре пользователя
+			if ownerWordExists(slangData.Entries, username, entry.Word, -1) {
+				return errWordExists
+			}
+			entry.ID = newEntryID()
+			entry.Owner = username
+			entry.Author = username
+			entry.LastEditedBy = ""
+			entry.Score = 0
+			entry.CreatedAt = time.Now().UTC()
+			entry.UpdatedAt = entry.CreatedAt
+			slangData.Entries = append(slangData.Entries, entry)
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondJSON(w, r, http.StatusCreated, map[string]string{"message": tr(r, msgWordAdded)})
+	}
+}
+
+// Вспомогательная функция для получения номера записи (с 1) из пути /api/entries/{index}
+func parseEntryIndex(r *http.Request) (int, bool) {
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || index < 1 {
+		return 0, false
+	}
+	return index, true
+}
+
+// GET /api/entries/{index}
+func handleGetEntry(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, ok := parseEntryIndex(r)
+		if !ok {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidIndex))
+			return
+		}
+		fields, err := parseFieldsParam(r)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+
+		slangData := loadSlangData(s)
+		visible := visibleIndexes(r, slangData.Entries)
+		if index > len(visible) {
+			respondError(w, r, http.StatusNotFound, errCodeNotFound, tr(r, msgWordNotFound))
+			return
+		}
+
+		entry := slangData.Entries[visible[index-1]]
+		respondEntry(w, r, entry, selectEntryFields(entry, fields))
+	}
+}
+
+// DELETE /api/entries/{index} — запись переносится в корзину, с ?permanent=true удаляется насовсем;
+// с ?dryRun=true только показывает, что было бы удалено
+func handleDeleteEntry(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, ok := parseEntryIndex(r)
+		if !ok {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidIndex))
+			return
+		}
+
+		var deleted []SlangEntry
+		var remaining int
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			own := ownEntries(slangData.Entries, usernameFromContext(r.Context()))
+			if index > len(own) {
+				return errEntryNotFound
+			}
+			deleted = []SlangEntry{slangData.Entries[own[index-1]]}
+			removeEntry(slangData, own[index-1], r.URL.Query().Get("permanent") == "true")
+			remaining = len(own) - 1
+			return dryRunError(r)
+		})
+		respondDeleted(w, r, err, deleted, remaining)
+	}
+}
+
+// GET /api/entry/{id}
+func handleGetEntryByID(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fields, err := parseFieldsParam(r)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+
+		slangData := loadSlangData(s)
+		index := findEntryByID(slangData.Entries, r.PathValue("id"))
+		if index < 0 || !canSee(slangData.Entries[index], usernameFromContext(r.Context())) {
+			respondError(w, r, http.StatusNotFound, errCodeNotFound, tr(r, msgWordNotFound))
+			return
+		}
+		respondEntry(w, r, slangData.Entries[index], selectEntryFields(slangData.Entries[index], fields))
+	}
+}
+
+// DELETE /api/entry/{id} (как и удаление по номеру, понимает permanent и dryRun)
+func handleDeleteEntryByID(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := usernameFromContext(r.Context())
+		var deleted []SlangEntry
+		var remaining int
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			index := findEntryByID(slangData.Entries, r.PathValue("id"))
+			// Чужие записи удалить нельзя; для владельца они выглядят как несуществующие
+			if index < 0 || slangData.Entries[index].Owner != username {
+				return errEntryNotFound
+			}
+			deleted = []SlangEntry{slangData.Entries[index]}
+			removeEntry(slangData, index, r.URL.Query().Get("permanent") == "true")
+			remaining = len(ownEntries(slangData.Entries, username))
+			return dryRunError(r)
+		})
+		respondDeleted(w, r, err, deleted, remaining)
+	}
+}
+
+// DELETE /api/entries/by-word/{word} (понимает permanent и dryRun)
+// В отличие от номера, слово не меняется, когда другие записи добавляются или удаляются
+func handleDeleteByWord(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		word := r.PathValue("word")
+		username := usernameFromContext(r.Context())
+
+		var deleted []SlangEntry
+		var remaining int
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			index := findOwnEntryIndex(slangData.Entries, username, word)
+			if index < 0 {
+				return errEntryNotFound
+			}
+			deleted = []SlangEntry{slangData.Entries[index]}
+			removeEntry(slangData, index, r.URL.Query().Get("permanent") == "true")
+			remaining = len(ownEntries(slangData.Entries, username))
+			return dryRunError(r)
+		})
+		respondDeleted(w, r, err, deleted, remaining)
+	}
+}
+
+// Замена записи i изменённой версией (общая для PUT, PATCH и консоли). Если содержимое
+// поменялось, прежняя версия уходит в историю, а время изменения и редактор обновляются.
+func updateEntry(slangData *SlangData, i int, entry SlangEntry, editor string) SlangEntry {
+	entry.CanonicalWord = normalizeWord(entry.Word)
+	if !sameEntryContent(slangData.Entries[i], entry) {
+		recordHistory(slangData, slangData.Entries[i])
+		entry.UpdatedAt = time.Now().UTC()
+		entry.LastEditedBy = editor
+	}
+	slangData.Entries[i] = entry
+	return entry
+}
+
+// PUT /api/entries/{index}. С заголовком If-Match (ETag из GET) запись меняется,
+// только если её не изменили с тех пор, иначе 412; то же для PATCH.
+func handleUpdateEntry(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, ok := parseEntryIndex(r)
+		if !ok {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidIndex))
+			return
+		}
+
+		var entry SlangEntry
+		if err := readJSON(w, r, &entry); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+		sanitizeEntry(&entry)
+
+		entry.Synonyms = normalizeSynonyms(entry.Synonyms)
+		entry.Tags = normalizeTags(entry.Tags)
+		if err := ValidateEntry(entry); err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeValidationFailed, trErr(r, err))
+			return
+		}
+
+		username := usernameFromContext(r.Context())
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			own := ownEntries(slangData.Entries, username)
+			if index > len(own) {
+				return errEntryNotFound
+			}
+			i := own[index-1]
+			if err := checkIfMatch(r, slangData.Entries[i]); err != nil {
+				return err
+			}
+
+			// Нельзя переименовать слово в уже существующее (кроме самого себя)
+			if ownerWordExists(slangData.Entries, username, entry.Word, i) {
+				return errWordExists
+			}
+
+			// ID, владелец, автор и время создания при замене записи не меняются
+			old := slangData.Entries[i]
+			entry.ID = old.ID
+			entry.Owner = old.Owner
+			entry.Author = old.Author
+			entry.LastEditedBy = old.LastEditedBy
+			entry.Score = old.Score
+			entry.CreatedAt = old.CreatedAt
+			entry.UpdatedAt = old.UpdatedAt
+			entry = updateEntry(slangData, i, entry, username)
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondEntry(w, r, entry, entry)
+	}
+}
+
+// Частичное изменение записи: nil означает, что поле не передано и остаётся прежним
+type SlangEntryPatch struct {
+	Word     *string   `json:"word"`
+	Meaning  *string   `json:"meaning"`
+	Example  *string   `json:"example"`
+	Origin   *string   `json:"origin"`
+	Synonyms *[]string `json:"synonyms"`
+	Tags     *[]string `json:"tags"`
+	Private  *bool     `json:"private"`
+}
+
+// PATCH /api/entries/{index}
+func handlePatchEntry(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, ok := parseEntryIndex(r)
+		if !ok {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidIndex))
+			return
+		}
+
+		var patch SlangEntryPatch
+		if err := readJSON(w, r, &patch); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+		sanitizePatch(&patch)
+
+		username := usernameFromContext(r.Context())
+		var entry SlangEntry
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			own := ownEntries(slangData.Entries, username)
+			if index > len(own) {
+				return errEntryNotFound
+			}
+			i := own[index-1]
+			if err := checkIfMatch(r, slangData.Entries[i]); err != nil {
+				return err
+			}
+
+			if patch.Word != nil && ownerWordExists(slangData.Entries, username, *patch.Word, i) {
+				return errWordExists
+			}
+
+			entry = slangData.Entries[i]
+			if patch.Word != nil {
+				entry.Word = *patch.Word
+			}
+			if patch.Meaning != nil {
+				entry.Meaning = *patch.Meaning
+			}
+			if patch.Example != nil {
+				entry.Example = *patch.Example
+			}
+			if patch.Origin != nil {
+				entry.Origin = *patch.Origin
+			}
+			if patch.Synonyms != nil {
+				entry.Synonyms = normalizeSynonyms(*patch.Synonyms)
+			}
+			if patch.Tags != nil {
+				entry.Tags = normalizeTags(*patch.Tags)
+			}
+			if patch.Private != nil {
+				entry.Private = *patch.Private
+			}
+			// Проверяется итоговая запись: в том числе нельзя очистить обязательные поля
+			if err := ValidateEntry(entry); err != nil {
+				return err
+			}
+			entry = updateEntry(slangData, i, entry, username)
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondEntry(w, r, entry, entry)
+	}
+}
+
+// GET /api/user — данные текущего пользователя по токену
+func handleGetUser(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := s.GetUser(usernameFromContext(r.Context()))
+		if err != nil {
+			respondError(w, r, http.StatusUnauthorized, errCodeUnauthorized, tr(r, msgUserNotRegistered))
+			return
+		}
+		// Не возвращаем пароль!
+		respondJSON(w, r, http.StatusOK, map[string]interface{}{"username": user.Username, "is_admin": user.IsAdmin})
+	}
+}
+
+// POST /api/user/password — смена пароля, нужен текущий пароль. Прежние токены,
+// включая текущий, после смены не действуют, поэтому в ответе — новый токен.
+func handleChangePassword(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		type Req struct {
+			CurrentPassword string `json:"current_password"`
+			NewPassword     string `json:"new_password"`
+		}
+		var req Req
+		if err := readJSON(w, r, &req); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+
+		user, err := s.GetUser(usernameFromContext(r.Context()))
+		if err != nil {
+			respondError(w, r, http.StatusUnauthorized, errCodeUnauthorized, tr(r, msgUserNotRegistered))
+			return
+		}
+		var invalid *ValidationError
+		switch err := changePassword(&user, req.CurrentPassword, req.NewPassword); {
+		case err == nil:
+		case err == errWrongPassword:
+			respondError(w, r, http.StatusUnauthorized, errCodeInvalidCredentials, trErr(r, err))
+			return
+		case errors.As(err, &invalid):
+			respondError(w, r, http.StatusBadRequest, errCodeValidationFailed, trErr(r, err))
+			return
+		default:
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgPasswordSaveFailed))
+			return
+		}
+
+		if err := s.SetUser(user); err != nil {
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgSaveFailed))
+			return
+		}
+		token, expires, err := issueToken(user.Username, user.IsAdmin)
+		if err != nil {
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgTokenIssueFailed))
+			return
+		}
+		respondJSON(w, r, http.StatusOK, map[string]string{
+			"message":    tr(r, msgPasswordChanged),
+			"token":      token,
+			"expires_at": expires.UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// DELETE /api/user — удаление аккаунта с подтверждением паролем;
+// ?delete_entries=true удаляет и словарь пользователя
+func handleDeleteUser(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		type Req struct {
+			Password string `json:"password"`
+		}
+		var req Req
+		if err := readJSON(w, r, &req); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+
+		user, err := s.GetUser(usernameFromContext(r.Context()))
+		if err != nil {
+			respondError(w, r, http.StatusNotFound, errCodeNotFound, tr(r, msgUserNotRegistered))
+			return
+		}
+		if !checkPassword(user, req.Password) {
+			respondError(w, r, http.StatusUnauthorized, errCodeInvalidCredentials, tr(r, msgWrongPassword))
+			return
+		}
+
+		var deleted int
+		err = modifySlangData(s, func(slangData *SlangData) error {
+			// Аккаунт могли удалить параллельным запросом, пока проверялся пароль
+			if findUser(slangData, user.Username) == nil {
+				return errUserNotFound
+			}
+			deleted = deleteUser(slangData, user.Username, r.URL.Query().Get("delete_entries") == "true")
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		// Токен удалённого пользователя больше не нужен
+		if claims, err := parseToken(bearerToken(r)); err == nil {
+			revokedTokens.revoke(claims.ID, time.Unix(claims.ExpiresAt, 0))
+		}
+		respondJSON(w, r, http.StatusOK, map[string]interface{}{
+			"message":         tr(r, msgAccountDeleted),
+			"deleted_entries": deleted,
+		})
+	}
+}
+
+// POST /api/register
+func handleRegister(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		type Req struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		var req Req
+		if err := readJSON(w, r, &req); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+
+		username := normalizeUsername(req.Username)
+		if err := ValidateUser(User{Username: username, Password: req.Password}); err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeValidationFailed, trErr(r, err))
+			return
+		}
+
+		// Хеш считается до блокировки хранилища: bcrypt медленный
+		hash, err := hashPassword(req.Password)
+		if err != nil {
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgPasswordSaveFailed))
+			return
+		}
+
+		err = modifySlangData(s, func(slangData *SlangData) error {
+			if findUser(slangData, username) != nil {
+				return errUserExists
+			}
+			// Первый зарегистрированный пользователь становится администратором
+			slangData.Users = append(slangData.Users, User{Username: username, Password: hash, IsAdmin: len(slangData.Users) == 0, CreatedAt: time.Now().UTC()})
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondJSON(w, r, http.StatusCreated, map[string]string{"message": tr(r, msgRegistered)})
+	}
+}
+
+// POST /api/login
+func handleLogin(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		type Req struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		var req Req
+		if err := readJSON(w, r, &req); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+
+		slangData := loadSlangData(s)
+		if len(slangData.Users) == 0 {
+			metrics.observeLogin(false)
+			respondError(w, r, http.StatusUnauthorized, errCodeUnauthorized, tr(r, msgRegisterFirst))
+			return
+		}
+
+		// Заблокированный аккаунт не принимает даже верный пароль
+		if left := accountLockout.lockedFor(req.Username); left > 0 {
+			metrics.observeLogin(false)
+			minutes := int(math.Ceil(left.Minutes()))
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(left.Seconds()))))
+			respondError(w, r, http.StatusLocked, errCodeAccountLocked, tr(r, msgAccountLocked, minutes))
+			return
+		}
+
+		user := findUser(&slangData, req.Username)
+		if user != nil && checkPassword(*user, req.Password) {
+			accountLockout.succeed(req.Username)
+			upgradeLegacyPassword(s, user, req.Password)
+			token, expires, err := issueToken(user.Username, user.IsAdmin)
+			if err != nil {
+				respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgTokenIssueFailed))
+				return
+			}
+			metrics.observeLogin(true)
+			respondJSON(w, r, http.StatusOK, map[string]string{
+				"message":    tr(r, msgLoggedIn),
+				"username":   user.Username,
+				"token":      token,
+				"expires_at": expires.UTC().Format(time.RFC3339),
+			})
+		} else {
+			metrics.observeLogin(false)
+			accountLockout.fail(req.Username)
+			respondError(w, r, http.StatusUnauthorized, errCodeInvalidCredentials, tr(r, msgInvalidLogin))
+		}
+	}
+}
+
+// GET /api/health — проверка для балансировщика, без авторизации
+func handleHealth(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Читаем хранилище напрямую, чтобы увидеть ошибку, а не пустой словарь
+		slangData, err := s.Load()
+		if err != nil {
+			respondJSON(w, r, http.StatusServiceUnavailable, map[string]string{
+				"status": "unavailable",
+				"error":  err.Error(),
+			})
+			return
+		}
+		respondJSON(w, r, http.StatusOK, map[string]interface{}{
+			"status":  "ok",
+			"entries": len(slangData.Entries),
+			"version": slangData.Version,
+		})
+	}
+}
+
+// ————————————————————————
+//         Запуск API сервера
+// ————————————————————————
+
+// Роутер со всеми маршрутами API. Не использует http.DefaultServeMux, а хранилище
+// получает параметром (обработчики берут его из замыкания), поэтому в тестах
+// можно создать роутер над отдельным файлом через httptest.NewServer.
+func newRouter(s Store) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// Открытые маршруты чтения: без токена показывают общий словарь, с токеном — словарь
+	// пользователя (общий — с ?shared=true)
+	mux.HandleFunc("GET /api/entries", optionalAuth(s, handleGetEntries(s)))
+	mux.HandleFunc("POST /api/entries", requireAuth(s, entryIdempotency.wrap(handleAddEntry(s))))
+	mux.HandleFunc("POST /api/entries/import", requireAuth(s, handleImport(s)))
+	mux.HandleFunc("POST /api/entries/delete", requireAuth(s, handleBulkDelete(s)))
+	mux.HandleFunc("GET /api/entries/export", optionalAuth(s, handleExport(s)))
+	mux.HandleFunc("GET /api/entries/random", optionalAuth(s, handleRandomEntry(s)))
+	mux.HandleFunc("GET /api/entries/batch", optionalAuth(s, handleEntryBatch(s)))
+	mux.HandleFunc("GET /api/entries/duplicates", requireAuth(s, handleDuplicates(s)))
+
+	// Операции с записью по номеру (изменения — только с токеном)
+	mux.HandleFunc("GET /api/entries/{index}", optionalAuth(s, handleGetEntry(s)))
+	mux.HandleFunc("PUT /api/entries/{index}", requireAuth(s, handleUpdateEntry(s)))
+	mux.HandleFunc("PATCH /api/entries/{index}", requireAuth(s, handlePatchEntry(s)))
+	mux.HandleFunc("DELETE /api/entries/{index}", requireAuth(s, handleDeleteEntry(s)))
+	mux.HandleFunc("POST /api/entries/{index}/vote", requireAuth(s, handleVote(s)))
+	mux.HandleFunc("GET /api/entries/{index}/history", optionalAuth(s, handleHistory(s)))
+	mux.HandleFunc("POST /api/entries/{index}/revert", requireAuth(s, handleRevert(s)))
+	mux.HandleFunc("DELETE /api/entries/by-word/{word}", requireAuth(s, handleDeleteByWord(s)))
+	mux.HandleFunc("GET /api/entries/by-word/{word}/synonyms", optionalAuth(s, handleGetSynonyms(s)))
+	mux.HandleFunc("GET /api/entries/by-word/{word}/related", optionalAuth(s, handleRelated(s)))
+	mux.HandleFunc("GET /api/entries/synonyms/check", optionalAuth(s, handleCheckSynonyms(s)))
+	mux.HandleFunc("POST /api/entries/synonyms/check", requireAuth(s, handleCheckSynonyms(s)))
+
+	// Операции с записью по постоянному ID. Не под /api/entries/: любой путь
+	// /api/entries/<что-то>/{id} пересекался бы с GET /api/entries/{index}/history.
+	mux.HandleFunc("GET /api/entry/{id}", optionalAuth(s, handleGetEntryByID(s)))
+	mux.HandleFunc("DELETE /api/entry/{id}", requireAuth(s, handleDeleteEntryByID(s)))
+
+	// Корзина пользователя
+	mux.HandleFunc("GET /api/trash", requireAuth(s, handleGetTrash(s)))
+	mux.HandleFunc("POST /api/trash/{id}/restore", requireAuth(s, handleRestoreTrash(s)))
+	mux.HandleFunc("DELETE /api/trash/{id}", requireAuth(s, handleDeleteTrash(s)))
+
+	// Резервные копии JSON-файла — только администратору: восстановление откатывает
+	// словари и аккаунты всех пользователей
+	mux.HandleFunc("POST /api/backup", requireAdmin(s, handleBackup(s)))
+	mux.HandleFunc("GET /api/backups", requireAdmin(s, handleListBackups(s)))
+	mux.HandleFunc("POST /api/restore", requireAdmin(s, handleRestore(s)))
+
+	mux.HandleFunc("GET /api/search", optionalAuth(s, handleSearch(s)))
+	mux.HandleFunc("GET /api/autocomplete", optionalAuth(s, handleAutocomplete(s)))
+	mux.HandleFunc("GET /api/word-of-day", handleWordOfDay(s))
+	mux.HandleFunc("GET /api/stats", optionalAuth(s, handleStats(s)))
+	mux.HandleFunc("GET /api/tags", optionalAuth(s, handleTags(s)))
+	mux.HandleFunc("GET /api/index", optionalAuth(s, handleIndex(s)))
+	mux.HandleFunc("GET /api/health", handleHealth(s))
+	mux.HandleFunc("GET /metrics", handleMetrics(s))
+	mux.HandleFunc("GET /api/user", requireAuth(s, handleGetUser(s)))
+	mux.HandleFunc("POST /api/user/password", requireAuth(s, handleChangePassword(s)))
+	mux.HandleFunc("POST /api/user/reset-request", resetLimiter.wrap(handleResetRequest(s)))
+	mux.HandleFunc("POST /api/user/reset", handleResetPassword(s))
+	mux.HandleFunc("DELETE /api/user", requireAuth(s, handleDeleteUser(s)))
+	mux.HandleFunc("GET /api/admin/users", requireAdmin(s, handleAdminUsers(s)))
+	mux.HandleFunc("DELETE /api/admin/entries/{id}", requireAdmin(s, handleAdminDeleteEntry(s)))
+	mux.HandleFunc("POST /api/register", handleRegister(s))
+	mux.HandleFunc("POST /api/login", loginLimiter.wrap(handleLogin(s)))
+	mux.HandleFunc("POST /api/logout", requireAuth(s, handleLogout))
+	mux.HandleFunc("GET /api/ws", handleWebSocket(s))
+	mux.HandleFunc("GET /api/events", handleEvents(s))
+	mux.HandleFunc("GET /api/openapi.json", handleOpenAPI())
+	mux.HandleFunc("GET /api/docs", handleDocs)
+
+	return mux
+}
+
+func startAPIServer(cfg config, s Store) {
+	mux := newRouter(s)
+	apiServer = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           logRequests(mux, withCORS(mux)),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	fmt.Println(t(msgAPIStarting), cfg.apiURL())
+	go func() {
+		if err := apiServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Println(t(msgServerStartFailed, err))
+		}
+	}()
+}
+
+// Сколько ждём завершения активных запросов при остановке
+const shutdownTimeout = 5 * time.Second
+
+var (
+	apiServer    *http.Server
+	shutdownOnce sync.Once
+)
+
+// Корректная остановка: дожидаемся активных запросов, сбрасываем данные
+// в хранилище и закрываем его. Повторные вызовы ничего не делают.
+func shutdownAPIServer(s Store) {
+	shutdownOnce.Do(func() {
+		if apiServer != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if err := apiServer.Shutdown(ctx); err != nil {
+				logger.Error("ошибка остановки сервера", "error", err)
+			}
+		}
+
+		// Финальное сохранение: читаем хранилище напрямую, чтобы при ошибке
+		// чтения не перезаписать данные пустым словарём
+		if slangData, err := s.Load(); err == nil {
+			if err := saveSlangData(s, slangData); err != nil {
+				logger.Error("ошибка сохранения данных", "error", err)
+			}
+		}
+		if closer, ok := s.(io.Closer); ok {
+			closer.Close()
+		}
+	})
+}
+
+// Остановка по SIGINT/SIGTERM (Ctrl+C, docker stop)
+func handleSignals(s Store) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		fmt.Println(t(msgStoppingServer))
+		shutdownAPIServer(s)
+		os.Exit(0)
+	}()
+}
+
+// ————————————————————————
+//         Основная программа
+// ————————————————————————
+
+func main() {
+	cfg, err := loadConfig(os.Args[1:])
+	if err != nil {
+		os.Exit(2)
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, t(msgStoreOpenFailed), err)
+		os.Exit(1)
+	}
+	// Повреждённый файл восстанавливается при первом чтении; в строгом режиме сервер не запускается
+	if _, err := store.Load(); errors.Is(err, errCorruptData) {
+		fmt.Fprintln(os.Stderr, t(msgStoreOpenFailed), err)
+		os.Exit(1)
+	}
+	entryValidators, err = loadEntryValidators(cfg.Validators, cfg.BannedWordsFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		os.Exit(1)
+	}
+	webhooks, err = newWebhookDispatcher(cfg.Webhooks, cfg.WebhookSecret)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		os.Exit(1)
+	}
+	purgeTrashOnStartup(store, cfg.TrashDays)
+	jsonOutput = cfg.JSON
+
+	// С подкомандой — без меню и без API, вывод пригоден для скриптов
+	if len(cfg.Command) > 0 {
+		code := runCommand(store, cfg.Command, os.Stdout, os.Stderr)
+		if closer, ok := store.(io.Closer); ok {
+			closer.Close()
+		}
+		os.Exit(code)
+	}
+
+	fmt.Println(t(msgAppTitle))
+	fmt.Println("---------------------------")
+	startAPIServer(cfg, store)
+	handleSignals(store)
+	// Любой выход из меню корректно останавливает сервер
+	defer shutdownAPIServer(store)
+
+	for {
+		fmt.Println(t(msgMainMenu))
+		fmt.Println(t(msgMenuRegister))
+		fmt.Println(t(msgMenuLogin))
+		fmt.Println(t(msgMenuQuit))
+		fmt.Print(t(msgChooseAction))
+
+		choice, err := readInput()
+		if err != nil {
+			// Ввод закончился (Ctrl-D или конец переданного файла): выходим, а не крутим меню
+			fmt.Println("")
+			fmt.Println(t(msgGoodbye))
+			return
+		}
+
+		switch choice {
+		case "":
+			// Пустая строка — показать меню ещё раз, без сообщения об ошибке
+		case "1":
+			if register(store) {
+				fmt.Println(t(msgRegisteredCLI))
+			}
+		case "2":
+			if username := login(store); username != "" {
+				runDictionaryApp(store, username)
+				return
+			}
+		case "3":
+			fmt.Println(t(msgGoodbye))
+			return
+		default:
+			fmt.Println(t(msgInvalidChoice))
+		}
+	}
+}
+
+// Строка ввода без перевода строки. io.EOF — только когда ввод закончился
+// (Ctrl-D, конец переданного файла) и читать нечего; последняя строка без \n
+// возвращается как обычно. Прерванное так действие отменяется, а меню на
+// следующем чтении получает тот же EOF и завершает программу.
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Весь ввод консоли идёт через один буферизованный reader. Отдельный bufio.Reader
+// на каждый вопрос (или fmt.Scanln рядом с ним) забирал бы строки, набранные
+// для следующих вопросов, а Scanln к тому же читает одно слово и оставляет
+// остаток строки следующему вопросу.
+var stdin = bufio.NewReader(os.Stdin)
+
+// Ответ на вопрос консоли: вся строка без пробелов по краям, пустая строка —
+// пустой ответ. io.EOF — ввод закончился.
+func readInput() (string, error) {
+	line, err := readLine(stdin)
+	return strings.TrimSpace(line), err
+}
+
+// Номер в ответ на вопрос. Пустая строка, лишние слова или не число — ошибка
+// разбора, конец ввода — io.EOF.
+func readNumber() (int, error) {
+	line, err := readInput()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(line)
+}
+
+// Сообщение об отменённом вводе: с новой строки, потому что Ctrl-D не переводит её
+func printInputCancelled() {
+	fmt.Println("")
+	fmt.Println(t(msgInputCancelled))
+}
+
+func register(s Store) bool {
+	slangData := loadSlangData(s)
+	fmt.Print(t(msgPromptNewUsername))
+	username, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return false
+	}
+	username = normalizeUsername(username)
+	// Логин проверяем сразу, чтобы не спрашивать пароль зря
+	if err := validateUsername(username); err != nil {
+		fmt.Println(tErr(err))
+		return false
+	}
+	if findUser(&slangData, username) != nil {
+		fmt.Println(t(msgUsernameTaken))
+		return false
+	}
+	fmt.Print(t(msgPromptNewPassword))
+	password, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return false
+	}
+	if err := ValidateUser(User{Username: username, Password: password}); err != nil {
+		fmt.Println(tErr(err))
+		return false
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		fmt.Println(t(msgPasswordSaveFailedErr), err)
+		return false
+	}
+	err = modifySlangData(s, func(data *SlangData) error {
+		// Пока вводился пароль, логин могли занять через API или другую консоль
+		if findUser(data, username) != nil {
+			return errUserExists
+		}
+		data.Users = append(data.Users, User{Username: username, Password: hash, IsAdmin: len(data.Users) == 0, CreatedAt: time.Now().UTC()})
+		return nil
+	})
+	if errors.Is(err, errUserExists) {
+		fmt.Println(t(msgUsernameTaken))
+		return false
+	}
+	if err != nil {
+		fmt.Println(t(msgUserSaveFailed), err)
+		return false
+	}
+	fmt.Println(t(msgUserRegistered, username))
+	return true
+}
+
+// Вход в консоли; возвращает имя пользователя или пустую строку
+func login(s Store) string {
+	slangData := loadSlangData(s)
+	if len(slangData.Users) == 0 {
+		fmt.Println(t(msgMustRegister))
+		return ""
+	}
+	for attempts := 3; attempts > 0; attempts-- {
+		fmt.Print(t(msgPromptUsername))
+		username, err := readInput()
+		if err != nil {
+			printInputCancelled()
+			return ""
+		}
+		fmt.Print(t(msgPromptPassword))
+		password, err := readInput()
+		if err != nil {
+			printInputCancelled()
+			return ""
+		}
+		user := findUser(&slangData, username)
+		if user != nil && checkPassword(*user, password) {
+			upgradeLegacyPassword(s, user, password)
+			fmt.Println(t(msgWelcome, user.Username))
+			fmt.Println(t(msgWordsLoaded, len(ownEntries(slangData.Entries, user.Username))))
+			return user.Username
+		}
+		if attempts > 1 {
+			fmt.Println(t(msgLoginAttemptsLeft, attempts-1))
+		} else {
+			fmt.Println(t(msgLoginFailedCLI))
+		}
+	}
+	return ""
+}
+
+// Консольный словарь пользователя: показывает и меняет только его слова
+func runDictionaryApp(s Store, username string) {
+	for {
+		// Словарь перечитывается перед каждым действием: его могли изменить через
+		// API или другую консоль. Сами изменения идут через modifySlangData.
+		slangData := loadSlangData(s)
+		fmt.Println("")
+		fmt.Println(t(msgWhatToDo))
+		fmt.Println(t(msgMenuList))
+		fmt.Println(t(msgMenuSearch))
+		fmt.Println(t(msgMenuAdd))
+		fmt.Println(t(msgMenuEdit))
+		fmt.Println(t(msgMenuDelete))
+		fmt.Println(t(msgMenuChangePassword))
+		fmt.Println(t(msgMenuDeleteAccount))
+		fmt.Println(t(msgMenuExit))
+		fmt.Print(t(msgYourChoice))
+
+		choice, err := readInput()
+		if err != nil {
+			fmt.Println("")
+			fmt.Println(t(msgGoodbye))
+			return
+		}
+		// После номера пункта можно сразу написать аргумент: «2 кринж»
+		choice, arg, _ := strings.Cut(choice, " ")
+
+		switch choice {
+		case "":
+		case "1":
+			showAllEntries(pickEntries(slangData.Entries, ownEntries(slangData.Entries, username)))
+		case "2":
+			searchEntriesCLI(s, &slangData, username, strings.TrimSpace(arg))
+		case "3":
+			addNewEntry(s, &slangData, username)
+		case "4":
+			editEntry(s, &slangData, username)
+		case "5":
+			deleteEntry(s, &slangData, username)
+		case "6":
+			changePasswordCLI(s, &slangData, username)
+		case "7":
+			if deleteAccountCLI(s, &slangData, username) {
+				return
+			}
+		case "8":
+			fmt.Println(t(msgGoodbye))
+			return
+		default:
+			fmt.Println(t(msgNoSuchOption))
+		}
+	}
+}
+
+// Сколько записей консоль показывает за раз
+const cliPageSize = 10
+
+// Запись в консольном списке и её номер — тот, что вводится при изменении и удалении
+type numberedEntry struct {
+	number int
+	entry  SlangEntry
+}
+
+// Найденные записи с номерами, как в полном списке own, чтобы по ним можно было
+// изменить или удалить слово
+func numberEntries(own, found []SlangEntry) []numberedEntry {
+	numbers := make(map[string]int, len(own))
+	for i, entry := range own {
+		numbers[entry.ID] = i + 1
+	}
+	numbered := make([]numberedEntry, len(found))
+	for i, entry := range found {
+		numbered[i] = numberedEntry{number: numbers[entry.ID], entry: entry}
+	}
+	return numbered
+}
+
+func showAllEntries(entries []SlangEntry) {
+	numbered := make([]numberedEntry, len(entries))
+	for i, entry := range entries {
+		numbered[i] = numberedEntry{number: i + 1, entry: entry}
+	}
+	showEntryPages(numbered)
+}
+
+// Список по cliPageSize записей: Enter — следующая страница, b — предыдущая,
+// q — закончить просмотр. Короткий список выводится целиком, без вопросов,
+// а с -json — весь список сразу одним массивом.
+func showEntryPages(entries []numberedEntry) {
+	if jsonOutput {
+		printEntries(os.Stdout, entries)
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println(t(msgDictionaryEmptyCLI))
+		return
+	}
+	fmt.Println(t(msgTotalWords, len(entries)))
+	fmt.Println("==========================================")
+	pages := (len(entries) + cliPageSize - 1) / cliPageSize
+	for page := 0; ; {
+		for _, e := range entries[page*cliPageSize : min((page+1)*cliPageSize, len(entries))] {
+			showEntry(e.number, e.entry)
+		}
+		if pages == 1 {
+			return
+		}
+		fmt.Print(t(msgPagePrompt, page+1, pages))
+		answer, err := readInput()
+		if err != nil {
+			fmt.Println("")
+			return
+		}
+		switch strings.ToLower(answer) {
+		case "q":
+			return
+		case "b":
+			page = max(page-1, 0)
+		default:
+			if page == pages-1 {
+				return
+			}
+			page++
+		}
+	}
+}
+
+func showEntry(number int, entry SlangEntry) {
+	fmt.Println(t(msgEntryWord, number, entry.Word))
+	fmt.Println(t(msgEntryMeaning, entry.Meaning))
+	fmt.Println(t(msgEntryExample, entry.Example))
+	if entry.Origin != "" {
+		fmt.Println(t(msgEntryOrigin, entry.Origin))
+	}
+	if len(entry.Synonyms) > 0 {
+		fmt.Println(t(msgEntrySynonyms, strings.Join(entry.Synonyms, ", ")))
+	}
+	if len(entry.Tags) > 0 {
+		fmt.Println(t(msgEntryTags, strings.Join(entry.Tags, ", ")))
+	}
+	fmt.Println("------------------------------------------")
+}
+
+// Поиск по своим записям — та же подстрока без учёта регистра, что и в GET /api/search.
+// Найденное слово можно сразу открыть, изменить или удалить, не ища его номер в полном списке.
+// Запрос без аргумента спрашивается; с -json вопросов нет: запрос берётся только из
+// аргумента, а вывод — один массив найденного.
+func searchEntriesCLI(s Store, slangData *SlangData, username, query string) {
+	if query == "" && jsonOutput {
+		commandError(os.Stderr, t(msgSearchArgRequired))
+		return
+	}
+	if query == "" {
+		fmt.Print(t(msgPromptSearchQuery))
+		var err error
+		if query, err = readInput(); err != nil {
+			printInputCancelled()
+			return
+		}
+		if query == "" {
+			return
+		}
+	}
+	own := ownEntries(slangData.Entries, username)
+	found := searchEntries(pickEntries(slangData.Entries, own), query, defaultSearchFields)
+	if len(found) == 0 && !jsonOutput {
+		fmt.Println(t(msgNothingFoundCLI, query))
+		return
+	}
+	numbered := numberEntries(pickEntries(slangData.Entries, own), found)
+	showEntryPages(numbered)
+	if jsonOutput {
+		return
+	}
+
+	fmt.Print(t(msgPromptOpenFound))
+	line, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return
+	}
+	if line == "" {
+		return
+	}
+	index, err := strconv.Atoi(line)
+	if err != nil || !slices.ContainsFunc(numbered, func(e numberedEntry) bool { return e.number == index }) {
+		fmt.Println(t(msgNoSuchNumber))
+		return
+	}
+	i := own[index-1]
+	fmt.Println("")
+	showEntry(index, slangData.Entries[i])
+	fmt.Print(t(msgPromptEntryAction))
+	action, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return
+	}
+	switch action {
+	case "1":
+		editEntryAt(s, slangData, username, i)
+	case "2":
+		deleteEntryAt(s, slangData, i)
+	}
+}
+
+func addNewEntry(s Store, slangData *SlangData, username string) {
+	var entry SlangEntry
+	fmt.Println(t(msgAddingWord))
+	fmt.Print(t(msgPromptWord))
+	word, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return
+	}
+	entry.Word = sanitizeText(word)
+	if ownerWordExists(slangData.Entries, username, entry.Word, -1) {
+		fmt.Println(t(msgWordAlreadyInDictionary, entry.Word))
+		return
+	}
+	// Незаконченная запись не сохраняется: ввод оборвался — добавление отменено
+	var synonyms, tags string
+	for _, field := range []struct {
+		prompt msgID
+		value  *string
+	}{
+		{msgPromptMeaning, &entry.Meaning},
+		{msgPromptExample, &entry.Example},
+		{msgPromptOrigin, &entry.Origin},
+		{msgPromptSynonyms, &synonyms},
+		{msgPromptTags, &tags},
+	} {
+		fmt.Print(t(field.prompt))
+		value, err := readInput()
+		if err != nil {
+			printInputCancelled()
+			return
+		}
+		*field.value = sanitizeText(value)
+	}
+	entry.Synonyms = normalizeSynonyms(sanitizeStrings(strings.Split(synonyms, ",")))
+	entry.Tags = normalizeTags(sanitizeStrings(strings.Split(tags, ",")))
+	if err := ValidateEntry(entry); err != nil {
+		fmt.Println(t(msgWordNotAdded), tErr(err))
+		return
+	}
+	entry.ID = newEntryID()
+	entry.Owner = username
+	entry.Author = username
+	entry.CreatedAt = time.Now().UTC()
+	entry.UpdatedAt = entry.CreatedAt
+	err = modifySlangData(s, func(data *SlangData) error {
+		// Пока вводились поля, слово могли добавить через API или другую консоль
+		if ownerWordExists(data.Entries, username, entry.Word, -1) {
+			return errWordExists
+		}
+		data.Entries = append(data.Entries, entry)
+		return nil
+	})
+	if errors.Is(err, errWordExists) {
+		fmt.Println(t(msgWordAlreadyInDictionary, entry.Word))
+		return
+	}
+	if err != nil {
+		fmt.Println(t(msgWordSaveFailed), err)
+		return
+	}
+	fmt.Println(t(msgWordAddedCLI, entry.Word))
+}
+
+// Новое значение поля при редактировании в консоли: пустой ввод оставляет
+// текущее значение, «-» очищает поле. io.EOF — ввод закончился.
+func readEditedField(prompt msgID, current string) (string, error) {
+	if current != "" {
+		fmt.Println(t(msgCurrentValue, current))
+	}
+	fmt.Print(t(prompt))
+	value, err := readInput()
+	if err != nil {
+		return "", err
+	}
+	switch value = sanitizeText(value); value {
+	case "":
+		return current, nil
+	case "-":
+		return "", nil
+	}
+	return value, nil
+}
+
+func editEntry(s Store, slangData *SlangData, username string) {
+	own := ownEntries(slangData.Entries, username)
+	if len(own) == 0 {
+		fmt.Println(t(msgNothingToEdit))
+		return
+	}
+	showAllEntries(pickEntries(slangData.Entries, own))
+	fmt.Print(t(msgPromptEditIndex))
+	index, err := readNumber()
+	if errors.Is(err, io.EOF) {
+		printInputCancelled()
+		return
+	}
+	if err != nil || index < 1 || index > len(own) {
+		fmt.Println(t(msgNoSuchNumber))
+		return
+	}
+	editEntryAt(s, slangData, username, own[index-1])
+}
+
+// Изменение записи i: каждое поле можно ввести заново или оставить прежним.
+// Запись ищется заново по ID при сохранении: номер i относится к снимку словаря.
+func editEntryAt(s Store, slangData *SlangData, username string, i int) {
+	entry := slangData.Entries[i]
+	original := entry.Word
+	fmt.Println(t(msgEditingWord, entry.Word))
+	synonyms, tags := strings.Join(entry.Synonyms, ", "), strings.Join(entry.Tags, ", ")
+	for _, field := range []struct {
+		prompt msgID
+		value  *string
+	}{
+		{msgPromptWord, &entry.Word},
+		{msgPromptMeaning, &entry.Meaning},
+		{msgPromptExample, &entry.Example},
+		{msgPromptOrigin, &entry.Origin},
+		{msgPromptSynonyms, &synonyms},
+		{msgPromptTags, &tags},
+	} {
+		value, err := readEditedField(field.prompt, *field.value)
+		if err != nil {
+			printInputCancelled()
+			return
+		}
+		*field.value = value
+	}
+	entry.Synonyms = normalizeSynonyms(sanitizeStrings(strings.Split(synonyms, ",")))
+	entry.Tags = normalizeTags(sanitizeStrings(strings.Split(tags, ",")))
+
+	// Те же проверки, что при добавлении и в PUT /api/entries/{index}
+	if err := ValidateEntry(entry); err != nil {
+		fmt.Println(t(msgWordNotChanged), tErr(err))
+		return
+	}
+	changed := false
+	err := modifySlangData(s, func(data *SlangData) error {
+		j := findEntryByID(data.Entries, entry.ID)
+		if j < 0 || data.Entries[j].Owner != username {
+			return errEntryNotFound
+		}
+		if ownerWordExists(data.Entries, username, entry.Word, j) {
+			return errWordExists
+		}
+		// Введённые поля ложатся на свежую запись: рейтинг и остальное могли
+		// поменяться, пока шло редактирование
+		edited := data.Entries[j]
+		edited.Word, edited.Meaning, edited.Example, edited.Origin = entry.Word, entry.Meaning, entry.Example, entry.Origin
+		edited.Synonyms, edited.Tags = entry.Synonyms, entry.Tags
+		if sameEntryContent(data.Entries[j], edited) {
+			return errNoChanges
+		}
+		updateEntry(data, j, edited, username)
+		changed = true
+		return nil
+	})
+	switch {
+	case errors.Is(err, errEntryNotFound):
+		fmt.Println(t(msgWordGoneCLI, original))
+	case errors.Is(err, errWordExists):
+		fmt.Println(t(msgWordAlreadyInDictionary, entry.Word))
+	case err != nil:
+		fmt.Println(t(msgWordSaveFailed), err)
+	case !changed:
+		fmt.Println(t(msgWordUnchangedCLI))
+	default:
+		fmt.Println(t(msgWordUpdatedCLI, entry.Word))
+	}
+}
+
+func deleteEntry(s Store, slangData *SlangData, username string) {
+	own := ownEntries(slangData.Entries, username)
+	if len(own) == 0 {
+		fmt.Println(t(msgNothingToDelete))
+		return
+	}
+	showAllEntries(pickEntries(slangData.Entries, own))
+	fmt.Print(t(msgPromptDeleteIndex))
+	index, err := readNumber()
+	if errors.Is(err, io.EOF) {
+		printInputCancelled()
+		return
+	}
+	if err != nil || index < 1 || index > len(own) {
+		fmt.Println(t(msgNoSuchNumber))
+		return
+	}
+	deleteEntryAt(s, slangData, own[index-1])
+}
+
+// Удаление записи i в корзину после подтверждения. Как и при изменении, запись
+// ищется заново по ID: номер i относится к снимку словаря.
+func deleteEntryAt(s Store, slangData *SlangData, i int) {
+	id, wordToDelete := slangData.Entries[i].ID, slangData.Entries[i].Word
+	fmt.Print(t(msgConfirmDelete, wordToDelete))
+	confirm, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return
+	}
+	if isYes(confirm) {
+		err := modifySlangData(s, func(data *SlangData) error {
+			j := findEntryByID(data.Entries, id)
+			if j < 0 {
+				return errEntryNotFound
+			}
+			removeEntry(data, j, false)
+			return nil
+		})
+		if errors.Is(err, errEntryNotFound) {
+			fmt.Println(t(msgWordGoneCLI, wordToDelete))
+			return
+		}
+		if err != nil {
+			fmt.Println(t(msgWordDeleteFailed), err)
+			return
+		}
+		fmt.Println(t(msgWordMovedToTrash, wordToDelete))
+	} else {
+		fmt.Println(t(msgDeletionCancelled))
+	}
+}
+
+func changePasswordCLI(s Store, slangData *SlangData, username string) {
+	fmt.Print(t(msgPromptCurrentPassword))
+	current, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return
+	}
+	fmt.Print(t(msgPromptChangedPassword))
+	newPassword, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return
+	}
+
+	updated := *slangData
+	updated.Users = slices.Clone(slangData.Users)
+	user := findUser(&updated, username)
+	if user == nil {
+		fmt.Println(t(msgUserNotFound))
+		return
+	}
+	if err := changePassword(user, current, newPassword); err != nil {
+		fmt.Println(t(msgPasswordNotChanged), tErr(err))
+		return
+	}
+	if err := s.SetUser(*user); err != nil {
+		fmt.Println(t(msgPasswordSaveFailedErr), err)
+		return
+	}
+	*slangData = updated
+	fmt.Println(t(msgPasswordChanged))
+}
+
+// Удаление аккаунта из консоли; true, если аккаунт удалён и нужно выйти из словаря
+func deleteAccountCLI(s Store, slangData *SlangData, username string) bool {
+	fmt.Println(t(msgAccountWillBeDeleted, username))
+	fmt.Print(t(msgConfirmUsername))
+	confirm, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return false
+	}
+	if normalizeUsername(confirm) != username {
+		fmt.Println(t(msgDeletionCancelled))
+		return false
+	}
+	fmt.Print(t(msgPromptPassword))
+	password, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return false
+	}
+	user, err := s.GetUser(username)
+	if err != nil || !checkPassword(user, password) {
+		fmt.Println(t(msgWrongPasswordCancelled))
+		return false
+	}
+	fmt.Print(t(msgConfirmDeleteWords))
+	withEntries, err := readInput()
+	if err != nil {
+		printInputCancelled()
+		return false
+	}
+
+	var deleted int
+	err = modifySlangData(s, func(data *SlangData) error {
+		// Аккаунт могли удалить через API, пока шли вопросы
+		if findUser(data, username) == nil {
+			return errUserNotFound
+		}
+		deleted = deleteUser(data, username, isYes(withEntries))
+		return nil
+	})
+	if errors.Is(err, errUserNotFound) {
+		fmt.Println(t(msgUserNotFound))
+		return true
+	}
+	if err != nil {
+		fmt.Println(t(msgAccountDeleteFailed), err)
+		return false
+	}
+	fmt.Println(t(msgAccountDeletedCLI, deleted))
+	return true
+}
@@ -0,0 +1,950 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Тестовый сервер над хранилищем в памяти: диск не трогается, тесты не зависят друг от друга
+func newTestServer(t *testing.T) (*httptest.Server, *MemoryStore) {
+	t.Helper()
+	store := NewMemoryStore(emptySlangData())
+	srv := httptest.NewServer(newRouter(store))
+	t.Cleanup(srv.Close)
+	return srv, store
+}
+
+// Пользователь и его записи сразу в хранилище, минуя API. Токен выдаётся напрямую,
+// чтобы не упираться в ограничение частоты попыток входа.
+func seedUser(t *testing.T, s Store, username, password string, entries ...SlangEntry) string {
+	t.Helper()
+	slangData := loadSlangData(s)
+	hash, err := hashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slangData.Users = append(slangData.Users, User{Username: username, Password: hash})
+	for _, entry := range entries {
+		entry.ID = newEntryID()
+		entry.Owner = username
+		slangData.Entries = append(slangData.Entries, entry)
+	}
+	if err := saveSlangData(s, slangData); err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := issueToken(username, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+type testResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// Поле из JSON-объекта ответа; пустая строка, если его нет
+func (resp testResponse) field(t *testing.T, name string) string {
+	t.Helper()
+	var obj map[string]interface{}
+	if err := json.Unmarshal(resp.body, &obj); err != nil {
+		t.Fatalf("ответ не JSON-объект: %s", resp.body)
+	}
+	value, _ := obj[name].(string)
+	return value
+}
+
+func doRequest(t *testing.T, srv *httptest.Server, method, path, token, body string) testResponse {
+	t.Helper()
+	return doRequestWithHeaders(t, srv, method, path, token, body, nil)
+}
+
+// То же с дополнительными заголовками запроса (Idempotency-Key, If-Match и т.п.)
+func doRequestWithHeaders(t *testing.T, srv *httptest.Server, method, path, token, body string, header http.Header) testResponse {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testResponse{status: res.StatusCode, header: res.Header, body: data}
+}
+
+// Код ответа на POST-запрос; 0 при сетевой ошибке. В отличие от doRequest
+// не вызывает t.Fatal, поэтому годится для горутин.
+func postStatus(srv *httptest.Server, path, token, body string) int {
+	req, err := http.NewRequest("POST", srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		return 0
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		return 0
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	return res.StatusCode
+}
+
+func expectError(t *testing.T, resp testResponse, status int, code errorCode) {
+	t.Helper()
+	if resp.status != status {
+		t.Fatalf("status = %d, want %d; body %s", resp.status, status, resp.body)
+	}
+	if got := resp.field(t, "errorCode"); got != string(code) {
+		t.Errorf("errorCode = %q, want %q", got, code)
+	}
+	if resp.field(t, "error") == "" {
+		t.Errorf("пустое сообщение об ошибке: %s", resp.body)
+	}
+}
+
+func TestGetEntries(t *testing.T) {
+	srv, store := newTestServer(t)
+
+	resp := doRequest(t, srv, "GET", "/api/entries", "", "")
+	if resp.status != http.StatusOK || strings.TrimSpace(string(resp.body)) != "[]" {
+		t.Fatalf("пустой словарь: %d %s", resp.status, resp.body)
+	}
+
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии"},
+		SlangEntry{Word: "кринж", Meaning: "испанский стыд"},
+		SlangEntry{Word: "секрет", Meaning: "личное слово", Private: true},
+	)
+	seedUser(t, store, "bob", "1234", SlangEntry{Word: "изи", Meaning: "легко"})
+
+	var entries []SlangEntry
+	resp = doRequest(t, srv, "GET", "/api/entries", "", "")
+	if err := json.Unmarshal(resp.body, &entries); err != nil {
+		t.Fatal(err)
+	}
+	// Без токена — общий словарь всех пользователей без личных слов
+	if len(entries) != 3 {
+		t.Errorf("общий словарь: %d записей, want 3", len(entries))
+	}
+
+	resp = doRequest(t, srv, "GET", "/api/entries", token, "")
+	if err := json.Unmarshal(resp.body, &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[2].Word != "секрет" {
+		t.Errorf("словарь alice: %+v", entries)
+	}
+
+	var page entriesPage
+	resp = doRequest(t, srv, "GET", "/api/entries?limit=1&offset=1", token, "")
+	if err := json.Unmarshal(resp.body, &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || page.Limit != 1 || page.Offset != 1 || len(page.Entries) != 1 || page.Entries[0].Word != "кринж" {
+		t.Errorf("страница: %+v", page)
+	}
+
+	expectError(t, doRequest(t, srv, "GET", "/api/entries?limit=-1", "", ""), http.StatusBadRequest, errCodeInvalidParameter)
+	expectError(t, doRequest(t, srv, "GET", "/api/entries?hasExample=yes", "", ""), http.StatusBadRequest, errCodeInvalidParameter)
+	expectError(t, doRequest(t, srv, "GET", "/api/entries?since=вчера", "", ""), http.StatusBadRequest, errCodeInvalidParameter)
+	expectError(t, doRequest(t, srv, "GET", "/api/entries?sort=color", "", ""), http.StatusBadRequest, errCodeInvalidParameter)
+	expectError(t, doRequest(t, srv, "GET", "/api/entries", "not-a-token", ""), http.StatusUnauthorized, errCodeUnauthorized)
+}
+
+func TestGetEntriesPresenceFilters(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии", Example: "Он мой краш", Origin: "англ. crush"},
+		SlangEntry{Word: "кринж", Meaning: "испанский стыд", Example: "Это кринж", Synonyms: []string{"стыд"}},
+		SlangEntry{Word: "рофл", Meaning: "шутка", Example: "  "},
+		SlangEntry{Word: "изи", Meaning: "легко", Origin: "англ. easy", Tags: []string{"gaming"}},
+	)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"hasExample=false", []string{"рофл", "изи"}},
+		{"hasExample=true", []string{"краш", "кринж"}},
+		{"hasOrigin=false", []string{"кринж", "рофл"}},
+		{"hasSynonyms=true", []string{"кринж"}},
+		{"hasTags=true", []string{"изи"}},
+		// Фильтры действуют вместе
+		{"hasExample=true&hasOrigin=false", []string{"кринж"}},
+		{"hasExample=false&hasOrigin=false", []string{"рофл"}},
+		{"hasExample=true&hasSynonyms=true&hasOrigin=true", []string{}},
+	}
+	for _, tt := range tests {
+		resp := doRequest(t, srv, "GET", "/api/entries?"+tt.query, token, "")
+		if resp.status != http.StatusOK {
+			t.Fatalf("%s: status = %d; body %s", tt.query, resp.status, resp.body)
+		}
+		var entries []SlangEntry
+		if err := json.Unmarshal(resp.body, &entries); err != nil {
+			t.Fatal(err)
+		}
+		words := []string{}
+		for _, e := range entries {
+			words = append(words, e.Word)
+		}
+		if strings.Join(words, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: %v, want %v", tt.query, words, tt.want)
+		}
+	}
+
+	for _, query := range []string{"hasOrigin=1", "hasSynonyms=", "hasTags=TRUE"} {
+		expectError(t, doRequest(t, srv, "GET", "/api/entries?"+query, token, ""), http.StatusBadRequest, errCodeInvalidParameter)
+	}
+}
+
+func TestEntryFields(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии", Example: "Он мой краш", Synonyms: []string{"симпа"}},
+		SlangEntry{Word: "кринж", Meaning: "испанский стыд"},
+	)
+	id := loadSlangData(store).Entries[0].ID
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/entries?fields=word,meaning", `[{"meaning":"объект симпатии","word":"краш"},{"meaning":"испанский стыд","word":"кринж"}]`},
+		{"/api/entries?fields=word&limit=1", `{"total":2,"limit":1,"offset":0,"entries":[{"word":"краш"}]}`},
+		{"/api/entries/1?fields=word,synonyms", `{"synonyms":["симпа"],"word":"краш"}`},
+		{"/api/entry/" + id + "?fields=word,%20example", `{"example":"Он мой краш","word":"краш"}`},
+		// Пустое поле с omitempty не выводится, как и без fields
+		{"/api/entries/2?fields=word,origin", `{"word":"кринж"}`},
+	}
+	for _, tt := range tests {
+		resp := doRequest(t, srv, "GET", tt.path, token, "")
+		if resp.status != http.StatusOK {
+			t.Fatalf("%s: status = %d; body %s", tt.path, resp.status, resp.body)
+		}
+		if got := strings.TrimSpace(string(resp.body)); got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.path, got, tt.want)
+		}
+	}
+
+	for _, path := range []string{"/api/entries?fields=word,password", "/api/entries/1?fields=Word", "/api/entry/" + id + "?fields=nope"} {
+		expectError(t, doRequest(t, srv, "GET", path, token, ""), http.StatusBadRequest, errCodeInvalidParameter)
+	}
+}
+
+func TestEntryAttribution(t *testing.T) {
+	srv, store := newTestServer(t)
+	// Запись из старых данных — без автора
+	token := seedUser(t, store, "alice", "1234", SlangEntry{Word: "рофл", Meaning: "шутка"})
+
+	// Автора из тела запроса подменить нельзя
+	resp := doRequest(t, srv, "POST", "/api/entries", token, `{"word":"краш","meaning":"объект симпатии","author":"bob"}`)
+	if resp.status != http.StatusCreated {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	if added := loadSlangData(store).Entries[1]; added.Author != "alice" || added.LastEditedBy != "" {
+		t.Errorf("после добавления: %+v", added)
+	}
+
+	resp = doRequest(t, srv, "PATCH", "/api/entries/2", token, `{"meaning":"человек, который нравится"}`)
+	if resp.status != http.StatusOK || resp.field(t, "author") != "alice" || resp.field(t, "last_edited_by") != "alice" {
+		t.Errorf("после изменения: %d %s", resp.status, resp.body)
+	}
+	resp = doRequest(t, srv, "PUT", "/api/entries/2", token, `{"word":"краш","meaning":"объект симпатии","author":"bob","last_edited_by":"bob"}`)
+	if resp.status != http.StatusOK || resp.field(t, "author") != "alice" || resp.field(t, "last_edited_by") != "alice" {
+		t.Errorf("после замены: %d %s", resp.status, resp.body)
+	}
+
+	resp = doRequest(t, srv, "GET", "/api/entries?author=Alice", token, "")
+	var entries []SlangEntry
+	if err := json.Unmarshal(resp.body, &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Word != "краш" {
+		t.Errorf("?author=Alice: %+v", entries)
+	}
+}
+
+// Слова сортируются в том же порядке, что и в подсказках и нечётком поиске:
+// без учёта регистра, «ё» — как «е», а не после «я»
+func TestSortEntriesByWord(t *testing.T) {
+	var entries []SlangEntry
+	for _, word := range []string{"яблоко", "ёлка", "Ель", "арбуз"} {
+		entries = append(entries, SlangEntry{Word: word})
+	}
+	for key, want := range map[string]string{
+		"word":  "арбуз,ёлка,Ель,яблоко",
+		"-word": "яблоко,Ель,ёлка,арбуз",
+	} {
+		sorted, err := sortEntries(entries, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var words []string
+		for _, entry := range sorted {
+			words = append(words, entry.Word)
+		}
+		if got := strings.Join(words, ","); got != want {
+			t.Errorf("sort=%s: %s, want %s", key, got, want)
+		}
+	}
+}
+
+func TestLetterIndex(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии"},
+		SlangEntry{Word: "Кринж", Meaning: "испанский стыд"},
+		SlangEntry{Word: "ёлка", Meaning: "ёлка"},
+		SlangEntry{Word: "lol", Meaning: "смешно"},
+		SlangEntry{Word: "2к", Meaning: "2000 рублей"},
+		SlangEntry{Word: "#тег", Meaning: "хештег"},
+	)
+
+	resp := doRequest(t, srv, "GET", "/api/index", token, "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	// Ключи идут по порядку: «#», латиница, кириллица
+	want := `{"#":2,"L":1,"Е":1,"К":2}`
+	if got := strings.TrimSpace(string(resp.body)); got != want {
+		t.Errorf("index = %s, want %s", got, want)
+	}
+}
+
+func TestAddEntry(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234")
+	body := `{"word":"краш","meaning":"объект симпатии","tags":["Gen-Z"]}`
+
+	expectError(t, doRequest(t, srv, "POST", "/api/entries", "", body), http.StatusUnauthorized, errCodeUnauthorized)
+	expectError(t, doRequest(t, srv, "POST", "/api/entries", token, `{"word":`), http.StatusBadRequest, errCodeInvalidJSON)
+	expectError(t, doRequest(t, srv, "POST", "/api/entries", token, `{"word":"краш"}`), http.StatusBadRequest, errCodeValidationFailed)
+
+	resp := doRequest(t, srv, "POST", "/api/entries", token, body)
+	if resp.status != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body %s", resp.status, resp.body)
+	}
+	if resp.field(t, "message") == "" {
+		t.Errorf("нет сообщения: %s", resp.body)
+	}
+
+	slangData := loadSlangData(store)
+	if len(slangData.Entries) != 1 {
+		t.Fatalf("в словаре %d записей, want 1", len(slangData.Entries))
+	}
+	entry := slangData.Entries[0]
+	if entry.ID == "" || entry.Owner != "alice" || entry.CreatedAt.IsZero() || len(entry.Tags) != 1 || entry.Tags[0] != "gen-z" {
+		t.Errorf("сохранённая запись: %+v", entry)
+	}
+
+	// То же слово в другом регистре — дубликат в словаре пользователя
+	expectError(t, doRequest(t, srv, "POST", "/api/entries", token, `{"word":"КРАШ","meaning":"другое значение"}`),
+		http.StatusConflict, errCodeDuplicateWord)
+	// У другого пользователя — своё независимое слово
+	other := seedUser(t, store, "bob", "1234")
+	if resp := doRequest(t, srv, "POST", "/api/entries", other, body); resp.status != http.StatusCreated {
+		t.Errorf("слово bob: status = %d; body %s", resp.status, resp.body)
+	}
+}
+
+// Хранилище с задержкой после чтения: расширяет окно между чтением и сохранением,
+// чтобы потерянные обновления воспроизводились даже на одном процессоре
+type slowStore struct {
+	*FileStore
+}
+
+func (s slowStore) Load() (SlangData, error) {
+	slangData, err := s.FileStore.Load()
+	time.Sleep(time.Millisecond)
+	return slangData, err
+}
+
+// Параллельные добавления разных слов не должны терять друг друга: чтение,
+// проверка дубликата и сохранение идут под одной блокировкой хранилища
+func TestConcurrentAddEntry(t *testing.T) {
+	store := slowStore{NewFileStore(filepath.Join(t.TempDir(), "slang.json"))}
+	srv := httptest.NewServer(newRouter(store))
+	defer srv.Close()
+	token := seedUser(t, store, "alice", "1234")
+
+	const n = 50
+	statuses := make([]int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"word":"слово%d","meaning":"значение номер %d"}`, i, i)
+			statuses[i] = postStatus(srv, "/api/entries", token, body)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, status := range statuses {
+		if status != http.StatusCreated {
+			t.Errorf("слово%d: status = %d, want 201", i, status)
+		}
+	}
+	slangData := loadSlangData(store)
+	if len(slangData.Entries) != n {
+		t.Fatalf("сохранено %d записей из %d", len(slangData.Entries), n)
+	}
+	seen := make(map[string]bool)
+	for _, e := range slangData.Entries {
+		seen[e.Word] = true
+	}
+	for i := 0; i < n; i++ {
+		if word := fmt.Sprintf("слово%d", i); !seen[word] {
+			t.Errorf("потеряно слово %s", word)
+		}
+	}
+}
+
+// Одно и то же слово, добавляемое параллельно, сохраняется ровно один раз
+func TestConcurrentAddSameWord(t *testing.T) {
+	store := slowStore{NewFileStore(filepath.Join(t.TempDir(), "slang.json"))}
+	srv := httptest.NewServer(newRouter(store))
+	defer srv.Close()
+	token := seedUser(t, store, "alice", "1234")
+
+	const n = 20
+	statuses := make([]int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = postStatus(srv, "/api/entries", token, `{"word":"краш","meaning":"объект симпатии"}`)
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, status := range statuses {
+		switch status {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("status = %d, want 201 or 409", status)
+		}
+	}
+	if created != 1 {
+		t.Errorf("создано %d раз, want 1", created)
+	}
+	if n := len(loadSlangData(store).Entries); n != 1 {
+		t.Errorf("в словаре %d записей, want 1", n)
+	}
+}
+
+// Голоса, импорт и добавление, идущие параллельно, не затирают друг друга
+// Консоль работает со снимком словаря, загруженным раньше: изменения через API
+// после этого не должны теряться, а номер из снимка — попадать в чужую запись
+func TestCLIActionsUseFreshData(t *testing.T) {
+	_, store := newTestServer(t)
+	seedUser(t, store, "cli", "secret123",
+		SlangEntry{Word: "кринж", Meaning: "стыд", Example: "это кринж"},
+		SlangEntry{Word: "рофл", Meaning: "шутка", Example: "это рофл"})
+	snapshot := loadSlangData(store)
+
+	// Пока консоль ждёт ввода, через API удаляют первое слово и добавляют новое
+	err := modifySlangData(store, func(slangData *SlangData) error {
+		removeEntry(slangData, 0, false)
+		slangData.Entries = append(slangData.Entries, SlangEntry{ID: newEntryID(), Owner: "cli", Word: "вайб", Meaning: "атмосфера", Example: "хороший вайб"})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setInput := func(input string) { stdin = bufio.NewReader(strings.NewReader(input)) }
+	t.Cleanup(func() { setInput("") })
+
+	// Удалённое слово не воскресает и не тянет за собой соседей
+	setInput("y\n")
+	deleteEntryAt(store, &snapshot, 0)
+	// Номер 1 в снимке — «рофл», хотя в хранилище он уже первый
+	setInput("y\n")
+	deleteEntryAt(store, &snapshot, 1)
+	// Изменение исчезнувшего слова ничего не сохраняет
+	setInput("кринжа\n\n\n\n\n\n")
+	editEntryAt(store, &snapshot, "cli", 0)
+
+	var words []string
+	for _, entry := range loadSlangData(store).Entries {
+		words = append(words, entry.Word)
+	}
+	if strings.Join(words, ",") != "вайб" {
+		t.Errorf("слова %v, want [вайб]", words)
+	}
+}
+
+func TestConcurrentVoteImportAdd(t *testing.T) {
+	store := slowStore{NewFileStore(filepath.Join(t.TempDir(), "slang.json"))}
+	srv := httptest.NewServer(newRouter(store))
+	defer srv.Close()
+	owner := seedUser(t, store, "alice", "1234", SlangEntry{Word: "краш", Meaning: "объект симпатии"})
+
+	const voters = 10
+	tokens := make([]string, voters)
+	for i := range tokens {
+		tokens[i] = seedUser(t, store, fmt.Sprintf("voter%d", i), "1234")
+	}
+
+	var wg sync.WaitGroup
+	statuses := make([]int, voters+2)
+	for i, token := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = postStatus(srv, "/api/entries/1/vote?shared=true", token, `{"direction":"up"}`)
+		}()
+	}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		statuses[voters] = postStatus(srv, "/api/entries/import", owner, `[{"word":"изи","meaning":"легко"}]`)
+	}()
+	go func() {
+		defer wg.Done()
+		statuses[voters+1] = postStatus(srv, "/api/entries", owner, `{"word":"рофл","meaning":"шутка"}`)
+	}()
+	wg.Wait()
+
+	for i, status := range statuses {
+		if status != http.StatusOK && status != http.StatusCreated {
+			t.Errorf("запрос %d: status = %d", i, status)
+		}
+	}
+	slangData := loadSlangData(store)
+	if len(slangData.Entries) != 3 {
+		t.Errorf("записей %d, want 3", len(slangData.Entries))
+	}
+	if score := slangData.Entries[0].Score; score != voters {
+		t.Errorf("рейтинг %d, want %d", score, voters)
+	}
+}
+
+// Параллельная регистрация разных пользователей не теряет ни одного
+func TestConcurrentRegister(t *testing.T) {
+	store := slowStore{NewFileStore(filepath.Join(t.TempDir(), "slang.json"))}
+	srv := httptest.NewServer(newRouter(store))
+	defer srv.Close()
+
+	const n = 10
+	statuses := make([]int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"username":"user%d","password":"1234"}`, i)
+			statuses[i] = postStatus(srv, "/api/register", "", body)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, status := range statuses {
+		if status != http.StatusCreated {
+			t.Errorf("user%d: status = %d, want 201", i, status)
+		}
+	}
+	if got := len(loadSlangData(store).Users); got != n {
+		t.Errorf("сохранено %d пользователей из %d", got, n)
+	}
+}
+
+func TestDeleteEntry(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии"},
+		SlangEntry{Word: "кринж", Meaning: "испанский стыд"},
+	)
+	other := seedUser(t, store, "bob", "1234")
+
+	expectError(t, doRequest(t, srv, "DELETE", "/api/entries/1", "", ""), http.StatusUnauthorized, errCodeUnauthorized)
+	for _, index := range []string{"abc", "0", "-1"} {
+		expectError(t, doRequest(t, srv, "DELETE", "/api/entries/"+index, token, ""), http.StatusBadRequest, errCodeInvalidParameter)
+	}
+	expectError(t, doRequest(t, srv, "DELETE", "/api/entries/3", token, ""), http.StatusNotFound, errCodeNotFound)
+	// Номера считаются в словаре пользователя: у bob записей нет
+	expectError(t, doRequest(t, srv, "DELETE", "/api/entries/1", other, ""), http.StatusNotFound, errCodeNotFound)
+
+	resp := doRequest(t, srv, "DELETE", "/api/entries/1", token, "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body %s", resp.status, resp.body)
+	}
+	slangData := loadSlangData(store)
+	if len(slangData.Entries) != 1 || slangData.Entries[0].Word != "кринж" {
+		t.Errorf("после удаления: %+v", slangData.Entries)
+	}
+	if len(slangData.Trash) != 1 || slangData.Trash[0].Word != "краш" {
+		t.Errorf("корзина: %+v", slangData.Trash)
+	}
+
+	if resp := doRequest(t, srv, "DELETE", "/api/entries/1?permanent=true", token, ""); resp.status != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body %s", resp.status, resp.body)
+	}
+	slangData = loadSlangData(store)
+	if len(slangData.Entries) != 0 || len(slangData.Trash) != 1 {
+		t.Errorf("после удаления насовсем: записи %+v, корзина %+v", slangData.Entries, slangData.Trash)
+	}
+}
+
+// С dryRun=true удаление только показывается: словарь, корзина и события не меняются
+func TestDeleteEntryDryRun(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии"},
+		SlangEntry{Word: "кринж", Meaning: "испанский стыд"},
+	)
+	before := loadSlangData(store)
+	events := openEvents(t, srv.URL, "")
+
+	for _, path := range []string{
+		"/api/entries/2?dryRun=true",
+		"/api/entry/" + before.Entries[1].ID + "?dryRun=true&permanent=true",
+		"/api/entries/by-word/кринж?dryRun=true",
+	} {
+		resp := doRequest(t, srv, "DELETE", path, token, "")
+		if resp.status != http.StatusOK {
+			t.Fatalf("%s: status = %d; body %s", path, resp.status, resp.body)
+		}
+		var preview deletionPreview
+		if err := json.Unmarshal(resp.body, &preview); err != nil {
+			t.Fatal(err)
+		}
+		if !preview.DryRun || len(preview.Deleted) != 1 || preview.Deleted[0].Word != "кринж" || preview.Remaining != 1 {
+			t.Errorf("%s: %+v", path, preview)
+		}
+	}
+	expectError(t, doRequest(t, srv, "DELETE", "/api/entries/3?dryRun=true", token, ""), http.StatusNotFound, errCodeNotFound)
+
+	after := loadSlangData(store)
+	if len(after.Entries) != 2 || len(after.Trash) != 0 {
+		t.Errorf("dryRun изменил данные: записи %+v, корзина %+v", after.Entries, after.Trash)
+	}
+
+	// Первое событие после пробных удалений — от настоящего
+	if resp := doRequest(t, srv, "DELETE", "/api/entries/2", token, ""); resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	if event := nextEvent(t, events); event.event != "deleted" || event.data.ID != before.Entries[1].ID {
+		t.Errorf("событие: %+v", event)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	srv, store := newTestServer(t)
+
+	resp := doRequest(t, srv, "POST", "/api/register", "", `{"username":" Alice ","password":"1234"}`)
+	if resp.status != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body %s", resp.status, resp.body)
+	}
+	slangData := loadSlangData(store)
+	if len(slangData.Users) != 1 {
+		t.Fatalf("пользователей: %d, want 1", len(slangData.Users))
+	}
+	user := slangData.Users[0]
+	if user.Username != "alice" || !isBcryptHash(user.Password) || !checkPassword(user, "1234") {
+		t.Errorf("сохранённый пользователь: %+v", user)
+	}
+
+	expectError(t, doRequest(t, srv, "POST", "/api/register", "", `{"username":"ALICE","password":"5678"}`),
+		http.StatusConflict, errCodeUserExists)
+	expectError(t, doRequest(t, srv, "POST", "/api/register", "", `{"username":"bob","password":"123"}`),
+		http.StatusBadRequest, errCodeValidationFailed)
+	expectError(t, doRequest(t, srv, "POST", "/api/register", "", `{"username":"  ","password":"1234"}`),
+		http.StatusBadRequest, errCodeValidationFailed)
+	expectError(t, doRequest(t, srv, "POST", "/api/register", "", `not json`), http.StatusBadRequest, errCodeInvalidJSON)
+
+	if n := len(loadSlangData(store).Users); n != 1 {
+		t.Errorf("после ошибок пользователей: %d, want 1", n)
+	}
+}
+
+// Попыток входа здесь меньше, чем разрешает loginLimiter подряд с одного адреса
+func TestLogin(t *testing.T) {
+	srv, store := newTestServer(t)
+
+	expectError(t, doRequest(t, srv, "POST", "/api/login", "", `{"username":"alice","password":"1234"}`),
+		http.StatusUnauthorized, errCodeUnauthorized)
+
+	seedUser(t, store, "alice", "1234")
+	expectError(t, doRequest(t, srv, "POST", "/api/login", "", `{"username":"alice","password":"4321"}`),
+		http.StatusUnauthorized, errCodeInvalidCredentials)
+
+	resp := doRequest(t, srv, "POST", "/api/login", "", `{"username":"Alice","password":"1234"}`)
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body %s", resp.status, resp.body)
+	}
+	if got := resp.field(t, "username"); got != "alice" {
+		t.Errorf("username = %q, want alice", got)
+	}
+	token := resp.field(t, "token")
+	if token == "" || resp.field(t, "expires_at") == "" {
+		t.Fatalf("нет токена: %s", resp.body)
+	}
+
+	// Выданный токен принимается защищёнными маршрутами
+	resp = doRequest(t, srv, "GET", "/api/user", token, "")
+	if resp.status != http.StatusOK || resp.field(t, "username") != "alice" {
+		t.Errorf("GET /api/user: %d %s", resp.status, resp.body)
+	}
+}
+
+// После удаления аккаунта не действует ни один его токен, в том числе когда
+// логин снова заняли
+func TestDeletedUserTokens(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "bob", "1234")
+	other, _, err := issueToken("bob", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := `{"word": "краш", "meaning": "симпатия", "example": "мой краш"}`
+
+	if resp := doRequest(t, srv, "DELETE", "/api/user", token, `{"password": "1234"}`); resp.status != http.StatusOK {
+		t.Fatalf("DELETE /api/user: %d %s", resp.status, resp.body)
+	}
+	expectError(t, doRequest(t, srv, "POST", "/api/entries", other, entry), http.StatusUnauthorized, errCodeUnauthorized)
+	expectError(t, doRequest(t, srv, "GET", "/api/entries", other, ""), http.StatusUnauthorized, errCodeUnauthorized)
+
+	// Новый владелец логина зарегистрировался позже, чем выдан старый токен
+	if err := store.SetUser(User{Username: "bob", Password: "x", CreatedAt: time.Now().Add(time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, doRequest(t, srv, "POST", "/api/entries", other, entry), http.StatusUnauthorized, errCodeUnauthorized)
+	if entries := loadSlangData(store).Entries; len(entries) != 0 {
+		t.Errorf("записи от старого токена: %+v", entries)
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("краш\r\n\nпоследняя"))
+	for _, want := range []string{"краш", "", "последняя"} {
+		line, err := readLine(reader)
+		if err != nil || line != want {
+			t.Fatalf("readLine = %q, %v; want %q", line, err, want)
+		}
+	}
+	// Ввод закончился — EOF, а не бесконечные пустые строки
+	if _, err := readLine(reader); err != io.EOF {
+		t.Errorf("после конца ввода: %v", err)
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234")
+	defer func(limit int64) { maxBodyBytes = limit }(maxBodyBytes)
+	maxBodyBytes = 64
+
+	long := `{"word": "краш", "meaning": "` + strings.Repeat("о", 100) + `"}`
+	expectError(t, doRequest(t, srv, "POST", "/api/entries", token, long), http.StatusRequestEntityTooLarge, errCodePayloadTooLarge)
+	expectError(t, doRequest(t, srv, "POST", "/api/register", "", `{"username": "`+strings.Repeat("b", 100)+`", "password": "1234"}`),
+		http.StatusRequestEntityTooLarge, errCodePayloadTooLarge)
+	expectError(t, doRequest(t, srv, "POST", "/api/entries/import?source=urban", token, `{"list": [`+strings.Repeat(" ", 100)+`]}`),
+		http.StatusRequestEntityTooLarge, errCodePayloadTooLarge)
+
+	// Тело в пределах лимита читается как обычно
+	if resp := doRequest(t, srv, "POST", "/api/entries", token, `{"word": "изи", "meaning": "легко"}`); resp.status != http.StatusCreated {
+		t.Errorf("короткое тело: status = %d; body %s", resp.status, resp.body)
+	}
+}
+
+func TestUnknownJSONField(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234", SlangEntry{Word: "краш", Meaning: "объект симпатии"})
+
+	tests := []struct {
+		method, path, body string
+		field              string
+	}{
+		{"POST", "/api/entries", `{"word": "изи", "meening": "легко"}`, "meening"},
+		{"PUT", "/api/entries/1", `{"word": "краш", "meaning": "симпатия", "tag": ["x"]}`, "tag"},
+		{"PATCH", "/api/entries/1", `{"exmaple": "он мой краш"}`, "exmaple"},
+		{"POST", "/api/register", `{"username": "bob", "passwrd": "1234"}`, "passwrd"},
+	}
+	for _, tt := range tests {
+		resp := doRequest(t, srv, tt.method, tt.path, token, tt.body)
+		expectError(t, resp, http.StatusBadRequest, errCodeInvalidJSON)
+		if msg := resp.field(t, "error"); !strings.Contains(msg, tt.field) {
+			t.Errorf("%s %s: сообщение %q не называет поле %q", tt.method, tt.path, msg, tt.field)
+		}
+	}
+
+	// Просто неверный JSON — прежнее общее сообщение
+	if _, ok := unknownJSONField(io.ErrUnexpectedEOF); ok {
+		t.Error("unknownJSONField распознал чужую ошибку")
+	}
+}
+
+func TestContentTypeRequired(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234")
+
+	tests := []struct {
+		path, contentType, body string
+		status                  int
+	}{
+		{"/api/entries", "", `{"word": "изи", "meaning": "легко"}`, http.StatusUnsupportedMediaType},
+		{"/api/entries", "application/x-www-form-urlencoded", "word=изи&meaning=легко", http.StatusUnsupportedMediaType},
+		{"/api/entries", "text/plain", `{"word": "изи", "meaning": "легко"}`, http.StatusUnsupportedMediaType},
+		{"/api/entries/import?source=urban", "text/plain", `{"list": []}`, http.StatusUnsupportedMediaType},
+		{"/api/entries", "application/json; charset=utf-8", `{"word": "изи", "meaning": "легко"}`, http.StatusCreated},
+		{"/api/entries", "Application/JSON", `{"word": "рофл", "meaning": "шутка"}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest("POST", srv.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		res, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		resp := testResponse{status: res.StatusCode, header: res.Header, body: data}
+		if tt.status == http.StatusUnsupportedMediaType {
+			expectError(t, resp, tt.status, errCodeUnsupportedMediaType)
+		} else if resp.status != tt.status {
+			t.Errorf("%s с %q: status = %d; body %s", tt.path, tt.contentType, resp.status, resp.body)
+		}
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234", SlangEntry{Word: "краш", Meaning: "объект симпатии"})
+	ifMatch := func(etag string) http.Header { return http.Header{"If-Match": {etag}} }
+
+	get := doRequest(t, srv, "GET", "/api/entries/1", token, "")
+	etag := get.header.Get("ETag")
+	if get.status != http.StatusOK || etag == "" || strings.HasPrefix(etag, "W/") {
+		t.Fatalf("GET: status = %d, ETag %q", get.status, etag)
+	}
+	byID := doRequest(t, srv, "GET", "/api/entry/"+loadSlangData(store).Entries[0].ID, token, "")
+	if byID.header.Get("ETag") != etag {
+		t.Errorf("ETag по ID %q, по номеру %q", byID.header.Get("ETag"), etag)
+	}
+
+	// Первый клиент меняет запись и получает новый тег
+	first := doRequestWithHeaders(t, srv, "PATCH", "/api/entries/1", token, `{"example": "он мой краш"}`, ifMatch(etag))
+	if first.status != http.StatusOK || first.header.Get("ETag") == etag {
+		t.Fatalf("PATCH с актуальным тегом: status = %d, ETag %q", first.status, first.header.Get("ETag"))
+	}
+	// Второй клиент со старым тегом не затирает чужую правку
+	expectError(t, doRequestWithHeaders(t, srv, "PUT", "/api/entries/1", token, `{"word": "краш", "meaning": "симпатия"}`, ifMatch(etag)),
+		http.StatusPreconditionFailed, errCodePreconditionFailed)
+	expectError(t, doRequestWithHeaders(t, srv, "PATCH", "/api/entries/1", token, `{"meaning": "симпатия"}`, ifMatch(etag)),
+		http.StatusPreconditionFailed, errCodePreconditionFailed)
+	// Слабый тег для If-Match не годится
+	expectError(t, doRequestWithHeaders(t, srv, "PATCH", "/api/entries/1", token, `{"meaning": "симпатия"}`, ifMatch("W/"+first.header.Get("ETag"))),
+		http.StatusPreconditionFailed, errCodePreconditionFailed)
+	if got := loadSlangData(store).Entries[0]; got.Meaning != "объект симпатии" || got.Example != "он мой краш" {
+		t.Errorf("запись после отклонённых правок: %+v", got)
+	}
+
+	// Голос не меняет тег: правке он не мешает. PUT без изменений тег тоже не меняет,
+	// поэтому один и тот же тег проходит все проверки подряд
+	doRequest(t, srv, "POST", "/api/entries/1/vote", token, `{"direction": "up"}`)
+	for _, header := range []http.Header{ifMatch(first.header.Get("ETag")), ifMatch(`"другой", ` + first.header.Get("ETag")), ifMatch("*"), nil} {
+		if resp := doRequestWithHeaders(t, srv, "PUT", "/api/entries/1", token, `{"word": "краш", "meaning": "объект симпатии", "example": "он мой краш"}`, header); resp.status != http.StatusOK {
+			t.Errorf("PUT с If-Match %q: status = %d; body %s", header.Get("If-Match"), resp.status, resp.body)
+		}
+	}
+
+	defer func() { requireIfMatch = false }()
+	requireIfMatch = true
+	expectError(t, doRequest(t, srv, "PATCH", "/api/entries/1", token, `{"meaning": "любовь"}`), http.StatusPreconditionRequired, errCodePreconditionRequired)
+}
+
+// История — по /api/entries/{index}/history, запись по ID — по /api/entry/{id};
+// прежнего адреса истории больше нет
+func TestHistoryRoutes(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234", SlangEntry{Word: "краш", Meaning: "симпатия", Example: "мой краш"})
+	resp := doRequest(t, srv, "PUT", "/api/entries/1", token, `{"word": "краш", "meaning": "объект симпатии", "example": "мой краш"}`)
+	if resp.status != http.StatusOK {
+		t.Fatalf("PUT: %d %s", resp.status, resp.body)
+	}
+	id := resp.field(t, "id")
+
+	resp = doRequest(t, srv, "GET", "/api/entries/1/history", token, "")
+	var history []historyItem
+	if resp.status != http.StatusOK || json.Unmarshal(resp.body, &history) != nil || len(history) != 1 || history[0].Meaning != "симпатия" {
+		t.Errorf("история: %d %s", resp.status, resp.body)
+	}
+	if resp := doRequest(t, srv, "GET", "/api/entry/"+id, token, ""); resp.status != http.StatusOK || resp.field(t, "id") != id {
+		t.Errorf("по ID: %d %s", resp.status, resp.body)
+	}
+	expectError(t, doRequest(t, srv, "GET", "/api/entries/x/history", token, ""), http.StatusBadRequest, errCodeInvalidParameter)
+	expectError(t, doRequest(t, srv, "GET", "/api/entries/2/history", token, ""), http.StatusNotFound, errCodeNotFound)
+	for _, path := range []string{"/api/entries/history/1", "/api/entries/id/" + id} {
+		if resp := doRequest(t, srv, "GET", path, token, ""); resp.status != http.StatusNotFound {
+			t.Errorf("%s: %d", path, resp.status)
+		}
+	}
+}
+
+// Уникальность слова — по канонической форме, а написание остаётся, как его ввели
+func TestCanonicalWord(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234")
+
+	if resp := doRequest(t, srv, "POST", "/api/entries", token, `{"word": "Ёлки  Палки", "meaning": "досада", "canonical_word": "чужое"}`); resp.status != http.StatusCreated {
+		t.Fatalf("добавление: status = %d; body %s", resp.status, resp.body)
+	}
+	entry := loadSlangData(store).Entries[0]
+	if entry.Word != "Ёлки  Палки" || entry.CanonicalWord != "елки палки" {
+		t.Errorf("слово %q, каноническая форма %q", entry.Word, entry.CanonicalWord)
+	}
+	for _, word := range []string{"елки палки", " ЁЛКИ ПАЛКИ "} {
+		expectError(t, doRequest(t, srv, "POST", "/api/entries", token, `{"word": "`+word+`", "meaning": "досада"}`),
+			http.StatusConflict, errCodeDuplicateWord)
+	}
+
+	// При переименовании каноническая форма обновляется вместе со словом
+	resp := doRequest(t, srv, "PATCH", "/api/entries/1", token, `{"word": "Капец"}`)
+	if resp.status != http.StatusOK || resp.field(t, "canonical_word") != "капец" {
+		t.Errorf("PATCH: status = %d; body %s", resp.status, resp.body)
+	}
+	if resp := doRequest(t, srv, "DELETE", "/api/entries/by-word/КАПЕЦ", token, ""); resp.status != http.StatusOK {
+		t.Errorf("удаление по слову: status = %d; body %s", resp.status, resp.body)
+	}
+}
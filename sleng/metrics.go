@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Метрики для Prometheus в текстовом формате (без внешней библиотеки)
+type metricsRegistry struct {
+	mu        sync.Mutex
+	requests  map[requestKey]uint64
+	durations map[routeKey]*histogram
+	logins    map[string]uint64 // success / failure
+}
+
+type routeKey struct {
+	Method string
+	Route  string
+}
+
+type requestKey struct {
+	routeKey
+	Status int
+}
+
+// Границы корзин гистограммы длительности запросов, в секундах
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type histogram struct {
+	counts []uint64 // по корзинам durationBuckets, не накопительно
+	sum    float64
+	count  uint64
+}
+
+var metrics = &metricsRegistry{
+	requests:  make(map[requestKey]uint64),
+	durations: make(map[routeKey]*histogram),
+	logins:    make(map[string]uint64),
+}
+
+// Метод для метки method: клиент может прислать любой, поэтому всё, кроме
+// стандартных методов, учитывается вместе как OTHER
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
+
+// Учёт одного запроса; route — шаблон маршрута из роутера, чтобы номера
+// и слова в пути не плодили отдельные ряды
+func (m *metricsRegistry) observeRequest(method, route string, status int, duration time.Duration) {
+	method = metricMethod(method)
+	// Шаблон вида "GET /api/entries/{index}": метод уже есть в отдельной метке
+	if _, path, found := strings.Cut(route, " "); found {
+		route = path
+	}
+	if route == "" {
+		route = "unmatched"
+	}
+	key := routeKey{Method: method, Route: route}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestKey{routeKey: key, Status: status}]++
+	h, ok := m.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(durationBuckets))}
+		m.durations[key] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (m *metricsRegistry) observeLogin(success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	m.mu.Lock()
+	m.logins[result]++
+	m.mu.Unlock()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Метрики в текстовом формате Prometheus; entries — текущее число записей
+func (m *metricsRegistry) write(b *strings.Builder, entries int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b.WriteString("# HELP sleng_http_requests_total Число HTTP-запросов по маршруту и коду ответа.\n")
+	b.WriteString("# TYPE sleng_http_requests_total counter\n")
+	requestKeys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		requestKeys = append(requestKeys, key)
+	}
+	slices.SortFunc(requestKeys, func(a, b requestKey) int {
+		if c := compareRouteKeys(a.routeKey, b.routeKey); c != 0 {
+			return c
+		}
+		return a.Status - b.Status
+	})
+	for _, key := range requestKeys {
+		fmt.Fprintf(b, "sleng_http_requests_total{method=%q,route=%q,status=\"%d\"} %d\n",
+			key.Method, key.Route, key.Status, m.requests[key])
+	}
+
+	b.WriteString("# HELP sleng_http_request_duration_seconds Длительность обработки HTTP-запросов.\n")
+	b.WriteString("# TYPE sleng_http_request_duration_seconds histogram\n")
+	routeKeys := make([]routeKey, 0, len(m.durations))
+	for key := range m.durations {
+		routeKeys = append(routeKeys, key)
+	}
+	slices.SortFunc(routeKeys, compareRouteKeys)
+	for _, key := range routeKeys {
+		h := m.durations[key]
+		labels := fmt.Sprintf("method=%q,route=%q", key.Method, key.Route)
+		var cumulative uint64
+		for i, bound := range durationBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(b, "sleng_http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(b, "sleng_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(b, "sleng_http_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(h.sum))
+		fmt.Fprintf(b, "sleng_http_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	b.WriteString("# HELP sleng_logins_total Число попыток входа через API по результату.\n")
+	b.WriteString("# TYPE sleng_logins_total counter\n")
+	for _, result := range []string{"failure", "success"} {
+		fmt.Fprintf(b, "sleng_logins_total{result=%q} %d\n", result, m.logins[result])
+	}
+
+	b.WriteString("# HELP sleng_entries Число записей в словаре.\n")
+	b.WriteString("# TYPE sleng_entries gauge\n")
+	fmt.Fprintf(b, "sleng_entries %d\n", entries)
+}
+
+func compareRouteKeys(a, b routeKey) int {
+	if c := strings.Compare(a.Route, b.Route); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Method, b.Method)
+}
+
+// GET /metrics — метрики для Prometheus
+func handleMetrics(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		slangData := loadSlangData(s)
+		var b strings.Builder
+		metrics.write(&b, len(slangData.Entries))
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write([]byte(b.String()))
+	}
+}
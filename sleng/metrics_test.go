@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Выдуманные методы не создают новых рядов: все они попадают в OTHER
+func TestMetricsMethodLabel(t *testing.T) {
+	m := &metricsRegistry{
+		requests:  make(map[requestKey]uint64),
+		durations: make(map[routeKey]*histogram),
+		logins:    make(map[string]uint64),
+	}
+	for _, method := range []string{"GET", "FOO", "BAR1", "get", "DELETE"} {
+		m.observeRequest(method, "", 404, time.Millisecond)
+	}
+	want := map[string]uint64{"GET": 1, "DELETE": 1, "OTHER": 3}
+	if len(m.requests) != len(want) {
+		t.Errorf("рядов %d, want %d: %v", len(m.requests), len(want), m.requests)
+	}
+	for key, n := range m.requests {
+		if want[key.Method] != n {
+			t.Errorf("%s: %d, want %d", key.Method, n, want[key.Method])
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Логгер запросов. Уровень задаётся переменной SLENG_LOG_LEVEL
+// (debug, info, warn, error), формат — SLENG_LOG_FORMAT (text или json).
+var logger = newLogger(os.Getenv("SLENG_LOG_LEVEL"), os.Getenv("SLENG_LOG_FORMAT"))
+
+func newLogger(level, format string) *slog.Logger {
+	var lvl slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		lvl = slog.LevelDebug
+	case "warn":
+		lvl = slog.LevelWarn
+	case "error":
+		lvl = slog.LevelError
+	default:
+		lvl = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	if strings.ToLower(format) == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
+
+// Обёртка над ResponseWriter, запоминающая код ответа
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	rec.status = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+// Нужен http.ResponseController, чтобы добраться до исходного ResponseWriter
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// WebSocket (/api/ws) забирает соединение у HTTP-сервера; upgrader проверяет
+// именно интерфейс http.Hijacker, а не Unwrap
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	rec.status = http.StatusSwitchingProtocols
+	return http.NewResponseController(rec.ResponseWriter).Hijack()
+}
+
+// Middleware, логирующий метод, путь, код ответа и длительность каждого запроса
+// и учитывающий их в метриках. Шаблон маршрута для метрик берётся из роутера mux.
+func logRequests(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		_, route := mux.Handler(r)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		duration := time.Since(start)
+		metrics.observeRequest(r.Method, route, rec.status, duration)
+		logger.Info("request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", route,
+			"status", rec.status,
+			"duration", duration,
+		)
+	})
+}
+
+// Разрешённый источник для CORS задаётся переменной SLENG_CORS_ORIGIN (по умолчанию "*")
+var corsOrigin = envOrDefault("SLENG_CORS_ORIGIN", "*")
+
+func envOrDefault(name, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return def
+}
+
+// Целое число из переменной окружения; при отсутствии или ошибке — значение по умолчанию
+func envInt(name string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
+
+// Длительность из переменной окружения ("30s", "2m"); при отсутствии, ошибке
+// или неположительном значении — значение по умолчанию
+func envDuration(name string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
+
+// Флаг из переменной окружения: включён только при значении "true"
+func envBool(name string) bool {
+	return os.Getenv(name) == "true"
+}
+
+// Middleware для браузерных клиентов: добавляет CORS-заголовки ко всем
+// маршрутам /api/ и сам отвечает на preflight-запросы OPTIONS
+func withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		h := w.Header()
+		h.Set("Access-Control-Allow-Origin", corsOrigin)
+		h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-None-Match, If-Match, Idempotency-Key")
+		h.Set("Access-Control-Expose-Headers", "ETag, Idempotent-Replayed")
+		if corsOrigin != "*" {
+			h.Add("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
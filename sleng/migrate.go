@@ -0,0 +1,126 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ————————————————————————
+//         Миграции формата данных
+// ————————————————————————
+
+// Шаг миграции: приводит данные к версии version. Шаги должны быть безопасны
+// для повторного запуска — в файлах старых версий поле version бывало неточным.
+type migration struct {
+	version     string
+	description string
+	apply       func(*SlangData)
+}
+
+// Миграции по возрастанию версии. Изменение формата данных — новый шаг в конце
+// списка; schemaVersion берётся из последнего шага.
+var migrations = []migration{
+	{"1.1", "постоянные ID записей", migrateEntryIDs},
+	{"1.2", "список пользователей и владельцы записей", migrateOwners},
+	{"1.3", "администратор", migrateAdmin},
+	{"1.4", "каноническая форма слов", migrateCanonicalWords},
+}
+
+// Версия формата, которую пишет эта программа
+var schemaVersion = migrations[len(migrations)-1].version
+
+// Приведение данных к schemaVersion. Возвращает true, если данные изменились
+// и их нужно сохранить (в том числе только поле version).
+func migrateSlangData(slangData *SlangData) bool {
+	// Пароль без префикса bcrypt будет захеширован при следующем успешном входе.
+	// Это не изменение формата, а пометка в памяти, поэтому делается всегда.
+	defer markLegacyPasswords(slangData)
+
+	current := parseSchemaVersion(slangData.Version)
+	if compareVersions(current, parseSchemaVersion(schemaVersion)) >= 0 {
+		// Данные той же или более новой версии (от более новой программы) не трогаем
+		return false
+	}
+	for _, m := range migrations {
+		if compareVersions(current, parseSchemaVersion(m.version)) < 0 {
+			m.apply(slangData)
+			logger.Debug("миграция данных", "version", m.version, "description", m.description)
+		}
+	}
+	slangData.Version = schemaVersion
+	return true
+}
+
+// Версия "1.2" как [1, 2]. Пустая или нечитаемая версия — самая первая, 1.0.
+func parseSchemaVersion(version string) [2]int {
+	major, minor, _ := strings.Cut(version, ".")
+	ma, err1 := strconv.Atoi(major)
+	mi, err2 := strconv.Atoi(minor)
+	if err1 != nil || err2 != nil {
+		return [2]int{1, 0}
+	}
+	return [2]int{ma, mi}
+}
+
+func compareVersions(a, b [2]int) int {
+	if a[0] != b[0] {
+		return a[0] - b[0]
+	}
+	return a[1] - b[1]
+}
+
+// 1.1: записям из старых файлов выдаются ID
+func migrateEntryIDs(slangData *SlangData) {
+	for i := range slangData.Entries {
+		if slangData.Entries[i].ID == "" {
+			slangData.Entries[i].ID = newEntryID()
+		}
+	}
+}
+
+// 1.2: единственный пользователь старого формата переносится в список пользователей.
+// Записи без владельца (из общего словаря старых версий) достаются ему, а если
+// его нет — первому зарегистрированному.
+func migrateOwners(slangData *SlangData) {
+	legacyOwner := ""
+	if len(slangData.Users) > 0 {
+		legacyOwner = slangData.Users[0].Username
+	}
+	if slangData.LegacyUser != nil {
+		legacyOwner = normalizeUsername(slangData.LegacyUser.Username)
+		if slangData.LegacyUser.Username != "" && findUser(slangData, slangData.LegacyUser.Username) == nil {
+			legacy := *slangData.LegacyUser
+			legacy.Username = normalizeUsername(legacy.Username)
+			slangData.Users = append(slangData.Users, legacy)
+		}
+		slangData.LegacyUser = nil
+	}
+	for i := range slangData.Entries {
+		if slangData.Entries[i].Owner == "" && legacyOwner != "" {
+			slangData.Entries[i].Owner = legacyOwner
+		}
+	}
+}
+
+// 1.3: если администратора нет, им становится первый зарегистрированный пользователь,
+// как и при регистрации в пустом словаре
+func migrateAdmin(slangData *SlangData) {
+	if len(slangData.Users) == 0 || slices.ContainsFunc(slangData.Users, func(u User) bool { return u.IsAdmin }) {
+		return
+	}
+	slangData.Users[0].IsAdmin = true
+}
+
+func markLegacyPasswords(slangData *SlangData) {
+	for i := range slangData.Users {
+		if !isBcryptHash(slangData.Users[i].Password) {
+			slangData.Users[i].legacyPassword = true
+		}
+	}
+}
+
+// 1.4: записям из старых файлов заполняется каноническая форма слова
+func migrateCanonicalWords(slangData *SlangData) {
+	setCanonicalWords(slangData.Entries)
+}
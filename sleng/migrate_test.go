@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Файл версии 1.0 поднимается до текущей версии без потери данных
+func TestMigrateV1Fixture(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "slang-v1.0.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "slang.json")
+	if err := os.WriteFile(path, fixture, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewFileStore(path)
+
+	slangData := loadSlangData(store)
+	if slangData.Version != schemaVersion {
+		t.Errorf("version = %q, want %q", slangData.Version, schemaVersion)
+	}
+	if slangData.LegacyUser != nil || len(slangData.Users) != 1 {
+		t.Fatalf("пользователи: %+v, старый формат: %+v", slangData.Users, slangData.LegacyUser)
+	}
+	// Единственный пользователь становится администратором (1.3)
+	if user := slangData.Users[0]; user.Username != "admin" || user.Password != "1234" || !user.legacyPassword || !user.IsAdmin {
+		t.Errorf("пользователь: %+v", user)
+	}
+
+	if len(slangData.Entries) != 2 {
+		t.Fatalf("записей %d, want 2", len(slangData.Entries))
+	}
+	crush := slangData.Entries[0]
+	if crush.Word != "краш" || crush.Example != "Он мой краш уже полгода" || crush.Origin != "От английского crush - влюбленность" ||
+		len(crush.Synonyms) != 2 || crush.Synonyms[1] != "влюбленность" {
+		t.Errorf("содержимое записи изменилось: %+v", crush)
+	}
+	ids := map[string]bool{}
+	for _, entry := range slangData.Entries {
+		if entry.ID == "" || entry.Owner != "admin" {
+			t.Errorf("запись без ID или владельца: %+v", entry)
+		}
+		ids[entry.ID] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("ID не уникальны: %v", ids)
+	}
+
+	// Результат миграции сохранён: повторная загрузка ничего не меняет
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != schemaVersion || saved.Entries[0].ID != crush.ID {
+		t.Errorf("после сохранения: version %q, ID %q (было %q)", saved.Version, saved.Entries[0].ID, crush.ID)
+	}
+	if migrateSlangData(&saved) {
+		t.Error("данные текущей версии мигрируются повторно")
+	}
+}
+
+func TestMigrateAdmin(t *testing.T) {
+	tests := []struct {
+		name      string
+		users     []User
+		wantAdmin []bool
+	}{
+		{"нет пользователей", nil, nil},
+		{"администратора нет", []User{{Username: "alice"}, {Username: "bob"}}, []bool{true, false}},
+		{"администратор уже есть", []User{{Username: "alice"}, {Username: "bob", IsAdmin: true}}, []bool{false, true}},
+	}
+	for _, tt := range tests {
+		slangData := SlangData{Version: "1.2", Users: tt.users}
+		migrateSlangData(&slangData)
+		for i, user := range slangData.Users {
+			if user.IsAdmin != tt.wantAdmin[i] {
+				t.Errorf("%s: %s IsAdmin = %v, want %v", tt.name, user.Username, user.IsAdmin, tt.wantAdmin[i])
+			}
+		}
+	}
+}
+
+func TestMigrateSlangData(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        SlangData
+		wantChanged bool
+		wantVersion string
+		wantIDs     bool
+		wantCanon   string // CanonicalWord первой записи после миграции
+	}{
+		{
+			name:        "без версии",
+			data:        SlangData{Entries: []SlangEntry{{Word: " Изи "}}},
+			wantChanged: true, wantVersion: schemaVersion, wantIDs: true, wantCanon: "изи",
+		},
+		{
+			name:        "промежуточная версия",
+			data:        SlangData{Version: "1.3", Entries: []SlangEntry{{ID: "1", Word: "Ёлка"}}},
+			wantChanged: true, wantVersion: schemaVersion, wantIDs: true, wantCanon: "елка",
+		},
+		{
+			name:        "текущая версия",
+			data:        SlangData{Version: schemaVersion, Entries: []SlangEntry{{ID: "1", Word: "изи"}}},
+			wantChanged: false, wantVersion: schemaVersion, wantIDs: true,
+		},
+		{
+			// Файл от более новой программы не трогаем и версию не понижаем
+			name:        "более новая версия",
+			data:        SlangData{Version: "99.0", Entries: []SlangEntry{{Word: "изи"}}},
+			wantChanged: false, wantVersion: "99.0", wantIDs: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := migrateSlangData(&tt.data)
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if tt.data.Version != tt.wantVersion {
+				t.Errorf("version = %q, want %q", tt.data.Version, tt.wantVersion)
+			}
+			if hasID := tt.data.Entries[0].ID != ""; hasID != tt.wantIDs {
+				t.Errorf("ID = %q", tt.data.Entries[0].ID)
+			}
+			if canon := tt.data.Entries[0].CanonicalWord; canon != tt.wantCanon {
+				t.Errorf("CanonicalWord = %q, want %q", canon, tt.wantCanon)
+			}
+		})
+	}
+}
@@ -0,0 +1,798 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ————————————————————————
+//         Описание API (OpenAPI 3)
+// ————————————————————————
+
+// Схема JSON, записанная вручную (а не выведенная из Go-типа)
+type jsonSchema map[string]any
+
+// Ответ, который бывает в одном из нескольких видов
+type apiOneOf []any
+
+// Как маршрут относится к токену
+type apiAuth int
+
+const (
+	authNone     apiAuth = iota
+	authOptional         // без токена — общий словарь, с токеном — словарь пользователя
+	authRequired
+)
+
+// Параметр строки запроса. Параметры пути ({index}, {id}, {word}) выводятся из пути.
+type apiParam struct {
+	name        string
+	kind        string // string, integer, boolean
+	description string
+	required    bool
+}
+
+// Описание маршрута. Тело запроса и ответы задаются пустым значением Go-типа
+// (схема строится по его json-тегам) или готовой jsonSchema. Ответ nil — без тела;
+// для кодов ошибок nil означает стандартный конверт Error.
+type apiRoute struct {
+	method, path string
+	tag, summary string
+	auth         apiAuth
+	params       []apiParam
+	headers      []apiParam
+	body         any
+	responses    map[int]any
+}
+
+// Go-типы, которые попадают в components/schemas под своим именем;
+// остальные встраиваются в схему на месте
+var apiSchemaNames = map[reflect.Type]string{
+	reflect.TypeFor[SlangEntry]():      "SlangEntry",
+	reflect.TypeFor[SlangEntryPatch](): "SlangEntryPatch",
+	reflect.TypeFor[errorResponse]():   "Error",
+	reflect.TypeFor[entriesPage]():     "EntriesPage",
+	reflect.TypeFor[importResult]():    "ImportResult",
+	reflect.TypeFor[trashedEntry]():    "TrashedEntry",
+	reflect.TypeFor[historyItem]():     "HistoryItem",
+	reflect.TypeFor[backupInfo]():      "Backup",
+	reflect.TypeFor[entryEvent]():      "EntryEvent",
+	reflect.TypeFor[deletionPreview](): "DeletionPreview",
+}
+
+// Схемы, которым не соответствует отдельный Go-тип
+var (
+	messageSchema = jsonSchema{
+		"type":       "object",
+		"properties": jsonSchema{"message": jsonSchema{"type": "string"}},
+	}
+	userSchema = jsonSchema{
+		"type":     "object",
+		"required": []string{"username", "password"},
+		"properties": jsonSchema{
+			"username": jsonSchema{"type": "string"},
+			"password": jsonSchema{"type": "string", "format": "password", "writeOnly": true},
+		},
+	}
+	// На месте ненайденной записи — null
+	batchSchema = jsonSchema{
+		"type": "object",
+		"properties": jsonSchema{
+			"entries": jsonSchema{"type": "array", "items": jsonSchema{
+				"nullable": true, "allOf": []any{jsonSchema{"$ref": "#/components/schemas/SlangEntry"}},
+			}},
+			"missing": jsonSchema{"type": "array", "items": jsonSchema{"type": "string"}},
+		},
+	}
+	rejectedSchema = jsonSchema{
+		"allOf": []any{
+			jsonSchema{"$ref": "#/components/schemas/Error"},
+			jsonSchema{
+				"type":       "object",
+				"properties": jsonSchema{"reasons": jsonSchema{"type": "array", "items": jsonSchema{"type": "string"}}},
+			},
+		},
+	}
+)
+
+var (
+	prettyParam    = apiParam{"pretty", "boolean", "JSON с отступами", false}
+	sharedParam    = apiParam{"shared", "boolean", "с токеном: общий словарь вместо своего", false}
+	permanentParam = apiParam{"permanent", "boolean", "удалить насовсем, минуя корзину", false}
+	dryRunParam    = apiParam{"dryRun", "boolean", "только показать, что будет удалено, ничего не меняя", false}
+	fieldsParam    = apiParam{"fields", "string", "только эти поля записи через запятую, например word,meaning", false}
+	ifMatchHeader  = apiParam{"If-Match", "string", "ETag записи из GET: изменить, только если запись с тех пор не менялась", false}
+)
+
+// Все маршруты API. Новый маршрут в newRouter нужно описать и здесь:
+// TestOpenAPIRoutes проверяет, что каждое описание ведёт на настоящий маршрут.
+var apiRoutes = []apiRoute{
+	{
+		method: "GET", path: "/api/entries", tag: "entries", auth: authOptional,
+		summary: "Список записей; с limit или offset — страница",
+		params: []apiParam{
+			{"limit", "integer", "размер страницы (по умолчанию 50, не больше 200)", false},
+			{"offset", "integer", "сколько записей пропустить", false},
+			{"since", "string", "только изменённые после момента в RFC 3339", false},
+			{"tag", "string", "только записи с тегом", false},
+			{"author", "string", "только записи, добавленные пользователем", false},
+			{"sort", "string", "word, created, score; с «-» — в обратном порядке", false},
+			{"hasExample", "boolean", "true — только с примером, false — только без него", false},
+			{"hasOrigin", "boolean", "то же для происхождения", false},
+			{"hasSynonyms", "boolean", "то же для синонимов", false},
+			{"hasTags", "boolean", "то же для тегов", false},
+			fieldsParam,
+			sharedParam,
+		},
+		responses: map[int]any{200: apiOneOf{[]SlangEntry{}, entriesPage{}}, 304: nil, 400: nil},
+	},
+	{
+		method: "POST", path: "/api/entries", tag: "entries", auth: authRequired,
+		summary: "Добавить запись",
+		headers: []apiParam{
+			{"Idempotency-Key", "string", "ключ повтора: запрос с тем же ключом и телом вернёт исходный ответ", false},
+		},
+		body:      SlangEntry{},
+		responses: map[int]any{201: messageSchema, 400: nil, 401: nil, 409: nil, 422: rejectedSchema},
+	},
+	{
+		method: "POST", path: "/api/entries/import", tag: "entries", auth: authRequired,
+		summary:   "Импорт массива записей; дубликаты и неверные записи пропускаются",
+		params:    []apiParam{{"source", "string", "json (по умолчанию) или urban — экспорт Urban Dictionary", false}},
+		body:      []SlangEntry{},
+		responses: map[int]any{200: importResult{}, 400: nil, 401: nil},
+	},
+	{
+		method: "POST", path: "/api/entries/delete", tag: "entries", auth: authRequired,
+		summary:   "Удалить несколько записей по ID или словам одним сохранением",
+		params:    []apiParam{permanentParam, dryRunParam},
+		body:      []string{},
+		responses: map[int]any{200: bulkDeleteResult{}, 400: nil, 401: nil},
+	},
+	{
+		method: "GET", path: "/api/entries/export", tag: "entries", auth: authOptional,
+		summary: "Скачать словарь файлом",
+		params: []apiParam{
+			{"format", "string", "json (по умолчанию), csv или markdown", false},
+			{"bom", "boolean", "добавить BOM в CSV для Excel", false},
+			sharedParam,
+		},
+		responses: map[int]any{200: []SlangEntry{}, 400: nil},
+	},
+	{
+		method: "GET", path: "/api/entries/random", tag: "entries", auth: authOptional,
+		summary:   "Случайная запись",
+		params:    []apiParam{{"seed", "integer", "зерно генератора для воспроизводимого результата", false}, sharedParam},
+		responses: map[int]any{200: SlangEntry{}, 400: nil, 404: nil},
+	},
+	{
+		method: "GET", path: "/api/entries/batch", tag: "entries", auth: authOptional,
+		summary: "Несколько записей по ID или номерам, в порядке запроса",
+		params: []apiParam{
+			{"ids", "string", "ID записей через запятую (не больше 100)", false},
+			{"indices", "string", "или номера записей через запятую, как в /api/entries/{index}", false},
+			fieldsParam, sharedParam,
+		},
+		responses: map[int]any{200: batchSchema, 400: nil},
+	},
+	{
+		method: "GET", path: "/api/entries/{index}", tag: "entries", auth: authOptional,
+		summary:   "Запись по номеру",
+		params:    []apiParam{fieldsParam, sharedParam},
+		responses: map[int]any{200: SlangEntry{}, 400: nil, 404: nil},
+	},
+	{
+		method: "PUT", path: "/api/entries/{index}", tag: "entries", auth: authRequired,
+		summary:   "Заменить запись целиком",
+		headers:   []apiParam{ifMatchHeader},
+		body:      SlangEntry{},
+		responses: map[int]any{200: SlangEntry{}, 400: nil, 401: nil, 404: nil, 409: nil, 412: nil, 428: nil},
+	},
+	{
+		method: "PATCH", path: "/api/entries/{index}", tag: "entries", auth: authRequired,
+		summary:   "Изменить отдельные поля записи",
+		headers:   []apiParam{ifMatchHeader},
+		body:      SlangEntryPatch{},
+		responses: map[int]any{200: SlangEntry{}, 400: nil, 401: nil, 404: nil, 409: nil, 412: nil, 428: nil},
+	},
+	{
+		method: "DELETE", path: "/api/entries/{index}", tag: "entries", auth: authRequired,
+		summary:   "Удалить запись (в корзину)",
+		params:    []apiParam{permanentParam, dryRunParam},
+		responses: map[int]any{200: apiOneOf{messageSchema, deletionPreview{}}, 400: nil, 401: nil, 404: nil},
+	},
+	{
+		method: "POST", path: "/api/entries/{index}/vote", tag: "entries", auth: authRequired,
+		summary: "Голос за или против записи",
+		body: jsonSchema{
+			"type":       "object",
+			"required":   []string{"direction"},
+			"properties": jsonSchema{"direction": jsonSchema{"type": "string", "enum": []string{"up", "down"}}},
+		},
+		responses: map[int]any{
+			200: jsonSchema{
+				"type": "object",
+				"properties": jsonSchema{
+					"entry": jsonSchema{"$ref": "#/components/schemas/SlangEntry"},
+					"vote":  jsonSchema{"type": "string", "enum": []string{"up", "down"}},
+				},
+			},
+			400: nil, 401: nil, 404: nil,
+		},
+	},
+	{
+		method: "GET", path: "/api/entries/{index}/history", tag: "entries", auth: authOptional,
+		summary:   "Прежние версии записи",
+		responses: map[int]any{200: []historyItem{}, 400: nil, 404: nil},
+	},
+	{
+		method: "POST", path: "/api/entries/{index}/revert", tag: "entries", auth: authRequired,
+		summary:   "Откатить запись к версии из истории",
+		params:    []apiParam{{"version", "integer", "номер версии из истории", true}},
+		responses: map[int]any{200: SlangEntry{}, 400: nil, 401: nil, 404: nil, 409: nil},
+	},
+	{
+		method: "DELETE", path: "/api/entries/by-word/{word}", tag: "entries", auth: authRequired,
+		summary:   "Удалить запись по слову",
+		params:    []apiParam{permanentParam, dryRunParam},
+		responses: map[int]any{200: apiOneOf{messageSchema, deletionPreview{}}, 401: nil, 404: nil},
+	},
+	{
+		method: "GET", path: "/api/entries/by-word/{word}/synonyms", tag: "synonyms", auth: authOptional,
+		summary: "Синонимы слова: запись, если слово есть в словаре, иначе строка",
+		responses: map[int]any{
+			200: jsonSchema{"type": "array", "items": jsonSchema{"oneOf": []any{
+				jsonSchema{"$ref": "#/components/schemas/SlangEntry"},
+				jsonSchema{"type": "string"},
+			}}},
+			404: nil,
+		},
+	},
+	{
+		method: "GET", path: "/api/entries/by-word/{word}/related", tag: "search", auth: authOptional,
+		summary:   "Похожие слова: общие теги, синонимы и слова значения; сначала самые релевантные",
+		params:    []apiParam{{"limit", "integer", "сколько записей вернуть (по умолчанию 5, не больше 20)", false}, sharedParam},
+		responses: map[int]any{200: []relatedEntry{}, 400: nil, 404: nil},
+	},
+	{
+		method: "GET", path: "/api/entries/synonyms/check", tag: "synonyms", auth: authOptional,
+		summary:   "Односторонние синонимы",
+		responses: map[int]any{200: []synonymIssue{}},
+	},
+	{
+		method: "POST", path: "/api/entries/synonyms/check", tag: "synonyms", auth: authRequired,
+		summary:   "С fix=true — добавить недостающие обратные ссылки",
+		params:    []apiParam{{"fix", "boolean", "исправить и сохранить", false}},
+		responses: map[int]any{200: []synonymIssue{}, 401: nil},
+	},
+	{
+		method: "GET", path: "/api/entries/duplicates", tag: "entries", auth: authRequired,
+		summary:   "Записи своего словаря с одинаковыми или похожими значениями",
+		params:    []apiParam{{"threshold", "number", "порог сходства от 0.5 до 1, по умолчанию 0.85; 1 — только точные совпадения", false}},
+		responses: map[int]any{200: []duplicateGroup{}, 400: nil, 401: nil},
+	},
+	{
+		method: "GET", path: "/api/entry/{id}", tag: "entries", auth: authOptional,
+		summary:   "Запись по постоянному ID",
+		params:    []apiParam{fieldsParam},
+		responses: map[int]any{200: SlangEntry{}, 400: nil, 404: nil},
+	},
+	{
+		method: "DELETE", path: "/api/entry/{id}", tag: "entries", auth: authRequired,
+		summary:   "Удалить запись по постоянному ID",
+		params:    []apiParam{permanentParam, dryRunParam},
+		responses: map[int]any{200: apiOneOf{messageSchema, deletionPreview{}}, 401: nil, 404: nil},
+	},
+	{
+		method: "GET", path: "/api/trash", tag: "trash", auth: authRequired,
+		summary:   "Корзина пользователя",
+		responses: map[int]any{200: []trashedEntry{}, 401: nil},
+	},
+	{
+		method: "POST", path: "/api/trash/{id}/restore", tag: "trash", auth: authRequired,
+		summary:   "Вернуть запись из корзины",
+		responses: map[int]any{200: SlangEntry{}, 401: nil, 404: nil, 409: nil},
+	},
+	{
+		method: "DELETE", path: "/api/trash/{id}", tag: "trash", auth: authRequired,
+		summary:   "Удалить запись из корзины насовсем",
+		responses: map[int]any{200: messageSchema, 401: nil, 404: nil},
+	},
+	{
+		method: "POST", path: "/api/backup", tag: "backups", auth: authRequired,
+		summary:   "Сделать резервную копию (только администратор)",
+		responses: map[int]any{201: backupInfo{}, 401: nil, 403: nil, 404: nil, 501: nil},
+	},
+	{
+		method: "GET", path: "/api/backups", tag: "backups", auth: authRequired,
+		summary:   "Список резервных копий (только администратор)",
+		responses: map[int]any{200: []backupInfo{}, 401: nil, 403: nil, 501: nil},
+	},
+	{
+		method: "POST", path: "/api/restore", tag: "backups", auth: authRequired,
+		summary: "Восстановить словарь из резервной копии (только администратор)",
+		body: jsonSchema{
+			"type":       "object",
+			"required":   []string{"name"},
+			"properties": jsonSchema{"name": jsonSchema{"type": "string"}},
+		},
+		responses: map[int]any{
+			200: jsonSchema{
+				"type": "object",
+				"properties": jsonSchema{
+					"message": jsonSchema{"type": "string"},
+					"entries": jsonSchema{"type": "integer"},
+				},
+			},
+			400: nil, 401: nil, 403: nil, 501: nil,
+		},
+	},
+	{
+		method: "GET", path: "/api/search", tag: "search", auth: authOptional,
+		summary: "Поиск по подстроке, нечёткий поиск или поиск по звучанию слова",
+		params: []apiParam{
+			{"q", "string", "что искать", true},
+			{"fields", "string", "поля через запятую: word, meaning, example", false},
+			{"fuzzy", "boolean", "нечёткий поиск по слову", false},
+			{"max_distance", "integer", "для fuzzy: наибольшее расстояние Левенштейна", false},
+			{"phonetic", "boolean", "поиск по звучанию слова (Soundex, кириллица транслитерируется); нельзя вместе с fuzzy", false},
+			sharedParam,
+		},
+		responses: map[int]any{200: apiOneOf{[]SlangEntry{}, []fuzzyMatch{}}, 400: nil},
+	},
+	{
+		method: "GET", path: "/api/autocomplete", tag: "search", auth: authOptional,
+		summary: "Слова, начинающиеся с префикса",
+		params: []apiParam{
+			{"prefix", "string", "начало слова", false},
+			{"limit", "integer", "сколько подсказок (по умолчанию 10)", false},
+		},
+		responses: map[int]any{200: []string{}, 400: nil},
+	},
+	{
+		method: "GET", path: "/api/word-of-day", tag: "search",
+		summary: "Слово дня из общего словаря",
+		responses: map[int]any{
+			200: jsonSchema{
+				"type": "object",
+				"properties": jsonSchema{
+					"date":  jsonSchema{"type": "string", "format": "date"},
+					"entry": jsonSchema{"$ref": "#/components/schemas/SlangEntry"},
+				},
+			},
+			404: nil,
+		},
+	},
+	{
+		method: "GET", path: "/api/stats", tag: "search", auth: authOptional,
+		summary:   "Статистика словаря",
+		responses: map[int]any{200: dictionaryStats{}},
+	},
+	{
+		method: "GET", path: "/api/tags", tag: "search", auth: authOptional,
+		summary:   "Теги с количеством записей",
+		responses: map[int]any{200: []tagCount{}},
+	},
+	{
+		method: "GET", path: "/api/index", tag: "search", auth: authOptional,
+		summary:   "Алфавитный указатель: первая буква -> количество слов, цифры и символы под «#»",
+		responses: map[int]any{200: map[string]int{}},
+	},
+	{
+		method: "GET", path: "/api/health", tag: "service",
+		summary: "Проверка для балансировщика",
+		responses: map[int]any{
+			200: jsonSchema{
+				"type": "object",
+				"properties": jsonSchema{
+					"status":  jsonSchema{"type": "string"},
+					"entries": jsonSchema{"type": "integer"},
+					"version": jsonSchema{"type": "string"},
+				},
+			},
+			503: jsonSchema{
+				"type": "object",
+				"properties": jsonSchema{
+					"status": jsonSchema{"type": "string"},
+					"error":  jsonSchema{"type": "string"},
+				},
+			},
+		},
+	},
+	{
+		method: "GET", path: "/metrics", tag: "service",
+		summary:   "Метрики в формате Prometheus (text/plain)",
+		responses: map[int]any{200: nil},
+	},
+	{
+		method: "GET", path: "/api/user", tag: "users", auth: authRequired,
+		summary: "Текущий пользователь",
+		responses: map[int]any{
+			200: jsonSchema{
+				"type": "object",
+				"properties": jsonSchema{
+					"username": jsonSchema{"type": "string"},
+					"is_admin": jsonSchema{"type": "boolean"},
+				},
+			},
+			401: nil,
+		},
+	},
+	{
+		method: "POST", path: "/api/user/password", tag: "users", auth: authRequired,
+		summary: "Сменить пароль",
+		body: jsonSchema{
+			"type":     "object",
+			"required": []string{"current_password", "new_password"},
+			"properties": jsonSchema{
+				"current_password": jsonSchema{"type": "string", "format": "password"},
+				"new_password":     jsonSchema{"type": "string", "format": "password"},
+			},
+		},
+		responses: map[int]any{
+			// Прежние токены после смены не действуют: клиент продолжает с новым
+			200: jsonSchema{
+				"type": "object",
+				"properties": jsonSchema{
+					"message":    jsonSchema{"type": "string"},
+					"token":      jsonSchema{"type": "string"},
+					"expires_at": jsonSchema{"type": "string", "format": "date-time"},
+				},
+			},
+			400: nil, 401: nil,
+		},
+	},
+	{
+		method: "POST", path: "/api/user/reset-request", tag: "users",
+		summary: "Запросить сброс пароля: одноразовый токен пишется в лог сервера для передачи пользователю",
+		body: jsonSchema{
+			"type":       "object",
+			"required":   []string{"username"},
+			"properties": jsonSchema{"username": jsonSchema{"type": "string"}},
+		},
+		responses: map[int]any{202: messageSchema, 400: nil, 429: nil},
+	},
+	{
+		method: "POST", path: "/api/user/reset", tag: "users",
+		summary: "Задать новый пароль по токену сброса (токен действует час и только один раз)",
+		body: jsonSchema{
+			"type":     "object",
+			"required": []string{"token", "new_password"},
+			"properties": jsonSchema{
+				"token":        jsonSchema{"type": "string"},
+				"new_password": jsonSchema{"type": "string", "format": "password"},
+			},
+		},
+		responses: map[int]any{200: messageSchema, 400: nil},
+	},
+	{
+		method: "DELETE", path: "/api/user", tag: "users", auth: authRequired,
+		summary: "Удалить аккаунт (нужен пароль)",
+		params:  []apiParam{{"delete_entries", "boolean", "удалить и словарь пользователя", false}},
+		body: jsonSchema{
+			"type":       "object",
+			"required":   []string{"password"},
+			"properties": jsonSchema{"password": jsonSchema{"type": "string", "format": "password"}},
+		},
+		responses: map[int]any{
+			200: jsonSchema{
+				"type": "object",
+				"properties": jsonSchema{
+					"message":         jsonSchema{"type": "string"},
+					"deleted_entries": jsonSchema{"type": "integer"},
+				},
+			},
+			400: nil, 401: nil, 404: nil,
+		},
+	},
+	{
+		method: "GET", path: "/api/admin/users", tag: "admin", auth: authRequired,
+		summary:   "Все пользователи (только администратор)",
+		responses: map[int]any{200: []adminUserInfo{}, 401: nil, 403: nil},
+	},
+	{
+		method: "DELETE", path: "/api/admin/entries/{id}", tag: "admin", auth: authRequired,
+		summary:   "Удалить запись любого пользователя (только администратор)",
+		params:    []apiParam{permanentParam, dryRunParam},
+		responses: map[int]any{200: apiOneOf{messageSchema, deletionPreview{}}, 401: nil, 403: nil, 404: nil},
+	},
+	{
+		method: "POST", path: "/api/register", tag: "users",
+		summary:   "Регистрация",
+		body:      jsonSchema{"$ref": "#/components/schemas/User"},
+		responses: map[int]any{201: messageSchema, 400: nil, 409: nil},
+	},
+	{
+		method: "POST", path: "/api/login", tag: "users",
+		summary: "Вход: выдаёт JWT для заголовка Authorization: Bearer",
+		body:    jsonSchema{"$ref": "#/components/schemas/User"},
+		responses: map[int]any{
+			200: jsonSchema{
+				"type": "object",
+				"properties": jsonSchema{
+					"message":    jsonSchema{"type": "string"},
+					"username":   jsonSchema{"type": "string"},
+					"token":      jsonSchema{"type": "string"},
+					"expires_at": jsonSchema{"type": "string", "format": "date-time"},
+				},
+			},
+			400: nil, 401: nil, 423: nil, 429: nil,
+		},
+	},
+	{
+		method: "POST", path: "/api/logout", tag: "users", auth: authRequired,
+		summary:   "Отозвать токен",
+		responses: map[int]any{200: messageSchema, 401: nil},
+	},
+	{
+		method: "GET", path: "/api/ws", tag: "events",
+		summary:   "WebSocket с событиями EntryEvent; токен — в Authorization или ?token=",
+		params:    []apiParam{{"token", "string", "JWT, если заголовок не передать", false}},
+		responses: map[int]any{101: nil, 401: nil},
+	},
+	{
+		method: "GET", path: "/api/events", tag: "events",
+		summary:   "Server-Sent Events с событиями EntryEvent; заголовок Last-Event-ID продолжает поток",
+		params:    []apiParam{{"token", "string", "JWT, если заголовок не передать", false}},
+		responses: map[int]any{200: entryEvent{}, 400: nil, 401: nil},
+	},
+	{
+		method: "GET", path: "/api/openapi.json", tag: "service",
+		summary:   "Это описание API",
+		responses: map[int]any{200: jsonSchema{"type": "object"}},
+	},
+	{
+		method: "GET", path: "/api/docs", tag: "service",
+		summary:   "Swagger UI",
+		responses: map[int]any{200: nil},
+	},
+}
+
+// Сборщик документа: копит схемы из components по мере обхода маршрутов
+type openAPIBuilder struct {
+	schemas jsonSchema
+}
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// Описание параметров пути: номер записи, постоянный ID или слово
+func pathParamSchema(name string) (jsonSchema, string) {
+	switch name {
+	case "index":
+		return jsonSchema{"type": "integer", "minimum": 1}, "номер записи с 1"
+	case "id":
+		return jsonSchema{"type": "string", "format": "uuid"}, "постоянный ID записи"
+	default:
+		return jsonSchema{"type": "string"}, ""
+	}
+}
+
+// Документ OpenAPI 3 по списку apiRoutes
+func buildOpenAPISpec() jsonSchema {
+	b := &openAPIBuilder{schemas: jsonSchema{"User": userSchema}}
+	paths := jsonSchema{}
+	for _, route := range apiRoutes {
+		item, _ := paths[route.path].(jsonSchema)
+		if item == nil {
+			item = jsonSchema{}
+			paths[route.path] = item
+		}
+		item[strings.ToLower(route.method)] = b.operation(route)
+	}
+	b.schemaOf(reflect.TypeFor[errorResponse]())
+
+	return jsonSchema{
+		"openapi": "3.0.3",
+		"info": jsonSchema{
+			"title":       "Sleng API",
+			"version":     "1.0.0",
+			"description": "Словарь сленга. Ошибки приходят в виде Error; язык сообщений выбирается заголовком Accept-Language (ru, en).",
+		},
+		"paths": paths,
+		"components": jsonSchema{
+			"schemas": b.schemas,
+			"securitySchemes": jsonSchema{
+				"bearerAuth": jsonSchema{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+func (b *openAPIBuilder) operation(route apiRoute) jsonSchema {
+	op := jsonSchema{
+		"summary":     route.summary,
+		"tags":        []string{route.tag},
+		"operationId": operationID(route),
+	}
+
+	params := []any{}
+	for _, match := range pathParam.FindAllStringSubmatch(route.path, -1) {
+		schema, description := pathParamSchema(match[1])
+		params = append(params, jsonSchema{
+			"name": match[1], "in": "path", "required": true,
+			"schema": schema, "description": description,
+		})
+	}
+	for _, p := range append(route.params, prettyParam) {
+		params = append(params, jsonSchema{
+			"name": p.name, "in": "query", "required": p.required,
+			"schema": jsonSchema{"type": p.kind}, "description": p.description,
+		})
+	}
+	for _, p := range route.headers {
+		params = append(params, jsonSchema{
+			"name": p.name, "in": "header", "required": p.required,
+			"schema": jsonSchema{"type": p.kind}, "description": p.description,
+		})
+	}
+	op["parameters"] = params
+
+	if route.body != nil {
+		op["requestBody"] = jsonSchema{
+			"required": true,
+			"content":  jsonSchema{"application/json": jsonSchema{"schema": b.schema(route.body)}},
+		}
+	}
+
+	switch route.auth {
+	case authRequired:
+		op["security"] = []any{jsonSchema{"bearerAuth": []string{}}}
+	case authOptional:
+		op["security"] = []any{jsonSchema{}, jsonSchema{"bearerAuth": []string{}}}
+	}
+
+	responses := jsonSchema{}
+	for code, payload := range route.responses {
+		response := jsonSchema{"description": http.StatusText(code)}
+		if payload == nil && code >= 400 {
+			payload = errorResponse{}
+		}
+		if payload != nil {
+			contentType := "application/json"
+			if route.path == "/api/events" && code == http.StatusOK {
+				contentType = "text/event-stream"
+			}
+			response["content"] = jsonSchema{contentType: jsonSchema{"schema": b.schema(payload)}}
+		}
+		responses[strconv.Itoa(code)] = response
+	}
+	// Тело любого запроса — JSON (иначе 415) не больше SLENG_MAX_BODY_BYTES (иначе 413)
+	if route.body != nil {
+		for _, code := range []int{http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType} {
+			responses[strconv.Itoa(code)] = jsonSchema{
+				"description": http.StatusText(code),
+				"content":     jsonSchema{"application/json": jsonSchema{"schema": b.schema(errorResponse{})}},
+			}
+		}
+	}
+	op["responses"] = responses
+	return op
+}
+
+// Имя операции из метода и пути: GET /api/entries/{index} -> getEntriesByIndex
+func operationID(route apiRoute) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(route.method))
+	for _, part := range strings.FieldsFunc(strings.TrimPrefix(route.path, "/api"), func(r rune) bool {
+		return r == '/' || r == '-' || r == '.'
+	}) {
+		if strings.HasPrefix(part, "{") {
+			id.WriteString("By")
+			part = strings.Trim(part, "{}")
+		}
+		id.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return id.String()
+}
+
+func (b *openAPIBuilder) schema(v any) jsonSchema {
+	switch v := v.(type) {
+	case jsonSchema:
+		return v
+	case apiOneOf:
+		variants := make([]any, len(v))
+		for i, variant := range v {
+			variants[i] = b.schema(variant)
+		}
+		return jsonSchema{"oneOf": variants}
+	}
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+// Схема по Go-типу: поля и их имена берутся из json-тегов, как у encoding/json
+func (b *openAPIBuilder) schemaOf(t reflect.Type) jsonSchema {
+	if t == reflect.TypeFor[time.Time]() {
+		return jsonSchema{"type": "string", "format": "date-time"}
+	}
+	if name, ok := apiSchemaNames[t]; ok {
+		if _, done := b.schemas[name]; !done {
+			b.schemas[name] = jsonSchema{} // место занято до обхода полей — на случай рекурсии
+			b.schemas[name] = b.structSchema(t)
+		}
+		return jsonSchema{"$ref": "#/components/schemas/" + name}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schemaOf(t.Elem())
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return jsonSchema{"type": "array", "items": b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return jsonSchema{"type": "object", "additionalProperties": b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return b.structSchema(t)
+	default:
+		return jsonSchema{}
+	}
+}
+
+func (b *openAPIBuilder) structSchema(t reflect.Type) jsonSchema {
+	properties := jsonSchema{}
+	b.addFields(t, properties)
+	return jsonSchema{"type": "object", "properties": properties}
+}
+
+// Поля встроенных структур (fuzzyMatch, trashedEntry, historyItem) поднимаются
+// на уровень внешней, как при кодировании в JSON
+func (b *openAPIBuilder) addFields(t reflect.Type, properties jsonSchema) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.addFields(field.Type, properties)
+			continue
+		}
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schemaOf(field.Type)
+	}
+}
+
+// GET /api/openapi.json — описание API в формате OpenAPI 3
+func handleOpenAPI() http.HandlerFunc {
+	spec := buildOpenAPISpec()
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, r, http.StatusOK, spec)
+	}
+}
+
+// Swagger UI с CDN; описание берётся с /api/openapi.json того же сервера
+const swaggerPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>Sleng API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({url: "/api/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+// GET /api/docs — страница Swagger UI
+func handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerPage))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Каждое описание в apiRoutes ведёт на маршрут с тем же шаблоном
+func TestOpenAPIRoutes(t *testing.T) {
+	mux := newRouter(NewFileStore(filepath.Join(t.TempDir(), "slang.json")))
+	seen := map[string]bool{}
+	for _, route := range apiRoutes {
+		pattern := route.method + " " + route.path
+		if seen[pattern] {
+			t.Errorf("%s описан дважды", pattern)
+		}
+		seen[pattern] = true
+
+		path := strings.NewReplacer("{index}", "1", "{id}", "abc", "{word}", "краш").Replace(route.path)
+		_, got := mux.Handler(httptest.NewRequest(route.method, path, nil))
+		if got != pattern {
+			t.Errorf("%s: запрос попадает в %q", pattern, got)
+		}
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	srv, _ := newTestServer(t)
+	resp := doRequest(t, srv, "GET", "/api/openapi.json", "", "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d", resp.status)
+	}
+	var spec struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(resp.body, &spec); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("openapi = %q", spec.OpenAPI)
+	}
+	if _, ok := spec.Paths["/api/entries/{index}"]["patch"]; !ok {
+		t.Error("нет PATCH /api/entries/{index}")
+	}
+	for _, name := range []string{"SlangEntry", "User", "Error"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("нет схемы %s", name)
+		}
+	}
+
+	// Все ссылки $ref ведут на существующие схемы
+	for _, ref := range strings.Split(string(resp.body), `"$ref":"#/components/schemas/`)[1:] {
+		name, _, _ := strings.Cut(ref, `"`)
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("ссылка на несуществующую схему %s", name)
+		}
+	}
+
+	docs := doRequest(t, srv, "GET", "/api/docs", "", "")
+	if docs.status != http.StatusOK || !strings.Contains(string(docs.body), "/api/openapi.json") {
+		t.Errorf("/api/docs: %d", docs.status)
+	}
+}
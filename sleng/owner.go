@@ -0,0 +1,81 @@
+package main
+
+import "net/http"
+
+// ————————————————————————
+//         Словари пользователей
+// ————————————————————————
+
+// Номера (в общем срезе) записей пользователя в порядке добавления
+func ownEntries(entries []SlangEntry, owner string) []int {
+	var indexes []int
+	for i, e := range entries {
+		if e.Owner == owner {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// Номера записей общего словаря: все слова всех пользователей, кроме личных
+func sharedEntries(entries []SlangEntry) []int {
+	var indexes []int
+	for i, e := range entries {
+		if !e.Private {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// Какие записи видит запрос: с токеном — словарь пользователя,
+// без токена или с ?shared=true — общий словарь
+func visibleIndexes(r *http.Request, entries []SlangEntry) []int {
+	username := usernameFromContext(r.Context())
+	if username == "" || r.URL.Query().Get("shared") == "true" {
+		return sharedEntries(entries)
+	}
+	return ownEntries(entries, username)
+}
+
+// Записи по номерам
+func pickEntries(entries []SlangEntry, indexes []int) []SlangEntry {
+	picked := make([]SlangEntry, 0, len(indexes))
+	for _, i := range indexes {
+		picked = append(picked, entries[i])
+	}
+	return picked
+}
+
+// Записи, которые видит запрос (см. visibleIndexes)
+func visibleEntries(r *http.Request, entries []SlangEntry) []SlangEntry {
+	return pickEntries(entries, visibleIndexes(r, entries))
+}
+
+// Может ли пользователь (или аноним, если username пуст) видеть запись
+func canSee(entry SlangEntry, username string) bool {
+	return !entry.Private || entry.Owner == username
+}
+
+// Есть ли слово в словаре пользователя; запись с номером skip (с 0) не учитывается.
+// У разных пользователей одно и то же слово может быть в словаре независимо.
+func ownerWordExists(entries []SlangEntry, owner, word string, skip int) bool {
+	word = normalizeWord(word)
+	for i, e := range entries {
+		if i != skip && e.Owner == owner && e.canonical() == word {
+			return true
+		}
+	}
+	return false
+}
+
+// Номер записи (с 0) с указанным словом в словаре пользователя или -1
+func findOwnEntryIndex(entries []SlangEntry, owner, word string) int {
+	word = normalizeWord(word)
+	for i, e := range entries {
+		if e.Owner == owner && e.canonical() == word {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// ————————————————————————
+//         Поиск по звучанию (?phonetic=true)
+// ————————————————————————
+
+// Кириллица переводится в латиницу, а уже латиница кодируется по правилам
+// Soundex. Транслитерация упрощённая (ш -> sh, ж -> zh, ь и ъ пропадают), поэтому
+// «краш» и «crush», «изи» и «easy» получают одинаковые коды.
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Группы согласных Soundex: похоже звучащие буквы получают одну цифру
+var soundexDigits = map[rune]byte{
+	'b': '1', 'f': '1', 'p': '1', 'v': '1',
+	'c': '2', 'g': '2', 'j': '2', 'k': '2', 'q': '2', 's': '2', 'x': '2', 'z': '2',
+	'd': '3', 't': '3',
+	'l': '4',
+	'm': '5', 'n': '5',
+	'r': '6',
+}
+
+// Длина кода: как и в Soundex, различаются только первые звуки слова
+const phoneticCodeLength = 4
+
+// Фонетический код слова. В отличие от классического Soundex первая буква
+// тоже заменяется цифрой своей группы, а гласная в начале — нулём: иначе
+// «краш» (K…) и «crush» (C…) не совпали бы из-за одной буквы.
+// Гласные разделяют одинаковые согласные, h и w — нет; прочие символы пропускаются.
+// Пустая строка — в слове нет латинских или кириллических букв.
+func phoneticCode(word string) string {
+	code := make([]byte, 0, phoneticCodeLength)
+	var last byte
+	for i, r := range transliterate(normalizeWord(word)) {
+		digit, consonant := soundexDigits[r]
+		switch {
+		case consonant:
+			if digit != last {
+				code = append(code, digit)
+			}
+			last = digit
+		case strings.ContainsRune("aeiouy", r):
+			if i == 0 {
+				code = append(code, '0')
+			}
+			last = 0
+		case r == 'h' || r == 'w':
+			// Не звучат и не разделяют согласные
+		default:
+			last = 0
+		}
+		if len(code) == phoneticCodeLength {
+			break
+		}
+	}
+	return string(code)
+}
+
+// Записи, слово которых звучит так же, как запрос (совпадают фонетические коды),
+// по алфавиту. Запрос без букв ничего не находит.
+func phoneticSearchEntries(entries []SlangEntry, query string) []SlangEntry {
+	results := []SlangEntry{}
+	code := phoneticCode(query)
+	if code == "" {
+		return results
+	}
+	for _, entry := range entries {
+		if phoneticCode(entry.Word) == code {
+			results = append(results, entry)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return normalizeWord(results[i].Word) < normalizeWord(results[j].Word)
+	})
+	return results
+}
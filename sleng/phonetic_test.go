@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestPhoneticCode(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"краш", "crush", true},
+		{"изи", "easy", true},
+		{"вайб", "vibe", true},
+		{"кринж", "cringe", true},
+		{"Кринж", "кринж", true},
+		{"краш", "кринж", false},
+		{"изи", "вайб", false},
+	}
+	for _, tt := range tests {
+		a, b := phoneticCode(tt.a), phoneticCode(tt.b)
+		if (a == b) != tt.same {
+			t.Errorf("phoneticCode(%q) = %q, phoneticCode(%q) = %q, совпадение ожидалось: %v", tt.a, a, tt.b, b, tt.same)
+		}
+	}
+	if code := phoneticCode("!!!"); code != "" {
+		t.Errorf("код слова без букв = %q", code)
+	}
+	if code := phoneticCode("абракадабра"); len(code) != phoneticCodeLength {
+		t.Errorf("код длинного слова = %q", code)
+	}
+}
+
+func TestPhoneticSearch(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии"},
+		SlangEntry{Word: "crush", Meaning: "то же по-английски"},
+		SlangEntry{Word: "изи", Meaning: "легко"},
+	)
+
+	resp := doRequest(t, srv, "GET", "/api/search?q=crash&phonetic=true", token, "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	var entries []SlangEntry
+	if err := json.Unmarshal(resp.body, &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Word != "crush" || entries[1].Word != "краш" {
+		t.Errorf("найдено: %+v", entries)
+	}
+
+	expectError(t, doRequest(t, srv, "GET", "/api/search?q=краш&phonetic=true&fuzzy=true", token, ""), http.StatusBadRequest, errCodeInvalidParameter)
+}
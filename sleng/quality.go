@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Проверка качества новой записи. Возвращает причины отказа (переводимые ошибки);
+// пустой список — запись принята.
+type EntryValidator interface {
+	Validate(entry SlangEntry) []error
+}
+
+// Проверки, через которые проходят новые записи (POST /api/entries и импорт).
+// По умолчанию — встроенные без списка запрещённых слов; main настраивает список
+// флагом -validators.
+var entryValidators = []EntryValidator{meaningValidator{minLetters: 3}, capsValidator{minLetters: 10}}
+
+// Все причины отказа от всех проверок
+func validateNewEntry(entry SlangEntry) []error {
+	var reasons []error
+	for _, v := range entryValidators {
+		reasons = append(reasons, v.Validate(entry)...)
+	}
+	return reasons
+}
+
+// Значение «для галочки»: почти без букв или просто повторяет слово
+type meaningValidator struct {
+	minLetters int
+}
+
+func (v meaningValidator) Validate(entry SlangEntry) []error {
+	if countLetters(entry.Meaning) < v.minLetters {
+		return []error{newMsgError(msgMeaningTooShort, v.minLetters)}
+	}
+	if sameWord(entry.Meaning, entry.Word) {
+		return []error{newMsgError(msgMeaningRepeatsWord)}
+	}
+	return nil
+}
+
+// Значение, написанное одними заглавными (КАПСОМ). Короткие аббревиатуры
+// вроде «ИМХО» не трогаем — проверяется только текст длиннее minLetters букв.
+type capsValidator struct {
+	minLetters int
+}
+
+func (v capsValidator) Validate(entry SlangEntry) []error {
+	if countLetters(entry.Meaning) >= v.minLetters && strings.ToUpper(entry.Meaning) == entry.Meaning {
+		return []error{newMsgError(msgMeaningAllCaps)}
+	}
+	return nil
+}
+
+func countLetters(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// Запрещённые слова из файла: по одному в строке, строки с # — комментарии
+type bannedWordsValidator struct {
+	words map[string]bool
+}
+
+func loadBannedWords(path string) (bannedWordsValidator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return bannedWordsValidator{}, err
+	}
+	defer f.Close()
+
+	v := bannedWordsValidator{words: make(map[string]bool)}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		v.words[normalizeWord(line)] = true
+	}
+	return v, scanner.Err()
+}
+
+func (v bannedWordsValidator) Validate(entry SlangEntry) []error {
+	for _, text := range []string{entry.Word, entry.Meaning, entry.Example, entry.Origin} {
+		// Сравниваем отдельные слова, чтобы запрещённое слово не находилось внутри обычного
+		words := strings.FieldsFunc(text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+		})
+		for _, word := range words {
+			if v.words[normalizeWord(word)] {
+				return []error{newMsgError(msgBannedWords)}
+			}
+		}
+	}
+	return nil
+}
+
+// Список проверок по настройке: имена через запятую (quality, caps, banned);
+// пустая строка или none отключает проверки
+func loadEntryValidators(names, bannedWordsFile string) ([]EntryValidator, error) {
+	var validators []EntryValidator
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.TrimSpace(strings.ToLower(name)); name {
+		case "", "none":
+		case "quality":
+			validators = append(validators, meaningValidator{minLetters: 3})
+		case "caps":
+			validators = append(validators, capsValidator{minLetters: 10})
+		case "banned":
+			// Без файла проверять нечего
+			if bannedWordsFile == "" {
+				continue
+			}
+			banned, err := loadBannedWords(bannedWordsFile)
+			if err != nil {
+				return nil, fmt.Errorf("не удалось прочитать список запрещённых слов: %w", err)
+			}
+			validators = append(validators, banned)
+		default:
+			return nil, fmt.Errorf("неизвестная проверка записей: %s", name)
+		}
+	}
+	return validators, nil
+}
+
+// Причины отказа текстом на языке l
+func reasonTexts(l lang, reasons []error) []string {
+	texts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		texts[i] = errorText(l, reason)
+	}
+	return texts
+}
+
+// Ответ 422 с причинами отказа
+func respondRejected(w http.ResponseWriter, r *http.Request, reasons []error) {
+	respondJSON(w, r, http.StatusUnprocessableEntity, map[string]any{
+		"error":     tr(r, msgEntryRejected),
+		"code":      http.StatusUnprocessableEntity,
+		"errorCode": errCodeEntryRejected,
+		"reasons":   reasonTexts(requestLang(r), reasons),
+	})
+}
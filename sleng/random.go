@@ -0,0 +1,76 @@
+package main
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// GET /api/entries/random[?seed=N]
+// Генератор math/rand/v2 инициализируется случайно при каждом запуске;
+// параметр seed нужен для воспроизводимого результата в тестах.
+func handleRandomEntry(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rng := rand.IntN
+		if raw := r.URL.Query().Get("seed"); raw != "" {
+			seed, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidSeed))
+				return
+			}
+			rng = rand.New(rand.NewPCG(seed, seed)).IntN
+		}
+
+		entries := visibleEntries(r, loadSlangData(s).Entries)
+		if len(entries) == 0 {
+			respondError(w, r, http.StatusNotFound, errCodeNotFound, tr(r, msgDictionaryEmpty))
+			return
+		}
+		respondJSON(w, r, http.StatusOK, entries[rng(len(entries))])
+	}
+}
+
+// Часовой пояс, в котором меняется «слово дня» (переменная SLENG_TIMEZONE, по умолчанию UTC)
+var wordOfDayLocation = loadLocation(os.Getenv("SLENG_TIMEZONE"))
+
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		logger.Warn("неизвестный часовой пояс, используется UTC", "timezone", name)
+		return time.UTC
+	}
+	return loc
+}
+
+// Номер слова дня: хеш даты по модулю количества записей, поэтому
+// до полуночи все клиенты получают одно и то же слово
+func wordOfDayIndex(date string, count int) int {
+	h := fnv.New64a()
+	h.Write([]byte(date))
+	return int(h.Sum64() % uint64(count))
+}
+
+// GET /api/word-of-day
+func handleWordOfDay(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Слово дня одно на всех, поэтому выбирается из общего словаря
+		slangData := loadSlangData(s)
+		entries := pickEntries(slangData.Entries, sharedEntries(slangData.Entries))
+		if len(entries) == 0 {
+			respondError(w, r, http.StatusNotFound, errCodeNotFound, tr(r, msgDictionaryEmpty))
+			return
+		}
+
+		date := time.Now().In(wordOfDayLocation).Format(time.DateOnly)
+		respondJSON(w, r, http.StatusOK, map[string]interface{}{
+			"date":  date,
+			"entry": entries[wordOfDayIndex(date, len(entries))],
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Ограничитель частоты запросов по IP-адресу клиента
+type ipRateLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*ipLimiter
+	limit    rate.Limit
+	burst    int
+}
+
+type ipLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Как и в консоли, где даётся 3 попытки, даём несколько попыток входа
+// подряд, после чего — одну попытку раз в 12 секунд (5 в минуту)
+var loginLimiter = newIPRateLimiter(rate.Every(12*time.Second), 5, time.Minute)
+
+// Создание ограничителя; раз в cleanupEvery из памяти удаляются адреса,
+// с которых давно не было запросов
+func newIPRateLimiter(limit rate.Limit, burst int, cleanupEvery time.Duration) *ipRateLimiter {
+	l := &ipRateLimiter{
+		limiters: make(map[string]*ipLimiter),
+		limit:    limit,
+		burst:    burst,
+	}
+	go func() {
+		for range time.Tick(cleanupEvery) {
+			l.cleanup(3 * cleanupEvery)
+		}
+	}()
+	return l
+}
+
+func (l *ipRateLimiter) get(ip string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.limiters[ip]
+	if !ok {
+		entry = &ipLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[ip] = entry
+	}
+	entry.lastSeen = time.Now()
+	return entry.limiter
+}
+
+func (l *ipRateLimiter) cleanup(maxIdle time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ip, entry := range l.limiters {
+		if time.Since(entry.lastSeen) > maxIdle {
+			delete(l.limiters, ip)
+		}
+	}
+}
+
+// IP-адрес клиента без порта
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Middleware: при превышении лимита отвечает 429 с заголовком Retry-After
+func (l *ipRateLimiter) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reservation := l.get(clientIP(r)).Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			respondError(w, r, http.StatusTooManyRequests, errCodeRateLimited, tr(r, msgTooManyLogins))
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ————————————————————————
+//         Похожие слова
+// ————————————————————————
+
+const (
+	defaultRelatedLimit = 5
+	maxRelatedLimit     = 20
+)
+
+// Вклад признаков в релевантность: прямая ссылка через синонимы важнее
+// общего тега, общий тег — важнее частично похожего значения
+const (
+	relatedSynonymLink   = 5.0 // одно слово указано синонимом другого
+	relatedSharedSynonym = 2.0 // за каждый общий синоним
+	relatedSharedTag     = 3.0 // за каждый общий тег
+	relatedMeaning       = 4.0 // умножается на долю общих слов значения (0..1)
+)
+
+// Похожая запись и её релевантность
+type relatedEntry struct {
+	SlangEntry
+	Relevance float64 `json:"relevance"`
+}
+
+// Значимые слова значения: без коротких служебных («в», «на», «от») и повторов
+func meaningTokens(meaning string) []string {
+	var tokens []string
+	for _, token := range strings.Fields(normalizeMeaning(meaning)) {
+		if utf8.RuneCountInString(token) >= 3 && !slices.Contains(tokens, token) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// Сколько элементов a есть в b (сравнение как у слов словаря)
+func countShared(a, b []string) int {
+	n := 0
+	for _, x := range a {
+		if slices.ContainsFunc(b, func(y string) bool { return sameWord(x, y) }) {
+			n++
+		}
+	}
+	return n
+}
+
+// Релевантность candidate для entry; 0 — ничего общего
+func relatedness(entry, candidate SlangEntry) float64 {
+	score := 0.0
+	linked := func(from, to SlangEntry) bool {
+		return slices.ContainsFunc(from.Synonyms, func(s string) bool { return sameWord(s, to.Word) })
+	}
+	if linked(entry, candidate) || linked(candidate, entry) {
+		score += relatedSynonymLink
+	}
+	score += relatedSharedSynonym * float64(countShared(entry.Synonyms, candidate.Synonyms))
+	score += relatedSharedTag * float64(countShared(entry.Tags, candidate.Tags))
+
+	// Доля общих слов значения (коэффициент Жаккара)
+	a, b := meaningTokens(entry.Meaning), meaningTokens(candidate.Meaning)
+	if shared := countShared(a, b); shared > 0 {
+		score += relatedMeaning * float64(shared) / float64(len(a)+len(b)-shared)
+	}
+	return math.Round(score*100) / 100
+}
+
+// Записи, похожие на entry, от самых релевантных; сама запись (и её повторы
+// с тем же словом) не попадает в результат
+func findRelated(entries []SlangEntry, entry SlangEntry, limit int) []relatedEntry {
+	related := []relatedEntry{}
+	for _, candidate := range entries {
+		if sameWord(candidate.Word, entry.Word) {
+			continue
+		}
+		if relevance := relatedness(entry, candidate); relevance > 0 {
+			related = append(related, relatedEntry{SlangEntry: candidate, Relevance: relevance})
+		}
+	}
+	sort.SliceStable(related, func(i, j int) bool {
+		if related[i].Relevance != related[j].Relevance {
+			return related[i].Relevance > related[j].Relevance
+		}
+		return normalizeWord(related[i].Word) < normalizeWord(related[j].Word)
+	})
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return related
+}
+
+// GET /api/entries/by-word/{word}/related[?limit=5] — как и синонимы, слово
+// под by-word: путь /api/entries/{word}/related пересекался бы с /api/entries/{index}/history
+func handleRelated(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := parseNonNegativeParam(r, "limit", defaultRelatedLimit)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+		limit = min(limit, maxRelatedLimit)
+
+		entries := visibleEntries(r, loadSlangData(s).Entries)
+		i := findEntryIndex(entries, r.PathValue("word"))
+		if i < 0 {
+			respondError(w, r, http.StatusNotFound, errCodeNotFound, tr(r, msgWordNotFound))
+			return
+		}
+		respondJSON(w, r, http.StatusOK, findRelated(entries, entries[i], limit))
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestFindRelated(t *testing.T) {
+	entries := []SlangEntry{
+		{Word: "краш", Meaning: "человек, который очень нравится", Synonyms: []string{"симпа"}, Tags: []string{"отношения"}},
+		{Word: "симпа", Meaning: "симпатия", Tags: []string{"отношения"}},
+		{Word: "бойфренд", Meaning: "парень, с которым встречаются", Tags: []string{"отношения"}},
+		{Word: "вайб", Meaning: "атмосфера, которая нравится"},
+		{Word: "изи", Meaning: "легко"},
+		{Word: "Краш", Meaning: "повтор того же слова"},
+	}
+
+	related := findRelated(entries, entries[0], 10)
+	var words []string
+	for _, r := range related {
+		words = append(words, r.Word)
+	}
+	// симпа: ссылка через синоним и общий тег; бойфренд: только тег;
+	// вайб: общие слова значения; изи и само слово не попадают
+	want := []string{"симпа", "бойфренд", "вайб"}
+	if len(words) != len(want) {
+		t.Fatalf("похожие: %v, want %v", words, want)
+	}
+	for i := range want {
+		if words[i] != want[i] {
+			t.Errorf("похожие: %v, want %v", words, want)
+			break
+		}
+	}
+	if related[0].Relevance != relatedSynonymLink+relatedSharedTag {
+		t.Errorf("релевантность симпы = %v", related[0].Relevance)
+	}
+
+	if got := findRelated(entries, entries[0], 1); len(got) != 1 || got[0].Word != "симпа" {
+		t.Errorf("limit=1: %+v", got)
+	}
+}
+
+func TestHandleRelated(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "объект симпатии", Tags: []string{"отношения"}},
+		SlangEntry{Word: "симпа", Meaning: "симпатия", Tags: []string{"отношения"}},
+	)
+
+	resp := doRequest(t, srv, "GET", "/api/entries/by-word/Краш/related", token, "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	var related []relatedEntry
+	if err := json.Unmarshal(resp.body, &related); err != nil {
+		t.Fatal(err)
+	}
+	if len(related) != 1 || related[0].Word != "симпа" || related[0].Relevance <= 0 {
+		t.Errorf("похожие: %+v", related)
+	}
+
+	expectError(t, doRequest(t, srv, "GET", "/api/entries/by-word/нет-такого/related", token, ""), http.StatusNotFound, errCodeNotFound)
+	expectError(t, doRequest(t, srv, "GET", "/api/entries/by-word/краш/related?limit=-1", token, ""), http.StatusBadRequest, errCodeInvalidParameter)
+}
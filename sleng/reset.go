@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ————————————————————————
+//         Сброс пароля
+// ————————————————————————
+
+// Сколько действует токен сброса пароля
+const resetTokenTTL = time.Hour
+
+// Запросы сброса пишут в лог и заменяют прежний токен, поэтому их немного:
+// три подряд, затем один в минуту. Сам токен угадать нельзя, сброс по нему не ограничен.
+var resetLimiter = newIPRateLimiter(rate.Every(time.Minute), 3, time.Minute)
+
+var (
+	errResetTokenInvalid = newMsgError(msgResetTokenInvalid)
+	errResetTokenExpired = newMsgError(msgResetTokenExpired)
+)
+
+type resetRequest struct {
+	username string
+	expires  time.Time
+}
+
+// Выданные токены сброса пароля. Почты нет, поэтому токен передаёт пользователю
+// администратор: он пишется в лог сервера. Хранится только SHA-256 токена,
+// как и у revokedTokens — в памяти: после перезапуска токен нужно запросить заново.
+type resetTokenStore struct {
+	mu      sync.Mutex
+	pending map[string]resetRequest // хеш токена -> пользователь и срок
+}
+
+var resetTokens = newResetTokenStore(10 * time.Minute)
+
+// Создание хранилища; раз в cleanupEvery из памяти удаляются истёкшие токены
+func newResetTokenStore(cleanupEvery time.Duration) *resetTokenStore {
+	rs := &resetTokenStore{pending: make(map[string]resetRequest)}
+	go func() {
+		for range time.Tick(cleanupEvery) {
+			rs.cleanup()
+		}
+	}()
+	return rs
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Новый одноразовый токен для пользователя; прежний токен этого пользователя перестаёт действовать
+func (rs *resetTokenStore) issue(username string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for hash, req := range rs.pending {
+		if req.username == username {
+			delete(rs.pending, hash)
+		}
+	}
+	rs.pending[hashResetToken(token)] = resetRequest{username: username, expires: time.Now().Add(resetTokenTTL)}
+	return token, nil
+}
+
+// Использование токена: возвращает логин и сразу удаляет токен, поэтому
+// второй раз тот же токен уже не подойдёт
+func (rs *resetTokenStore) redeem(token string) (string, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	hash := hashResetToken(token)
+	req, ok := rs.pending[hash]
+	if !ok {
+		return "", errResetTokenInvalid
+	}
+	delete(rs.pending, hash)
+	if time.Now().After(req.expires) {
+		return "", errResetTokenExpired
+	}
+	return req.username, nil
+}
+
+func (rs *resetTokenStore) cleanup() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := time.Now()
+	for hash, req := range rs.pending {
+		if now.After(req.expires) {
+			delete(rs.pending, hash)
+		}
+	}
+}
+
+// POST /api/user/reset-request — {"username": "..."}. Токен пишется в лог для
+// передачи пользователю; ответ одинаковый, есть такой пользователь или нет.
+func handleResetRequest(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Username string `json:"username"`
+		}
+		if err := readJSON(w, r, &req); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+
+		username := normalizeUsername(req.Username)
+		if _, err := s.GetUser(username); err == nil {
+			token, err := resetTokens.issue(username)
+			if err != nil {
+				respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgSaveFailed))
+				return
+			}
+			logger.Warn("запрошен сброс пароля, передайте токен пользователю", "user", username,
+				"token", token, "expires", time.Now().Add(resetTokenTTL).UTC().Format(time.RFC3339))
+		} else {
+			logger.Info("запрошен сброс пароля несуществующего пользователя", "user", username)
+		}
+		respondJSON(w, r, http.StatusAccepted, map[string]string{"message": tr(r, msgResetRequested)})
+	}
+}
+
+// POST /api/user/reset — {"token": "...", "new_password": "..."}
+func handleResetPassword(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Token       string `json:"token"`
+			NewPassword string `json:"new_password"`
+		}
+		if err := readJSON(w, r, &req); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+		// Пароль проверяется до использования токена: неподходящий пароль не сжигает токен
+		if err := validatePassword(req.NewPassword); err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeValidationFailed, trErr(r, err))
+			return
+		}
+		// Медленный bcrypt — только после проверки токена
+		username, err := resetTokens.redeem(req.Token)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidResetToken, trErr(r, err))
+			return
+		}
+		hash, err := hashPassword(req.NewPassword)
+		if err != nil {
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgPasswordSaveFailed))
+			return
+		}
+		user, err := s.GetUser(username)
+		if errors.Is(err, errUserNotFound) {
+			// Аккаунт удалили, пока токен ждал
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidResetToken, trErr(r, errResetTokenInvalid))
+			return
+		}
+		if err != nil {
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgSaveFailed))
+			return
+		}
+		setPassword(&user, hash)
+		if err := s.SetUser(user); err != nil {
+			respondError(w, r, http.StatusInternalServerError, errCodeInternal, tr(r, msgSaveFailed))
+			return
+		}
+		logger.Info("пароль сброшен по токену", "user", username)
+		respondJSON(w, r, http.StatusOK, map[string]string{"message": tr(r, msgPasswordChanged)})
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPasswordReset(t *testing.T) {
+	srv, store := newTestServer(t)
+	seedUser(t, store, "alice", "1234")
+
+	// Ответ не выдаёт, есть ли пользователь
+	for _, username := range []string{"Alice", "nobody"} {
+		resp := doRequest(t, srv, "POST", "/api/user/reset-request", "", `{"username":"`+username+`"}`)
+		if resp.status != http.StatusAccepted {
+			t.Fatalf("%s: status = %d; body %s", username, resp.status, resp.body)
+		}
+	}
+
+	// Токен попадает только в лог, поэтому в тесте он выдаётся напрямую
+	token, err := resetTokens.issue("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := func(token, password string) string {
+		return `{"token":"` + token + `","new_password":"` + password + `"}`
+	}
+
+	// Неподходящий пароль не расходует токен
+	expectError(t, doRequest(t, srv, "POST", "/api/user/reset", "", body(token, "12")), http.StatusBadRequest, errCodeValidationFailed)
+	expectError(t, doRequest(t, srv, "POST", "/api/user/reset", "", body("nope", "5678")), http.StatusBadRequest, errCodeInvalidResetToken)
+
+	resp := doRequest(t, srv, "POST", "/api/user/reset", "", body(token, "5678"))
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	user, err := store.GetUser("alice")
+	if err != nil || !checkPassword(user, "5678") {
+		t.Errorf("пароль не сменился: %v", err)
+	}
+
+	// Повторно тот же токен не подходит
+	expectError(t, doRequest(t, srv, "POST", "/api/user/reset", "", body(token, "9999")), http.StatusBadRequest, errCodeInvalidResetToken)
+}
+
+func TestResetTokenStore(t *testing.T) {
+	rs := newResetTokenStore(time.Hour)
+
+	first, _ := rs.issue("alice")
+	second, _ := rs.issue("alice")
+	if _, err := rs.redeem(first); err != errResetTokenInvalid {
+		t.Errorf("прежний токен: %v, want errResetTokenInvalid", err)
+	}
+	if username, err := rs.redeem(second); err != nil || username != "alice" {
+		t.Errorf("redeem = %q, %v", username, err)
+	}
+
+	expired, _ := rs.issue("bob")
+	rs.mu.Lock()
+	for hash, req := range rs.pending {
+		req.expires = time.Now().Add(-time.Minute)
+		rs.pending[hash] = req
+	}
+	rs.mu.Unlock()
+	if _, err := rs.redeem(expired); err != errResetTokenExpired {
+		t.Errorf("истёкший токен: %v, want errResetTokenExpired", err)
+	}
+	if _, err := rs.redeem(expired); err != errResetTokenInvalid {
+		t.Errorf("истёкший токен повторно: %v, want errResetTokenInvalid", err)
+	}
+}
+
+// Токен, выданный минуту назад, — заведомо раньше смены пароля в тесте
+func tokenIssuedMinuteAgo(t *testing.T, username string) string {
+	t.Helper()
+	payload, err := json.Marshal(tokenClaims{
+		ID:        newEntryID(),
+		Subject:   username,
+		IssuedAt:  float64(time.Now().Add(-time.Minute).Unix()),
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + signToken(unsigned)
+}
+
+// После смены и после сброса пароля токены, выданные со старым паролем, не принимаются
+func TestPasswordChangeInvalidatesTokens(t *testing.T) {
+	srv, store := newTestServer(t)
+	seedUser(t, store, "alice", "1234")
+
+	old := tokenIssuedMinuteAgo(t, "alice")
+	leaked := tokenIssuedMinuteAgo(t, "alice")
+	if resp := doRequest(t, srv, "GET", "/api/user", leaked, ""); resp.status != http.StatusOK {
+		t.Fatalf("до смены пароля: %d %s", resp.status, resp.body)
+	}
+	// Выдан в ту же секунду, что и смена пароля, но раньше неё
+	sameSecond, _, err := issueToken("alice", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := doRequest(t, srv, "POST", "/api/user/password", old, `{"current_password":"1234","new_password":"5678"}`)
+	if resp.status != http.StatusOK {
+		t.Fatalf("смена пароля: %d %s", resp.status, resp.body)
+	}
+	for _, token := range []string{leaked, old, sameSecond} {
+		expectError(t, doRequest(t, srv, "GET", "/api/user", token, ""), http.StatusUnauthorized, errCodeUnauthorized)
+	}
+	// Ответ на смену пароля содержит новый токен, и клиент не теряет вход
+	if resp := doRequest(t, srv, "GET", "/api/user", resp.field(t, "token"), ""); resp.status != http.StatusOK {
+		t.Errorf("токен из ответа: %d %s", resp.status, resp.body)
+	}
+
+	// Новый вход после смены пароля работает
+	resp = doRequest(t, srv, "POST", "/api/login", "", `{"username":"alice","password":"5678"}`)
+	if resp.status != http.StatusOK {
+		t.Fatalf("вход: %d %s", resp.status, resp.body)
+	}
+	if resp := doRequest(t, srv, "GET", "/api/user", resp.field(t, "token"), ""); resp.status != http.StatusOK {
+		t.Errorf("новый токен: %d %s", resp.status, resp.body)
+	}
+
+	// Сброс пароля отсекает токены так же. Время смены сдвигается в прошлое,
+	// чтобы токен из прошлой проверки был выдан после неё
+	user, _ := store.GetUser("alice")
+	user.PasswordChangedAt = time.Now().Add(-2 * time.Minute)
+	store.SetUser(user)
+	leaked = tokenIssuedMinuteAgo(t, "alice")
+	if resp := doRequest(t, srv, "GET", "/api/user", leaked, ""); resp.status != http.StatusOK {
+		t.Fatalf("до сброса: %d %s", resp.status, resp.body)
+	}
+	reset, err := resetTokens.issue("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp = doRequest(t, srv, "POST", "/api/user/reset", "", `{"token":"`+reset+`","new_password":"9999"}`)
+	if resp.status != http.StatusOK {
+		t.Fatalf("сброс: %d %s", resp.status, resp.body)
+	}
+	expectError(t, doRequest(t, srv, "GET", "/api/user", leaked, ""), http.StatusUnauthorized, errCodeUnauthorized)
+}
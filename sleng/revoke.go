@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Список отозванных токенов (по jti) — для выхода из системы. Запись хранится,
+// пока токен не истечёт сам: после этого он и так не пройдёт проверку.
+type tokenBlocklist struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time // jti -> время истечения токена
+}
+
+var revokedTokens = newTokenBlocklist(10 * time.Minute)
+
+// Создание списка; раз в cleanupEvery из памяти удаляются истёкшие токены
+func newTokenBlocklist(cleanupEvery time.Duration) *tokenBlocklist {
+	b := &tokenBlocklist{revoked: make(map[string]time.Time)}
+	go func() {
+		for range time.Tick(cleanupEvery) {
+			b.cleanup()
+		}
+	}()
+	return b
+}
+
+func (b *tokenBlocklist) revoke(jti string, expires time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.revoked[jti] = expires
+}
+
+func (b *tokenBlocklist) isRevoked(jti string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.revoked[jti]
+	return ok
+}
+
+func (b *tokenBlocklist) cleanup() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for jti, expires := range b.revoked {
+		if now.After(expires) {
+			delete(b.revoked, jti)
+		}
+	}
+}
+
+// POST /api/logout — отзывает токен, с которым пришёл запрос (уже проверенный requireAuth)
+func handleLogout(w http.ResponseWriter, r *http.Request) {
+	claims, err := parseToken(bearerToken(r))
+	if err != nil {
+		respondError(w, r, http.StatusUnauthorized, errCodeUnauthorized, trErr(r, err))
+		return
+	}
+	revokedTokens.revoke(claims.ID, time.Unix(claims.ExpiresAt, 0))
+	respondJSON(w, r, http.StatusOK, map[string]string{"message": tr(r, msgLoggedOut)})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Невидимые символы нулевой ширины, которые попадают в текст при копировании
+// из браузера и мессенджеров и ломают поиск и сравнение слов.
+// ZWJ и ZWNJ не трогаем: они нужны в эмодзи и некоторых письменностях.
+func isZeroWidth(r rune) bool {
+	switch r {
+	case '\u200b', // zero width space
+		'\u2060', // word joiner
+		'\ufeff': // BOM, zero width no-break space
+		return true
+	}
+	return false
+}
+
+// Очистка текста: убирает управляющие символы (кроме обычных пробельных — табуляции
+// и перевода строки) и символы нулевой ширины, обрезает пробелы по краям.
+// Буквы, знаки препинания и эмодзи остаются как есть.
+func sanitizeText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' {
+			return r
+		}
+		if unicode.IsControl(r) || isZeroWidth(r) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}
+
+func sanitizeStrings(values []string) []string {
+	for i := range values {
+		values[i] = sanitizeText(values[i])
+	}
+	return values
+}
+
+// Очистка всех текстовых полей записи перед проверкой и сохранением
+func sanitizeEntry(entry *SlangEntry) {
+	entry.Word = sanitizeText(entry.Word)
+	entry.CanonicalWord = normalizeWord(entry.Word)
+	entry.Meaning = sanitizeText(entry.Meaning)
+	entry.Example = sanitizeText(entry.Example)
+	entry.Origin = sanitizeText(entry.Origin)
+	entry.Synonyms = sanitizeStrings(entry.Synonyms)
+	entry.Tags = sanitizeStrings(entry.Tags)
+}
+
+// То же для частичного обновления: очищаются только переданные поля
+func sanitizePatch(patch *SlangEntryPatch) {
+	for _, field := range []*string{patch.Word, patch.Meaning, patch.Example, patch.Origin} {
+		if field != nil {
+			*field = sanitizeText(*field)
+		}
+	}
+	if patch.Synonyms != nil {
+		*patch.Synonyms = sanitizeStrings(*patch.Synonyms)
+	}
+	if patch.Tags != nil {
+		*patch.Tags = sanitizeStrings(*patch.Tags)
+	}
+}
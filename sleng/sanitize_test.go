@@ -0,0 +1,74 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"краш", "краш"},
+		{"кр\u200bаш", "краш"},
+		{"\u200bкраш\u200b", "краш"},
+		{"\ufeffлол", "лол"},
+		{"на\u2060чиле", "начиле"},
+		{"пример\tс табом", "пример\tс табом"},
+		{"\tкраш\t", "краш"},
+		{"строка\nвторая", "строка\nвторая"},
+		{"звон\aок", "звонок"},
+		{"нуль\x00байт", "нульбайт"},
+		{"esc\x1b[31m", "esc[31m"},
+		{"перевод\r\n", "перевод"},
+		{"\u0085краш", "краш"},
+		{"Ёжик, привет! 🔥", "Ёжик, привет! 🔥"},
+		{"👨\u200d👩\u200d👧", "👨\u200d👩\u200d👧"},
+		{"  \u200b  ", ""},
+	}
+	for _, tt := range tests {
+		if got := sanitizeText(tt.in); got != tt.want {
+			t.Errorf("sanitizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeEntry(t *testing.T) {
+	entry := SlangEntry{
+		Word:     "\u200bкраш\t",
+		Meaning:  "объект\x07 симпатии",
+		Example:  "Он мой\u200b краш",
+		Origin:   "\tот crush",
+		Synonyms: []string{"симпатия\u200b", "\x01любовь"},
+		Tags:     []string{"\ufeffgen-z"},
+	}
+	sanitizeEntry(&entry)
+
+	if entry.Word != "краш" || entry.Meaning != "объект симпатии" ||
+		entry.Example != "Он мой краш" || entry.Origin != "от crush" {
+		t.Errorf("text fields not sanitized: %+v", entry)
+	}
+	if !slices.Equal(entry.Synonyms, []string{"симпатия", "любовь"}) {
+		t.Errorf("synonyms = %q", entry.Synonyms)
+	}
+	if !slices.Equal(entry.Tags, []string{"gen-z"}) {
+		t.Errorf("tags = %q", entry.Tags)
+	}
+}
+
+func TestSanitizePatch(t *testing.T) {
+	word := "\u200bлол"
+	tags := []string{"мем\u200b"}
+	patch := SlangEntryPatch{Word: &word, Tags: &tags}
+	sanitizePatch(&patch)
+
+	if *patch.Word != "лол" {
+		t.Errorf("word = %q", *patch.Word)
+	}
+	if (*patch.Tags)[0] != "мем" {
+		t.Errorf("tags = %q", *patch.Tags)
+	}
+	if patch.Meaning != nil {
+		t.Error("absent fields must stay nil")
+	}
+}
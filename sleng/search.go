@@ -0,0 +1,174 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Поля записи, по которым разрешён поиск
+var searchableFields = map[string]func(SlangEntry) string{
+	"word":    func(e SlangEntry) string { return e.Word },
+	"meaning": func(e SlangEntry) string { return e.Meaning },
+	"example": func(e SlangEntry) string { return e.Example },
+}
+
+// Поля, по которым ищет поиск без параметра fields (и поиск в CLI)
+var defaultSearchFields = []string{"word", "meaning", "example"}
+
+// Поиск записей, у которых хотя бы одно из полей содержит запрос (без учёта регистра)
+func searchEntries(entries []SlangEntry, query string, fields []string) []SlangEntry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	results := []SlangEntry{}
+	if query == "" {
+		return results
+	}
+	for _, entry := range entries {
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(searchableFields[field](entry)), query) {
+				results = append(results, entry)
+				break
+			}
+		}
+	}
+	return results
+}
+
+// Максимальное расстояние Левенштейна для нечёткого поиска по умолчанию
+const defaultFuzzyDistance = 2
+
+// Результат нечёткого поиска: запись и её расстояние до запроса
+type fuzzyMatch struct {
+	SlangEntry
+	Distance int `json:"distance"`
+}
+
+// Расстояние Левенштейна, считается по рунам, чтобы кириллическая буква была одним символом
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Нечёткий поиск по слову: записи не дальше maxDistance, сначала самые близкие
+func fuzzySearchEntries(entries []SlangEntry, query string, maxDistance int) []fuzzyMatch {
+	query = normalizeWord(query)
+	matches := []fuzzyMatch{}
+	for _, entry := range entries {
+		if d := levenshtein(query, normalizeWord(entry.Word)); d <= maxDistance {
+			matches = append(matches, fuzzyMatch{SlangEntry: entry, Distance: d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return normalizeWord(matches[i].Word) < normalizeWord(matches[j].Word)
+	})
+	return matches
+}
+
+// GET /api/search?q=...&fields=word,meaning
+// GET /api/search?q=...&fuzzy=true&max_distance=2 — нечёткий поиск по слову
+// GET /api/search?q=...&phonetic=true — поиск слов, которые звучат похоже
+func handleSearch(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgQueryRequired))
+			return
+		}
+
+		phonetic := r.URL.Query().Get("phonetic") == "true"
+		if phonetic && r.URL.Query().Get("fuzzy") == "true" {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgSearchModeConflict))
+			return
+		}
+		if phonetic {
+			entries := visibleEntries(r, loadSlangData(s).Entries)
+			respondJSON(w, r, http.StatusOK, phoneticSearchEntries(entries, query))
+			return
+		}
+
+		if r.URL.Query().Get("fuzzy") == "true" {
+			maxDistance, err := parseNonNegativeParam(r, "max_distance", defaultFuzzyDistance)
+			if err != nil {
+				respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+				return
+			}
+			entries := visibleEntries(r, loadSlangData(s).Entries)
+			respondJSON(w, r, http.StatusOK, fuzzySearchEntries(entries, query, maxDistance))
+			return
+		}
+
+		fields := defaultSearchFields
+		if raw := r.URL.Query().Get("fields"); raw != "" {
+			fields = nil
+			for _, field := range strings.Split(raw, ",") {
+				field = strings.ToLower(strings.TrimSpace(field))
+				if _, ok := searchableFields[field]; !ok {
+					respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgUnknownSearchField, field))
+					return
+				}
+				fields = append(fields, field)
+			}
+		}
+
+		entries := visibleEntries(r, loadSlangData(s).Entries)
+		respondJSON(w, r, http.StatusOK, searchEntries(entries, query, fields))
+	}
+}
+
+// Сколько подсказок отдаёт автодополнение по умолчанию
+const defaultAutocompleteLimit = 10
+
+// Слова, начинающиеся с префикса (без учёта регистра и разницы ё/е), по алфавиту
+func autocompleteWords(entries []SlangEntry, prefix string, limit int) []string {
+	prefix = normalizeWord(prefix)
+	words := []string{}
+	if prefix == "" {
+		return words
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(normalizeWord(entry.Word), prefix) {
+			words = append(words, entry.Word)
+		}
+	}
+	sort.Slice(words, func(i, j int) bool {
+		return normalizeWord(words[i]) < normalizeWord(words[j])
+	})
+	if len(words) > limit {
+		words = words[:limit]
+	}
+	return words
+}
+
+// GET /api/autocomplete?prefix=...&limit=10
+func handleAutocomplete(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := parseNonNegativeParam(r, "limit", defaultAutocompleteLimit)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, trErr(r, err))
+			return
+		}
+		limit = min(limit, maxPageLimit)
+
+		entries := visibleEntries(r, loadSlangData(s).Entries)
+		respondJSON(w, r, http.StatusOK, autocompleteWords(entries, r.URL.Query().Get("prefix"), limit))
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Как часто отправлять комментарий-пустышку, чтобы прокси не закрывали простаивающее соединение
+const sseKeepAlive = 15 * time.Second
+
+// GET /api/events — те же события, что и /api/ws, в формате Server-Sent Events:
+//
+//	id: 42
+//	event: added
+//	data: {"type":"added","id":"...","entry":{...}}
+//
+// Браузер (EventSource) при переподключении сам присылает Last-Event-ID
+// и получает пропущенные события, если они ещё хранятся.
+func handleEvents(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := streamUser(s, r)
+		if err != nil {
+			respondAuthError(w, r, err)
+			return
+		}
+		lastID := int64(-1)
+		if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+			lastID, err = strconv.ParseInt(raw, 10, 64)
+			if err != nil || lastID < 0 {
+				respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidLastEventID))
+				return
+			}
+		}
+
+		rc := http.NewResponseController(w)
+		// Поток живёт долго: общие таймауты сервера к нему не применяются. Без снятия
+		// таймаута чтения сервер по его истечении отменил бы контекст запроса.
+		rc.SetWriteDeadline(time.Time{})
+		rc.SetReadDeadline(time.Time{})
+
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		sub, missed := liveEvents.subscribe(username, lastID)
+		defer liveEvents.unsubscribe(sub)
+		for _, msg := range missed {
+			writeSSE(w, msg)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(sseKeepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case msg, ok := <-sub.send:
+				if !ok {
+					return
+				}
+				writeSSE(w, msg)
+			case <-ticker.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			case <-r.Context().Done():
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, msg hubMessage) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.id, msg.event.Type, msg.data)
+}
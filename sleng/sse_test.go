@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sseEvent struct {
+	id    int64
+	event string
+	data  entryEvent
+}
+
+// Подключение к /api/events; события читаются из возвращаемого канала
+func openEvents(t *testing.T, url, lastEventID string) <-chan sseEvent {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, "GET", url+"/api/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("ответ: %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer res.Body.Close()
+		defer close(events)
+		var current sseEvent
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				current.id, _ = strconv.ParseInt(strings.TrimPrefix(line, "id: "), 10, 64)
+			case strings.HasPrefix(line, "event: "):
+				current.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &current.data)
+			case line == "" && current.event != "":
+				events <- current
+				current = sseEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("поток событий закрыт")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("нет события")
+	}
+	return sseEvent{}
+}
+
+func TestServerSentEvents(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234")
+	events := openEvents(t, srv.URL, "")
+
+	for _, word := range []string{"краш", "кринж"} {
+		body := `{"word":"` + word + `","meaning":"значение слова"}`
+		if resp := doRequest(t, srv, "POST", "/api/entries", token, body); resp.status != http.StatusCreated {
+			t.Fatalf("status = %d; body %s", resp.status, resp.body)
+		}
+	}
+	first := nextEvent(t, events)
+	second := nextEvent(t, events)
+	if first.event != "added" || first.data.Entry == nil || first.data.Entry.Word != "краш" {
+		t.Errorf("первое событие: %+v", first)
+	}
+	if second.id != first.id+1 || second.data.Entry == nil || second.data.Entry.Word != "кринж" {
+		t.Errorf("второе событие: %+v", second)
+	}
+
+	// Переподключение с Last-Event-ID получает пропущенное событие
+	resumed := openEvents(t, srv.URL, strconv.FormatInt(first.id, 10))
+	replayed := nextEvent(t, resumed)
+	if replayed.id != second.id || replayed.data.Entry == nil || replayed.data.Entry.Word != "кринж" {
+		t.Errorf("после переподключения: %+v", replayed)
+	}
+
+	resp := doRequest(t, srv, "DELETE", "/api/entries/1?permanent=true", token, "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	for _, ch := range []<-chan sseEvent{events, resumed} {
+		deleted := nextEvent(t, ch)
+		if deleted.event != "deleted" || deleted.data.ID != first.data.ID || deleted.id != second.id+1 {
+			t.Errorf("удаление: %+v", deleted)
+		}
+	}
+}
+
+func TestServerSentEventsBadLastEventID(t *testing.T) {
+	srv, _ := newTestServer(t)
+	req, _ := http.NewRequest("GET", srv.URL+"/api/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", res.StatusCode)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Сводка по словарю для панели статистики
+type dictionaryStats struct {
+	Total                int     `json:"total"`
+	WithOrigin           int     `json:"with_origin"`
+	WithSynonyms         int     `json:"with_synonyms"`
+	AvgMeaningLength     float64 `json:"avg_meaning_length"`
+	DistinctFirstLetters int     `json:"distinct_first_letters"`
+}
+
+// Подсчёт статистики за один проход по записям (длина значения — в символах, не байтах)
+func computeStats(entries []SlangEntry) dictionaryStats {
+	stats := dictionaryStats{Total: len(entries)}
+	letters := make(map[rune]struct{})
+	meaningRunes := 0
+
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.Origin) != "" {
+			stats.WithOrigin++
+		}
+		if len(entry.Synonyms) > 0 {
+			stats.WithSynonyms++
+		}
+		meaningRunes += utf8.RuneCountInString(entry.Meaning)
+		if word := normalizeWord(entry.Word); word != "" {
+			first, _ := utf8.DecodeRuneInString(word)
+			letters[unicode.ToLower(first)] = struct{}{}
+		}
+	}
+
+	if stats.Total > 0 {
+		stats.AvgMeaningLength = float64(meaningRunes) / float64(stats.Total)
+	}
+	stats.DistinctFirstLetters = len(letters)
+	return stats
+}
+
+// Буква указателя для слова: первая буква в верхнем регистре, для цифр
+// и символов — «#». Ё уже заменена на Е в normalizeWord, поэтому «ёлка» стоит под Е.
+func indexLetter(word string) string {
+	first, _ := utf8.DecodeRuneInString(normalizeWord(word))
+	if !unicode.IsLetter(first) {
+		return "#"
+	}
+	return string(unicode.ToUpper(first))
+}
+
+// Алфавитный указатель: буква -> количество слов на неё. JSON-объект из map
+// выводится с ключами по порядку кодов: «#», латиница, затем кириллица А–Я.
+func letterIndex(entries []SlangEntry) map[string]int {
+	index := make(map[string]int)
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.Word) != "" {
+			index[indexLetter(entry.Word)]++
+		}
+	}
+	return index
+}
+
+// GET /api/index
+func handleIndex(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entries := visibleEntries(r, loadSlangData(s).Entries)
+		respondJSON(w, r, http.StatusOK, letterIndex(entries))
+	}
+}
+
+// GET /api/stats
+func handleStats(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entries := visibleEntries(r, loadSlangData(s).Entries)
+		respondJSON(w, r, http.StatusOK, computeStats(entries))
+	}
+}
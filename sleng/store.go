@@ -0,0 +1,371 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Хранилище словаря: JSON-файл (по умолчанию), база SQLite или память
+type Store interface {
+	Load() (SlangData, error)
+	Save(SlangData) error
+	// Чтение, изменение и сохранение под одной блокировкой, чтобы параллельные
+	// запросы не затирали изменения друг друга. Если fn вернула ошибку,
+	// ничего не сохраняется и Modify возвращает эту ошибку.
+	Modify(fn func(*SlangData) error) error
+
+	// Операции над отдельными записями и пользователями. FileStore выражает их
+	// через Load и Modify (функции storeEntries и т.п. ниже), sqliteStore меняет
+	// AddEntry, DeleteEntry и SetUser одной строкой таблицы.
+	Entries() ([]SlangEntry, error)
+	// Ошибка errWordExists, если у владельца уже есть такое слово
+	AddEntry(entry SlangEntry) error
+	// Удаление насовсем, вместе с голосами и историей; errEntryNotFound, если ID нет
+	DeleteEntry(id string) error
+	// Ошибка errUserNotFound, если пользователя нет
+	GetUser(username string) (User, error)
+	// Добавляет пользователя или заменяет пользователя с тем же логином
+	SetUser(user User) error
+}
+
+// Выбор хранилища по настройке storage: json, sqlite или memory
+func openStore(cfg config) (Store, error) {
+	switch cfg.Storage {
+	case "", "json":
+		store := NewFileStore(cfg.DataFile)
+		store.backupDir = cfg.BackupDir
+		store.keepBackups = cfg.KeepBackups
+		store.strict = cfg.Strict
+		return store, nil
+	case "sqlite":
+		return openSQLiteStore(cfg.DBFile, cfg.DataFile)
+	case "memory":
+		return openMemoryStore(cfg.DataFile)
+	default:
+		return nil, fmt.Errorf("неизвестный тип хранилища: %s", cfg.Storage)
+	}
+}
+
+func emptySlangData() SlangData {
+	return SlangData{Version: schemaVersion, Entries: []SlangEntry{}, Users: []User{}}
+}
+
+func storeEntries(s Store) ([]SlangEntry, error) {
+	slangData, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return slangData.Entries, nil
+}
+
+func storeAddEntry(s Store, entry SlangEntry) error {
+	return s.Modify(func(slangData *SlangData) error {
+		if ownerWordExists(slangData.Entries, entry.Owner, entry.Word, -1) {
+			return errWordExists
+		}
+		entry.CanonicalWord = normalizeWord(entry.Word)
+		slangData.Entries = append(slangData.Entries, entry)
+		return nil
+	})
+}
+
+func storeDeleteEntry(s Store, id string) error {
+	return s.Modify(func(slangData *SlangData) error {
+		i := findEntryByID(slangData.Entries, id)
+		if i < 0 {
+			return errEntryNotFound
+		}
+		removeEntry(slangData, i, true)
+		return nil
+	})
+}
+
+// Пользователи из файлов старого формата (один пользователь, пароль в открытом
+// виде) находятся так же, как после loadSlangData
+func storeGetUser(s Store, username string) (User, error) {
+	slangData, err := s.Load()
+	if err != nil {
+		return User{}, err
+	}
+	migrateSlangData(&slangData)
+	user := findUser(&slangData, username)
+	if user == nil {
+		return User{}, errUserNotFound
+	}
+	return *user, nil
+}
+
+func storeSetUser(s Store, user User) error {
+	user.Username = normalizeUsername(user.Username)
+	return s.Modify(func(slangData *SlangData) error {
+		if existing := findUser(slangData, user.Username); existing != nil {
+			*existing = user
+			return nil
+		}
+		slangData.Users = append(slangData.Users, user)
+		return nil
+	})
+}
+
+// ————————————————————————
+//         JSON-файл
+// ————————————————————————
+
+// Словарь в JSON-файле. У каждого хранилища свой путь и свой мьютекс,
+// поэтому несколько словарей (или тестов) не мешают друг другу.
+type FileStore struct {
+	path string
+	mu   sync.RWMutex
+
+	// Перед каждым сохранением прежний файл копируется в backupDir;
+	// хранятся keepBackups последних копий, 0 — копии не делаются
+	backupDir   string
+	keepBackups int
+
+	// Данные последнего прочитанного или записанного файла. Чтения отдаются
+	// из памяти, пока у файла те же время изменения и размер, что и при
+	// заполнении кэша; если файл поменяли снаружи, он перечитывается.
+	cache     *SlangData
+	cacheStat os.FileInfo
+
+	// Повреждённый файл не восстанавливать (см. recoverCorruptLocked)
+	strict bool
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Load() (SlangData, error) {
+	s.mu.RLock()
+	slangData, ok := s.cached()
+	s.mu.RUnlock()
+	if ok {
+		return slangData, nil
+	}
+
+	// Кэш пуст или устарел: заполняем его под блокировкой на запись
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadLocked()
+}
+
+func (s *FileStore) Save(slangData SlangData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveLocked(slangData)
+}
+
+func (s *FileStore) Modify(fn func(*SlangData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slangData, err := s.loadLocked()
+	if err != nil {
+		return err
+	}
+	if err := fn(&slangData); err != nil {
+		return err
+	}
+	return s.saveLocked(slangData)
+}
+
+func (s *FileStore) Entries() ([]SlangEntry, error)        { return storeEntries(s) }
+func (s *FileStore) AddEntry(entry SlangEntry) error       { return storeAddEntry(s, entry) }
+func (s *FileStore) DeleteEntry(id string) error           { return storeDeleteEntry(s, id) }
+func (s *FileStore) GetUser(username string) (User, error) { return storeGetUser(s, username) }
+func (s *FileStore) SetUser(user User) error               { return storeSetUser(s, user) }
+
+// Копия данных из кэша, если файл не менялся с момента заполнения кэша.
+// Вызывается под s.mu (достаточно на чтение).
+func (s *FileStore) cached() (SlangData, bool) {
+	if s.cache == nil {
+		return SlangData{}, false
+	}
+	info, err := os.Stat(s.path)
+	if err != nil || !sameFile(info, s.cacheStat) {
+		return SlangData{}, false
+	}
+	// Вызывающий код меняет полученные данные, поэтому кэш отдаётся только копией
+	return cloneSlangData(*s.cache), true
+}
+
+func sameFile(a, b os.FileInfo) bool {
+	return a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+// Вызывается под s.mu (на запись)
+func (s *FileStore) loadLocked() (SlangData, error) {
+	if slangData, ok := s.cached(); ok {
+		return slangData, nil
+	}
+	s.cache = nil
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		return emptySlangData(), nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return SlangData{}, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	if err := s.fillCache(data, info); err != nil {
+		return s.recoverCorruptLocked(err)
+	}
+	return cloneSlangData(*s.cache), nil
+}
+
+// Кэш заполняется разбором тех же байтов, что лежат в файле, поэтому
+// не может разойтись с ним. Вызывается под s.mu (на запись).
+func (s *FileStore) fillCache(data []byte, info os.FileInfo) error {
+	var slangData SlangData
+	if err := json.Unmarshal(data, &slangData); err != nil {
+		return err
+	}
+	s.cache = &slangData
+	s.cacheStat = info
+	return nil
+}
+
+// Файл словаря есть, но не разбирается как JSON
+var errCorruptData = errors.New("файл словаря повреждён")
+
+// Повреждённый файл не затирается следующим сохранением: он переименовывается
+// в slang.json.corrupt.<время>, а словарь восстанавливается из самой свежей
+// исправной резервной копии или, если их нет, начинается заново. В строгом
+// режиме файл не трогается и возвращается errCorruptData.
+// Вызывается под s.mu (на запись).
+func (s *FileStore) recoverCorruptLocked(parseErr error) (SlangData, error) {
+	if s.strict {
+		return SlangData{}, fmt.Errorf("%w: %s: %v", errCorruptData, s.path, parseErr)
+	}
+	corrupt := s.path + ".corrupt." + time.Now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(s.path, corrupt); err != nil {
+		return SlangData{}, fmt.Errorf("%w: %s: %v (не удалось переименовать: %v)", errCorruptData, s.path, parseErr, err)
+	}
+	logger.Error("файл словаря повреждён, его содержимое сохранено отдельно", "file", s.path, "saved_as", corrupt, "error", parseErr)
+
+	backups, _ := s.listBackups()
+	for _, backup := range backups {
+		data, err := os.ReadFile(filepath.Join(s.backupDir, backup.Name))
+		if err != nil {
+			continue
+		}
+		var slangData SlangData
+		if err := json.Unmarshal(data, &slangData); err != nil {
+			continue
+		}
+		if err := writeFileAtomic(s.path, data, 0644); err != nil {
+			return SlangData{}, fmt.Errorf("ошибка записи файла: %w", err)
+		}
+		logger.Warn("словарь восстановлен из резервной копии", "backup", backup.Name, "entries", len(slangData.Entries))
+		return slangData, nil
+	}
+	logger.Warn("исправных резервных копий нет, словарь начат заново", "file", s.path)
+	return emptySlangData(), nil
+}
+
+// Вызывается под s.mu (на запись)
+func (s *FileStore) saveLocked(slangData SlangData) error {
+	data, err := json.MarshalIndent(slangData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка при сериализации: %w", err)
+	}
+	if s.keepBackups > 0 {
+		// Сбой копии не должен мешать сохранению — только предупреждаем
+		if _, err := s.backupLocked(); err != nil && !errors.Is(err, errNothingToBackup) {
+			logger.Warn("не удалось сделать резервную копию", "error", err)
+		}
+	}
+	// При сбое записи неизвестно, что осталось в файле: кэш сбрасывается
+	// и следующее чтение пойдёт с диска
+	s.cache = nil
+	if err := writeFileAtomic(s.path, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.fillCache(data, info)
+	}
+	return nil
+}
+
+// Глубокая копия: у копии свои срезы и словари, общих с оригиналом нет
+func cloneSlangData(d SlangData) SlangData {
+	c := d
+	c.Users = slices.Clone(d.Users)
+	if d.Entries != nil {
+		c.Entries = make([]SlangEntry, len(d.Entries))
+		for i, e := range d.Entries {
+			c.Entries[i] = cloneEntry(e)
+		}
+	}
+	if d.Votes != nil {
+		c.Votes = make(map[string]map[string]int, len(d.Votes))
+		for id, byUser := range d.Votes {
+			c.Votes[id] = maps.Clone(byUser)
+		}
+	}
+	if d.History != nil {
+		c.History = make(map[string][]entryRevision, len(d.History))
+		for id, revisions := range d.History {
+			copied := slices.Clone(revisions)
+			for i := range copied {
+				copied[i].Synonyms = slices.Clone(copied[i].Synonyms)
+				copied[i].Tags = slices.Clone(copied[i].Tags)
+			}
+			c.History[id] = copied
+		}
+	}
+	if d.Trash != nil {
+		c.Trash = slices.Clone(d.Trash)
+		for i := range d.Trash {
+			c.Trash[i].SlangEntry = cloneEntry(d.Trash[i].SlangEntry)
+		}
+	}
+	if d.LegacyUser != nil {
+		legacy := *d.LegacyUser
+		c.LegacyUser = &legacy
+	}
+	return c
+}
+
+func cloneEntry(e SlangEntry) SlangEntry {
+	e.Synonyms = slices.Clone(e.Synonyms)
+	e.Tags = slices.Clone(e.Tags)
+	return e
+}
+
+// Атомарная запись: данные пишутся во временный файл в той же папке,
+// сбрасываются на диск и только потом переименовываются поверх исходного.
+// Если процесс упадёт посередине, старый файл останется целым.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	// После успешного Rename временного файла уже нет, и Remove ничего не сделает
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
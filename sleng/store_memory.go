@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ————————————————————————
+//         В памяти
+// ————————————————————————
+
+// Словарь только в памяти: ничего не пишется на диск и всё теряется при
+// остановке. Для тестов, CI и демо-стендов без состояния.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data SlangData
+}
+
+// Пустое хранилище или хранилище с копией seed
+func NewMemoryStore(seed SlangData) *MemoryStore {
+	return &MemoryStore{data: cloneSlangData(seed)}
+}
+
+// Хранилище, заполненное из JSON-файла (если он есть). Файл только читается:
+// изменения в него не записываются.
+func openMemoryStore(seedPath string) (*MemoryStore, error) {
+	logger.Warn("данные хранятся только в памяти и пропадут при остановке")
+	if _, err := os.Stat(seedPath); os.IsNotExist(err) {
+		return NewMemoryStore(emptySlangData()), nil
+	}
+	// Строго: хранилище в памяти не должно ничего менять на диске, даже переименовывать
+	seed, err := (&FileStore{path: seedPath, strict: true}).Load()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать %s: %w", seedPath, err)
+	}
+	logger.Info("данные загружены в память", "file", seedPath)
+	return NewMemoryStore(seed), nil
+}
+
+// Отдаются копии, чтобы изменения у вызывающего не меняли хранилище
+func (s *MemoryStore) Load() (SlangData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneSlangData(s.data), nil
+}
+
+func (s *MemoryStore) Save(slangData SlangData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = cloneSlangData(slangData)
+	return nil
+}
+
+func (s *MemoryStore) Modify(fn func(*SlangData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slangData := cloneSlangData(s.data)
+	if err := fn(&slangData); err != nil {
+		return err
+	}
+	s.data = slangData
+	return nil
+}
+
+func (s *MemoryStore) Entries() ([]SlangEntry, error)        { return storeEntries(s) }
+func (s *MemoryStore) AddEntry(entry SlangEntry) error       { return storeAddEntry(s, entry) }
+func (s *MemoryStore) DeleteEntry(id string) error           { return storeDeleteEntry(s, id) }
+func (s *MemoryStore) GetUser(username string) (User, error) { return storeGetUser(s, username) }
+func (s *MemoryStore) SetUser(user User) error               { return storeSetUser(s, user) }
@@ -0,0 +1,727 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Хранилище в базе SQLite (чистый Go, без cgo)
+type sqliteStore struct {
+	db *sql.DB
+	// Сохранения и Modify идут по очереди: иначе Modify мог бы записать
+	// данные, прочитанные до чужого сохранения
+	mu sync.Mutex
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS users (
+	username TEXT PRIMARY KEY,
+	password TEXT NOT NULL,
+	is_admin INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL DEFAULT '',
+	password_changed_at TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS votes (
+	entry_id TEXT NOT NULL,
+	username TEXT NOT NULL,
+	vote     INTEGER NOT NULL,
+	PRIMARY KEY (entry_id, username)
+);
+CREATE TABLE IF NOT EXISTS history (
+	entry_id  TEXT PRIMARY KEY,
+	revisions TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS trash (
+	position   INTEGER PRIMARY KEY,
+	entry      TEXT NOT NULL,
+	deleted_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS entries (
+	position   INTEGER PRIMARY KEY,
+	id         TEXT NOT NULL DEFAULT '',
+	word       TEXT NOT NULL,
+	canonical_word TEXT NOT NULL DEFAULT '',
+	meaning    TEXT NOT NULL,
+	example    TEXT NOT NULL DEFAULT '',
+	origin     TEXT NOT NULL DEFAULT '',
+	synonyms   TEXT NOT NULL DEFAULT '[]',
+	tags       TEXT NOT NULL DEFAULT '[]',
+	score      INTEGER NOT NULL DEFAULT 0,
+	owner      TEXT NOT NULL DEFAULT '',
+	private    INTEGER NOT NULL DEFAULT 0,
+	author     TEXT NOT NULL DEFAULT '',
+	last_edited_by TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL DEFAULT '',
+	updated_at TEXT NOT NULL DEFAULT ''
+);`
+
+// Открытие базы. При первом запуске в неё импортируется существующий JSON-файл.
+func openSQLiteStore(path, jsonPath string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite не любит параллельных писателей
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка создания схемы: %w", err)
+	}
+	// Колонки, появившиеся после первой версии схемы
+	for _, column := range []struct{ name, definition string }{
+		{"id", "TEXT NOT NULL DEFAULT ''"},
+		{"tags", "TEXT NOT NULL DEFAULT '[]'"},
+		{"score", "INTEGER NOT NULL DEFAULT 0"},
+		{"owner", "TEXT NOT NULL DEFAULT ''"},
+		{"private", "INTEGER NOT NULL DEFAULT 0"},
+		{"author", "TEXT NOT NULL DEFAULT ''"},
+		{"last_edited_by", "TEXT NOT NULL DEFAULT ''"},
+		{"canonical_word", "TEXT NOT NULL DEFAULT ''"},
+	} {
+		if err := addColumnIfMissing(db, "entries", column.name, column.definition); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("ошибка обновления схемы: %w", err)
+		}
+	}
+	// Индекс по (owner, canonical_word) из прежней версии. Повтор слова проверяется
+	// по загруженному в Modify словарю, так что индекс только замедлял каждую запись.
+	if _, err := db.Exec(`DROP INDEX IF EXISTS entries_owner_word`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка обновления схемы: %w", err)
+	}
+	if err := addColumnIfMissing(db, "users", "is_admin", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка обновления схемы: %w", err)
+	}
+	if err := addColumnIfMissing(db, "users", "created_at", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка обновления схемы: %w", err)
+	}
+	if err := addColumnIfMissing(db, "users", "password_changed_at", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка обновления схемы: %w", err)
+	}
+
+	s := &sqliteStore{db: db}
+	if err := s.importJSON(jsonPath); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := s.fillCanonicalWords(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка обновления схемы: %w", err)
+	}
+	return s, nil
+}
+
+// Записи из базы старой версии получают каноническую форму слова: по ней AddEntry
+// ищет повтор, не читая словарь
+func (s *sqliteStore) fillCanonicalWords() error {
+	rows, err := s.db.Query(`SELECT position, word FROM entries WHERE canonical_word = ''`)
+	if err != nil {
+		return err
+	}
+	words := make(map[int64]string)
+	for rows.Next() {
+		var position int64
+		var word string
+		if err := rows.Scan(&position, &word); err != nil {
+			rows.Close()
+			return err
+		}
+		words[position] = word
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(words) == 0 {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for position, word := range words {
+		if _, err := tx.Exec(`UPDATE entries SET canonical_word = ? WHERE position = ?`, normalizeWord(word), position); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Добавление колонки в таблицу, созданную старой версией программы
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// Импорт slang.json, если база ещё ни разу не сохранялась
+func (s *sqliteStore) importJSON(jsonPath string) error {
+	var version string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key = 'version'`).Scan(&version)
+	if err == nil {
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	if _, err := os.Stat(jsonPath); os.IsNotExist(err) {
+		return s.Save(emptySlangData())
+	}
+	// Повреждённый файл не восстанавливается, а остаётся как есть вместе с ошибкой
+	slangData, err := (&FileStore{path: jsonPath, strict: true}).Load()
+	if err != nil {
+		return fmt.Errorf("не удалось импортировать %s: %w", jsonPath, err)
+	}
+	if err := s.Save(slangData); err != nil {
+		return err
+	}
+	logger.Info("данные импортированы в базу", "file", jsonPath)
+	return nil
+}
+
+func (s *sqliteStore) Load() (SlangData, error) {
+	slangData, _, err := s.load()
+	return slangData, err
+}
+
+// Номера строк entries и trash в порядке записей: по ним Modify обновляет
+// только изменившиеся строки
+type rowPositions struct {
+	entries, trash []int64
+}
+
+func (s *sqliteStore) load() (SlangData, rowPositions, error) {
+	slangData := emptySlangData()
+	var positions rowPositions
+
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key = 'version'`).Scan(&slangData.Version)
+	if err != nil && err != sql.ErrNoRows {
+		return SlangData{}, positions, err
+	}
+
+	users, err := s.db.Query(`SELECT username, password, is_admin, created_at, password_changed_at FROM users ORDER BY rowid`)
+	if err != nil {
+		return SlangData{}, positions, err
+	}
+	defer users.Close()
+	for users.Next() {
+		var user User
+		var createdAt, passwordChangedAt string
+		if err := users.Scan(&user.Username, &user.Password, &user.IsAdmin, &createdAt, &passwordChangedAt); err != nil {
+			return SlangData{}, positions, err
+		}
+		user.CreatedAt = parseStoredTime(createdAt)
+		user.PasswordChangedAt = parseStoredTime(passwordChangedAt)
+		slangData.Users = append(slangData.Users, user)
+	}
+	if err := users.Err(); err != nil {
+		return SlangData{}, positions, err
+	}
+
+	votes, err := s.db.Query(`SELECT entry_id, username, vote FROM votes`)
+	if err != nil {
+		return SlangData{}, positions, err
+	}
+	defer votes.Close()
+	for votes.Next() {
+		var entryID, username string
+		var vote int
+		if err := votes.Scan(&entryID, &username, &vote); err != nil {
+			return SlangData{}, positions, err
+		}
+		if slangData.Votes == nil {
+			slangData.Votes = make(map[string]map[string]int)
+		}
+		if slangData.Votes[entryID] == nil {
+			slangData.Votes[entryID] = make(map[string]int)
+		}
+		slangData.Votes[entryID][username] = vote
+	}
+	if err := votes.Err(); err != nil {
+		return SlangData{}, positions, err
+	}
+
+	history, err := s.db.Query(`SELECT entry_id, revisions FROM history`)
+	if err != nil {
+		return SlangData{}, positions, err
+	}
+	defer history.Close()
+	for history.Next() {
+		var entryID, data string
+		if err := history.Scan(&entryID, &data); err != nil {
+			return SlangData{}, positions, err
+		}
+		var revisions []entryRevision
+		if err := json.Unmarshal([]byte(data), &revisions); err != nil {
+			return SlangData{}, positions, err
+		}
+		if slangData.History == nil {
+			slangData.History = make(map[string][]entryRevision)
+		}
+		slangData.History[entryID] = revisions
+	}
+	if err := history.Err(); err != nil {
+		return SlangData{}, positions, err
+	}
+
+	trash, err := s.db.Query(`SELECT position, entry, deleted_at FROM trash ORDER BY position`)
+	if err != nil {
+		return SlangData{}, positions, err
+	}
+	defer trash.Close()
+	for trash.Next() {
+		var position int64
+		var data, deletedAt string
+		if err := trash.Scan(&position, &data, &deletedAt); err != nil {
+			return SlangData{}, positions, err
+		}
+		var t trashedEntry
+		if err := json.Unmarshal([]byte(data), &t.SlangEntry); err != nil {
+			return SlangData{}, positions, err
+		}
+		t.DeletedAt = parseStoredTime(deletedAt)
+		slangData.Trash = append(slangData.Trash, t)
+		positions.trash = append(positions.trash, position)
+	}
+	if err := trash.Err(); err != nil {
+		return SlangData{}, positions, err
+	}
+
+	rows, err := s.db.Query(`SELECT position, ` + entryColumns + ` FROM entries ORDER BY position`)
+	if err != nil {
+		return SlangData{}, positions, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var position int64
+		var entry SlangEntry
+		var synonyms, tags, createdAt, updatedAt string
+		if err := rows.Scan(&position, &entry.ID, &entry.Word, &entry.CanonicalWord, &entry.Meaning, &entry.Example, &entry.Origin,
+			&synonyms, &tags, &entry.Score, &entry.Owner, &entry.Private, &entry.Author, &entry.LastEditedBy,
+			&createdAt, &updatedAt); err != nil {
+			return SlangData{}, positions, err
+		}
+		if err := json.Unmarshal([]byte(synonyms), &entry.Synonyms); err != nil {
+			return SlangData{}, positions, err
+		}
+		if err := json.Unmarshal([]byte(tags), &entry.Tags); err != nil {
+			return SlangData{}, positions, err
+		}
+		entry.CreatedAt = parseStoredTime(createdAt)
+		entry.UpdatedAt = parseStoredTime(updatedAt)
+		slangData.Entries = append(slangData.Entries, entry)
+		positions.entries = append(positions.entries, position)
+	}
+	return slangData, positions, rows.Err()
+}
+
+func (s *sqliteStore) Save(slangData SlangData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, positions, err := s.load()
+	if err != nil {
+		return err
+	}
+	before, err := takeSnapshot(current, positions)
+	if err != nil {
+		return err
+	}
+	return s.saveChanges(before, slangData)
+}
+
+func (s *sqliteStore) Modify(fn func(*SlangData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slangData, positions, err := s.load()
+	if err != nil {
+		return err
+	}
+	// Снимок до fn: fn меняет срезы и карты slangData на месте
+	before, err := takeSnapshot(slangData, positions)
+	if err != nil {
+		return err
+	}
+	if err := fn(&slangData); err != nil {
+		return err
+	}
+	return s.saveChanges(before, slangData)
+}
+
+func (s *sqliteStore) Entries() ([]SlangEntry, error)        { return storeEntries(s) }
+func (s *sqliteStore) GetUser(username string) (User, error) { return storeGetUser(s, username) }
+
+// Добавление одной строкой, без чтения всего словаря: повтор слова ищется
+// запросом к entries по владельцу и канонической форме.
+func (s *sqliteStore) AddEntry(entry SlangEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	entry.CanonicalWord = normalizeWord(entry.Word)
+	var exists int
+	err = tx.QueryRow(`SELECT 1 FROM entries WHERE owner = ? AND canonical_word = ? LIMIT 1`,
+		entry.Owner, entry.CanonicalWord).Scan(&exists)
+	if err == nil {
+		return errWordExists
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	values, err := entryValues(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO entries (position, `+entryColumns+`)
+		VALUES ((SELECT COALESCE(MAX(position), 0) + 1 FROM entries)`+strings.Repeat(", ?", len(values))+`)`,
+		values...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Удаление насовсем, как storeDeleteEntry: вместе с записью пропадают её голоса и история
+func (s *sqliteStore) DeleteEntry(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM entries WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errEntryNotFound
+	}
+	if _, err := tx.Exec(`DELETE FROM votes WHERE entry_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM history WHERE entry_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) SetUser(user User) error {
+	user.Username = normalizeUsername(user.Username)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.db.Exec(upsertUser, append([]any{user.Username}, userValues(user)...)...)
+	return err
+}
+
+// Колонки entries после position, в порядке entryValues
+const entryColumns = `id, word, canonical_word, meaning, example, origin, synonyms, tags, score, owner, private,
+	author, last_edited_by, created_at, updated_at`
+
+// Значения колонок entryColumns. Каноническая форма пишется всегда, даже если
+// её не заполнили: по ней AddEntry ищет повтор слова.
+func entryValues(entry SlangEntry) ([]any, error) {
+	synonyms, err := json.Marshal(entry.Synonyms)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := json.Marshal(entry.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return []any{entry.ID, entry.Word, entry.canonical(), entry.Meaning, entry.Example, entry.Origin, string(synonyms),
+		string(tags), entry.Score, entry.Owner, entry.Private, entry.Author, entry.LastEditedBy,
+		formatStoredTime(entry.CreatedAt), formatStoredTime(entry.UpdatedAt)}, nil
+}
+
+const upsertUser = `INSERT INTO users (username, password, is_admin, created_at, password_changed_at) VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(username) DO UPDATE SET password = excluded.password, is_admin = excluded.is_admin,
+	created_at = excluded.created_at, password_changed_at = excluded.password_changed_at`
+
+// Значения колонок users после username
+func userValues(user User) []any {
+	return []any{user.Password, user.IsAdmin, formatStoredTime(user.CreatedAt), formatStoredTime(user.PasswordChangedAt)}
+}
+
+type voteKey struct{ entryID, username string }
+
+// Строки упорядоченной таблицы (entries, trash): ключ записи (ID), номер строки
+// (position, только у прочитанных из базы) и значения остальных колонок
+type positionedRows struct {
+	keys      []string
+	positions []int64
+	values    [][]any
+}
+
+// Данные в том виде, в каком они лежат в таблицах. Сравнение двух снимков
+// показывает, какие строки изменились.
+type sqliteSnapshot struct {
+	users   map[string][]any
+	votes   map[voteKey]int
+	history map[string]string
+	trash   positionedRows
+	entries positionedRows
+}
+
+func takeSnapshot(slangData SlangData, positions rowPositions) (sqliteSnapshot, error) {
+	snap := sqliteSnapshot{
+		users:   make(map[string][]any),
+		votes:   make(map[voteKey]int),
+		history: make(map[string]string),
+		trash:   positionedRows{positions: positions.trash},
+		entries: positionedRows{positions: positions.entries},
+	}
+	for _, user := range slangData.Users {
+		snap.users[user.Username] = userValues(user)
+	}
+	for entryID, byUser := range slangData.Votes {
+		for username, vote := range byUser {
+			snap.votes[voteKey{entryID, username}] = vote
+		}
+	}
+	for entryID, revisions := range slangData.History {
+		data, err := json.Marshal(revisions)
+		if err != nil {
+			return sqliteSnapshot{}, err
+		}
+		snap.history[entryID] = string(data)
+	}
+	for _, t := range slangData.Trash {
+		data, err := json.Marshal(t.SlangEntry)
+		if err != nil {
+			return sqliteSnapshot{}, err
+		}
+		snap.trash.keys = append(snap.trash.keys, t.ID)
+		snap.trash.values = append(snap.trash.values, []any{string(data), formatStoredTime(t.DeletedAt)})
+	}
+	for _, entry := range slangData.Entries {
+		values, err := entryValues(entry)
+		if err != nil {
+			return sqliteSnapshot{}, err
+		}
+		snap.entries.keys = append(snap.entries.keys, entry.ID)
+		snap.entries.values = append(snap.entries.values, values)
+	}
+	return snap, nil
+}
+
+// Запись в одной транзакции только тех строк, что отличаются от снимка before:
+// при сбое база остаётся в прежнем состоянии. Вызывается под s.mu.
+func (s *sqliteStore) saveChanges(before sqliteSnapshot, slangData SlangData) error {
+	after, err := takeSnapshot(slangData, rowPositions{})
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES ('version', ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, slangData.Version); err != nil {
+		return err
+	}
+
+	for username := range before.users {
+		if _, ok := after.users[username]; !ok {
+			if _, err := tx.Exec(`DELETE FROM users WHERE username = ?`, username); err != nil {
+				return err
+			}
+		}
+	}
+	// Новые пользователи — в порядке списка: по rowid Load восстанавливает порядок
+	for _, user := range slangData.Users {
+		values := after.users[user.Username]
+		if old, ok := before.users[user.Username]; ok && slices.Equal(old, values) {
+			continue
+		}
+		if _, err := tx.Exec(upsertUser, append([]any{user.Username}, values...)...); err != nil {
+			return err
+		}
+	}
+	// Пользователь из файла старого формата
+	if slangData.LegacyUser != nil && slangData.LegacyUser.Username != "" {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO users (username, password) VALUES (?, ?)`,
+			normalizeUsername(slangData.LegacyUser.Username), slangData.LegacyUser.Password); err != nil {
+			return err
+		}
+	}
+
+	for key := range before.votes {
+		if _, ok := after.votes[key]; !ok {
+			if _, err := tx.Exec(`DELETE FROM votes WHERE entry_id = ? AND username = ?`, key.entryID, key.username); err != nil {
+				return err
+			}
+		}
+	}
+	for key, vote := range after.votes {
+		if old, ok := before.votes[key]; ok && old == vote {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO votes (entry_id, username, vote) VALUES (?, ?, ?)
+			ON CONFLICT(entry_id, username) DO UPDATE SET vote = excluded.vote`, key.entryID, key.username, vote); err != nil {
+			return err
+		}
+	}
+
+	for entryID := range before.history {
+		if _, ok := after.history[entryID]; !ok {
+			if _, err := tx.Exec(`DELETE FROM history WHERE entry_id = ?`, entryID); err != nil {
+				return err
+			}
+		}
+	}
+	for entryID, data := range after.history {
+		if old, ok := before.history[entryID]; ok && old == data {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO history (entry_id, revisions) VALUES (?, ?)
+			ON CONFLICT(entry_id) DO UPDATE SET revisions = excluded.revisions`, entryID, data); err != nil {
+			return err
+		}
+	}
+
+	if err := writePositionedRows(tx, "trash", "entry, deleted_at", before.trash, after.trash); err != nil {
+		return err
+	}
+	if err := writePositionedRows(tx, "entries", entryColumns, before.entries, after.entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Запись упорядоченной таблицы: пропавшие строки удаляются, новые и изменившиеся
+// записываются, остальные не трогаются. Если старые номера строк не дают сохранить
+// новый порядок (записи переставили или вставили между соседними номерами),
+// таблица переписывается целиком.
+func writePositionedRows(tx *sql.Tx, table, columns string, before, after positionedRows) error {
+	insert := `INSERT OR REPLACE INTO ` + table + ` (position, ` + columns + `) VALUES (?` +
+		strings.Repeat(", ?", len(strings.Split(columns, ","))) + `)`
+
+	positions, ok := assignPositions(before, after.keys)
+	if !ok {
+		if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+			return err
+		}
+		for i, values := range after.values {
+			if _, err := tx.Exec(insert, append([]any{int64(i + 1)}, values...)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Что лежало под каждым номером. Значения включают ID, так что равные
+	// значения — та же запись без изменений.
+	stored := make(map[int64]int, len(before.positions))
+	for i, position := range before.positions {
+		stored[position] = i
+	}
+	for j, position := range positions {
+		if i, ok := stored[position]; ok {
+			delete(stored, position)
+			if slices.Equal(before.values[i], after.values[j]) {
+				continue
+			}
+		}
+		if _, err := tx.Exec(insert, append([]any{position}, after.values[j]...)...); err != nil {
+			return err
+		}
+	}
+	// Остались строки записей, которых больше нет
+	for position := range stored {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE position = ?`, position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Номера строк для записей keys: оставшиеся записи сохраняют свои номера, новые
+// получают свободные номера между соседями. false — если так сохранить порядок нельзя.
+func assignPositions(before positionedRows, keys []string) ([]int64, bool) {
+	old := make(map[string]int64, len(before.keys))
+	for i, key := range before.keys {
+		if _, dup := old[key]; dup {
+			return nil, false
+		}
+		old[key] = before.positions[i]
+	}
+
+	// Ближайший сохраняемый номер справа от каждой записи
+	next := make([]int64, len(keys))
+	limit := int64(math.MaxInt64)
+	for j := len(keys) - 1; j >= 0; j-- {
+		next[j] = limit
+		if position, ok := old[keys[j]]; ok {
+			limit = position
+		}
+	}
+
+	positions := make([]int64, len(keys))
+	seen := make(map[string]bool, len(keys))
+	var prev int64
+	for j, key := range keys {
+		if seen[key] {
+			return nil, false
+		}
+		seen[key] = true
+		position, ok := old[key]
+		if !ok {
+			position = prev + 1
+		}
+		if position <= prev || position >= next[j] {
+			return nil, false
+		}
+		positions[j] = position
+		prev = position
+	}
+	return positions, true
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+func formatStoredTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseStoredTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testData() SlangData {
+	slangData := emptySlangData()
+	slangData.Entries = []SlangEntry{{ID: "1", Word: "краш", Meaning: "объект симпатии", Synonyms: []string{"симпатия"}}}
+	slangData.Votes = map[string]map[string]int{"1": {"alice": 1}}
+	return slangData
+}
+
+// Изменения в полученных данных не должны попадать в кэш хранилища
+func TestFileStoreCacheIsolation(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "slang.json"))
+	if err := store.Save(testData()); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Entries[0].Word = "кринж"
+	loaded.Entries[0].Synonyms[0] = "стыд"
+	loaded.Votes["1"]["alice"] = -1
+	loaded.Entries = append(loaded.Entries, SlangEntry{ID: "2", Word: "изи"})
+
+	again, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Entries) != 1 {
+		t.Fatalf("записей %d, want 1", len(again.Entries))
+	}
+	if e := again.Entries[0]; e.Word != "краш" || e.Synonyms[0] != "симпатия" {
+		t.Errorf("запись изменилась в кэше: %+v", e)
+	}
+	if again.Votes["1"]["alice"] != 1 {
+		t.Errorf("голос изменился в кэше: %v", again.Votes)
+	}
+}
+
+// Файл, изменённый другой программой, перечитывается
+func TestFileStoreExternalChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slang.json")
+	store := NewFileStore(path)
+	if err := store.Save(testData()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	external := `{"version":"1.0","users":[],"entries":[{"id":"1","word":"кринж","meaning":"стыд"}]}`
+	if err := os.WriteFile(path, []byte(external), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0].Word != "кринж" {
+		t.Fatalf("не перечитан изменённый файл: %+v", loaded.Entries)
+	}
+
+	// Тот же размер, но другое время изменения
+	external = strings.Replace(external, "стыд", "тыдс", 1)
+	if err := os.WriteFile(path, []byte(external), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err = store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Entries[0].Meaning != "тыдс" {
+		t.Errorf("не перечитан файл того же размера: %+v", loaded.Entries[0])
+	}
+
+	// Испорченный файл восстанавливается (см. TestFileStoreCorruptFile), а не
+	// подменяется старыми данными из кэша
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err = store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Entries) != 0 {
+		t.Errorf("данные из кэша вместо восстановления: %+v", loaded.Entries)
+	}
+}
+
+// Modify видит результат предыдущего сохранения и не сохраняет ничего при ошибке
+func TestFileStoreModify(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "slang.json"))
+	if err := store.Save(testData()); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.Modify(func(slangData *SlangData) error {
+		slangData.Entries = append(slangData.Entries, SlangEntry{ID: "2", Word: "изи", Meaning: "легко"})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Modify(func(slangData *SlangData) error {
+		slangData.Entries = nil
+		return errEntryNotFound
+	})
+	if err != errEntryNotFound {
+		t.Fatalf("err = %v, want errEntryNotFound", err)
+	}
+
+	// Новое хранилище над тем же файлом читает с диска, минуя кэш
+	for _, s := range []*FileStore{store, NewFileStore(store.path)} {
+		loaded, err := s.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(loaded.Entries) != 2 || loaded.Entries[1].Word != "изи" {
+			t.Errorf("записи: %+v", loaded.Entries)
+		}
+	}
+}
+
+// Операции над записями и пользователями одинаково работают во всех хранилищах
+func TestStoreOperations(t *testing.T) {
+	dir := t.TempDir()
+	sqlite, err := openSQLiteStore(filepath.Join(dir, "slang.db"), filepath.Join(dir, "none.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+	stores := map[string]Store{
+		"json":   NewFileStore(filepath.Join(dir, "slang.json")),
+		"sqlite": sqlite,
+		"memory": NewMemoryStore(emptySlangData()),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Save(testData()); err != nil {
+				t.Fatal(err)
+			}
+
+			entry := SlangEntry{ID: "2", Word: "изи", Meaning: "легко", Owner: "alice", Author: "alice", LastEditedBy: "bob"}
+			if err := store.AddEntry(entry); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.AddEntry(SlangEntry{ID: "3", Word: "Изи", Meaning: "просто", Owner: "alice"}); err != errWordExists {
+				t.Errorf("повтор слова: err = %v, want errWordExists", err)
+			}
+			// У другого владельца своё пространство слов
+			if err := store.AddEntry(SlangEntry{ID: "4", Word: "изи", Meaning: "просто", Owner: "bob"}); err != nil {
+				t.Errorf("слово другого владельца: %v", err)
+			}
+
+			if err := store.DeleteEntry("1"); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.DeleteEntry("1"); err != errEntryNotFound {
+				t.Errorf("повторное удаление: err = %v, want errEntryNotFound", err)
+			}
+			entries, err := store.Entries()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 2 || entries[0].ID != "2" || entries[1].ID != "4" {
+				t.Errorf("записи: %+v", entries)
+			} else if entries[0].Author != "alice" || entries[0].LastEditedBy != "bob" {
+				t.Errorf("автор записи не сохранился: %+v", entries[0])
+			} else if entries[0].CanonicalWord != "изи" {
+				t.Errorf("каноническая форма слова не сохранилась: %+v", entries[0])
+			}
+			if loaded, _ := store.Load(); loaded.Votes["1"] != nil {
+				t.Error("голоса удалённой записи остались")
+			}
+
+			if _, err := store.GetUser("alice"); err != errUserNotFound {
+				t.Errorf("нет пользователя: err = %v, want errUserNotFound", err)
+			}
+			if err := store.SetUser(User{Username: " Alice ", Password: "hash1"}); err != nil {
+				t.Fatal(err)
+			}
+			changed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			if err := store.SetUser(User{Username: "alice", Password: "hash2", IsAdmin: true, CreatedAt: changed.AddDate(0, -1, 0), PasswordChangedAt: changed}); err != nil {
+				t.Fatal(err)
+			}
+			user, err := store.GetUser("ALICE")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.Username != "alice" || user.Password != "hash2" || !user.IsAdmin ||
+				!user.CreatedAt.Equal(changed.AddDate(0, -1, 0)) || !user.PasswordChangedAt.Equal(changed) {
+				t.Errorf("пользователь: %+v", user)
+			}
+			if loaded, _ := store.Load(); len(loaded.Users) != 1 {
+				t.Errorf("пользователей %d, want 1", len(loaded.Users))
+			}
+		})
+	}
+}
+
+// Хранилище в памяти читает файл-заготовку, но никогда его не меняет
+func TestMemoryStoreSeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slang.json")
+	if err := NewFileStore(path).Save(testData()); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := openMemoryStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddEntry(SlangEntry{ID: "2", Word: "изи", Meaning: "легко"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := store.Entries()
+	if len(entries) != 2 || entries[0].Word != "краш" {
+		t.Errorf("записи: %+v", entries)
+	}
+	// Изменение полученной копии не меняет хранилище
+	entries[0].Word = "изменено"
+	if again, _ := store.Entries(); again[0].Word != "краш" {
+		t.Error("хранилище отдало общий срез")
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Error("файл-заготовка изменился")
+	}
+
+	if empty, err := openMemoryStore(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Fatal(err)
+	} else if data, _ := empty.Load(); len(data.Entries) != 0 {
+		t.Errorf("без файла: %+v", data.Entries)
+	}
+}
+
+// Повреждённый файл откладывается, а словарь берётся из последней исправной копии
+func TestFileStoreCorruptFile(t *testing.T) {
+	corruptFiles := func(t *testing.T, dir string) []string {
+		t.Helper()
+		matches, err := filepath.Glob(filepath.Join(dir, "slang.json.corrupt.*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches
+	}
+	garbage := []byte(`{"entries": [{"word": "краш"`)
+
+	tests := []struct {
+		name        string
+		backups     map[string]string // имя копии -> содержимое
+		strict      bool
+		wantEntries int
+		wantErr     bool
+	}{
+		{name: "без копий", wantEntries: 0},
+		{
+			name: "из копии",
+			backups: map[string]string{
+				"slang-20260101-000000.000.json": `{"entries": [{"id": "1", "word": "краш", "meaning": "объект симпатии"}]}`,
+				// Самая свежая копия тоже повреждена и пропускается
+				"slang-20260102-000000.000.json": `{"entries": [`,
+			},
+			wantEntries: 1,
+		},
+		{name: "строгий режим", strict: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store := NewFileStore(filepath.Join(dir, "slang.json"))
+			store.backupDir = filepath.Join(dir, "backups")
+			store.strict = tt.strict
+			os.MkdirAll(store.backupDir, 0755)
+			for name, content := range tt.backups {
+				os.WriteFile(filepath.Join(store.backupDir, name), []byte(content), 0644)
+			}
+			os.WriteFile(store.path, garbage, 0644)
+
+			slangData, err := store.Load()
+			if tt.wantErr {
+				if !errors.Is(err, errCorruptData) {
+					t.Fatalf("err = %v, want errCorruptData", err)
+				}
+				if data, _ := os.ReadFile(store.path); string(data) != string(garbage) {
+					t.Error("в строгом режиме файл изменился")
+				}
+				if len(corruptFiles(t, dir)) != 0 {
+					t.Error("в строгом режиме файл переименован")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(slangData.Entries) != tt.wantEntries {
+				t.Errorf("записей %d, want %d", len(slangData.Entries), tt.wantEntries)
+			}
+
+			// Исходные байты сохранены для ручного восстановления
+			saved := corruptFiles(t, dir)
+			if len(saved) != 1 {
+				t.Fatalf("отложенных файлов: %v", saved)
+			}
+			if data, _ := os.ReadFile(saved[0]); string(data) != string(garbage) {
+				t.Errorf("отложенный файл: %s", data)
+			}
+			// Следующее чтение не находит повреждения
+			if again, err := store.Load(); err != nil || len(again.Entries) != tt.wantEntries {
+				t.Errorf("повторное чтение: %d записей, %v", len(again.Entries), err)
+			}
+		})
+	}
+}
+
+// SQLite пишет только изменившиеся строки и при этом сохраняет порядок записей,
+// даже если их переставили или вставили в середину
+func TestSQLiteStoreWritesChangedRows(t *testing.T) {
+	dir := t.TempDir()
+	store, err := openSQLiteStore(filepath.Join(dir, "slang.db"), filepath.Join(dir, "none.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	slangData := emptySlangData()
+	for i := range 50 {
+		id := strconv.Itoa(i)
+		slangData.Entries = append(slangData.Entries, SlangEntry{ID: id, Word: "слово" + id, Meaning: "значение"})
+		slangData.Users = append(slangData.Users, User{Username: "user" + id, Password: "hash"})
+	}
+	if err := store.Save(slangData); err != nil {
+		t.Fatal(err)
+	}
+
+	// Одна база, одно соединение: total_changes считает строки, записанные через него
+	changes := func() int {
+		var n int
+		if err := store.db.QueryRow(`SELECT total_changes()`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	ids := func() string {
+		loaded, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, entry := range loaded.Entries {
+			got = append(got, entry.ID)
+		}
+		return strings.Join(got, ",")
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(*SlangData)
+		maxChanges  int // вместе со строкой версии в meta
+		wantIDsHead string
+	}{
+		{
+			name:        "изменение записи",
+			modify:      func(d *SlangData) { d.Entries[10].Meaning = "новое значение" },
+			maxChanges:  2,
+			wantIDsHead: "0,1,2",
+		},
+		{
+			name:        "голос и пользователь",
+			modify:      func(d *SlangData) { d.Votes = map[string]map[string]int{"3": {"user1": 1}}; d.Users[5].IsAdmin = true },
+			maxChanges:  3,
+			wantIDsHead: "0,1,2",
+		},
+		{
+			name:        "удаление из середины",
+			modify:      func(d *SlangData) { d.Entries = slices.Delete(d.Entries, 1, 2) },
+			maxChanges:  2,
+			wantIDsHead: "0,2,3",
+		},
+		{
+			name: "вставка на место удалённой",
+			modify: func(d *SlangData) {
+				d.Entries = slices.Insert(d.Entries, 1, SlangEntry{ID: "new", Word: "новое", Meaning: "значение"})
+			},
+			maxChanges:  2,
+			wantIDsHead: "0,new,2",
+		},
+		{
+			name:        "перестановка",
+			modify:      func(d *SlangData) { d.Entries[0], d.Entries[2] = d.Entries[2], d.Entries[0] },
+			maxChanges:  1 + 2*50, // таблица записей переписывается целиком
+			wantIDsHead: "2,new,0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := changes()
+			if err := store.Modify(func(d *SlangData) error { tt.modify(d); return nil }); err != nil {
+				t.Fatal(err)
+			}
+			if n := changes() - start; n > tt.maxChanges {
+				t.Errorf("изменено строк: %d, want <= %d", n, tt.maxChanges)
+			}
+			if got := ids(); !strings.HasPrefix(got, tt.wantIDsHead+",") {
+				t.Errorf("порядок записей: %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Синонимы слова: те, что сами есть в словаре, раскрываются в полные записи,
+// остальные возвращаются строками
+func resolveSynonyms(entries []SlangEntry, synonyms []string) []interface{} {
+	resolved := make([]interface{}, 0, len(synonyms))
+	for _, synonym := range synonyms {
+		if i := findEntryIndex(entries, synonym); i >= 0 {
+			resolved = append(resolved, entries[i])
+		} else {
+			resolved = append(resolved, synonym)
+		}
+	}
+	return resolved
+}
+
+// GET /api/entries/by-word/{word}/synonyms (путь /api/entries/{word}/synonyms
+// пересекался бы с /api/entries/{index}/history, поэтому слово — под by-word, как у удаления)
+func handleGetSynonyms(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entries := visibleEntries(r, loadSlangData(s).Entries)
+		i := findEntryIndex(entries, r.PathValue("word"))
+		if i < 0 {
+			respondError(w, r, http.StatusNotFound, errCodeNotFound, tr(r, msgWordNotFound))
+			return
+		}
+		respondJSON(w, r, http.StatusOK, resolveSynonyms(entries, entries[i].Synonyms))
+	}
+}
+
+// Односторонняя связь: в синонимах записи word не хватает обратной ссылки на
+// слово MissingBacklinkTo, хотя оно указывает word своим синонимом
+type synonymIssue struct {
+	Word              string `json:"word"`
+	MissingBacklinkTo string `json:"missingBacklinkTo"`
+}
+
+// Поиск односторонних синонимов. Учитываются только синонимы, которые сами есть в словаре.
+func checkSynonyms(entries []SlangEntry) []synonymIssue {
+	issues := []synonymIssue{}
+	for _, entry := range entries {
+		for _, synonym := range entry.Synonyms {
+			j := findEntryIndex(entries, synonym)
+			if j < 0 || sameWord(entries[j].Word, entry.Word) {
+				continue
+			}
+			if !slices.ContainsFunc(entries[j].Synonyms, func(s string) bool { return sameWord(s, entry.Word) }) {
+				issues = append(issues, synonymIssue{Word: entries[j].Word, MissingBacklinkTo: entry.Word})
+			}
+		}
+	}
+	return issues
+}
+
+// Добавление недостающих обратных ссылок в словаре пользователя. Возвращает
+// исправленные связи; если запись упирается в лимит синонимов, связь остаётся как есть.
+// Все ссылки одной записи добавляются разом и меняют её как обычная правка:
+// одна версия в истории, новые время изменения, автор правки и ETag.
+func fixSynonyms(slangData *SlangData, owner string) []synonymIssue {
+	fixed := []synonymIssue{}
+	edited := make(map[int]SlangEntry)
+	var order []int
+	own := pickEntries(slangData.Entries, ownEntries(slangData.Entries, owner))
+	for _, issue := range checkSynonyms(own) {
+		i := findOwnEntryIndex(slangData.Entries, owner, issue.Word)
+		entry, ok := edited[i]
+		if !ok {
+			entry = slangData.Entries[i]
+			order = append(order, i)
+		}
+		entry.Synonyms = normalizeSynonyms(append(slices.Clone(entry.Synonyms), issue.MissingBacklinkTo))
+		if checkEntryLimits(entry) != nil {
+			continue
+		}
+		edited[i] = entry
+		fixed = append(fixed, issue)
+	}
+	for _, i := range order {
+		if entry, ok := edited[i]; ok {
+			updateEntry(slangData, i, entry, owner)
+		}
+	}
+	return fixed
+}
+
+// GET /api/entries/synonyms/check — только отчёт;
+// POST /api/entries/synonyms/check?fix=true — добавляет обратные ссылки и сохраняет
+func handleCheckSynonyms(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Query().Get("fix") != "true" {
+			entries := visibleEntries(r, loadSlangData(s).Entries)
+			respondJSON(w, r, http.StatusOK, checkSynonyms(entries))
+			return
+		}
+
+		var fixed []synonymIssue
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			fixed = fixSynonyms(slangData, usernameFromContext(r.Context()))
+			if len(fixed) == 0 {
+				return errNoChanges
+			}
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondJSON(w, r, http.StatusOK, fixed)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// Исправление синонимов — обычная правка записи: одна версия в истории на запись,
+// новый ETag и отметка о том, кто и когда менял
+func TestFixSynonymsRecordsHistory(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234",
+		SlangEntry{Word: "краш", Meaning: "симпатия", Example: "мой краш", Synonyms: []string{"симп"}},
+		SlangEntry{Word: "крашик", Meaning: "симпатия", Example: "мой крашик", Synonyms: []string{"симп"}},
+		SlangEntry{Word: "симп", Meaning: "поклонник", Example: "он симп"})
+	before := loadSlangData(store).Entries[2]
+
+	resp := doRequest(t, srv, "POST", "/api/entries/synonyms/check?fix=true", token, "")
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	slangData := loadSlangData(store)
+	entry := slangData.Entries[2]
+	if !slices.Equal(entry.Synonyms, []string{"краш", "крашик"}) {
+		t.Errorf("синонимы %v", entry.Synonyms)
+	}
+	if entry.LastEditedBy != "alice" || entry.UpdatedAt.IsZero() {
+		t.Errorf("нет отметки о правке: %+v", entry)
+	}
+	if entryETag(entry) == entryETag(before) {
+		t.Error("ETag не изменился")
+	}
+	// Две добавленные ссылки — одна правка
+	if history := slangData.History[entry.ID]; len(history) != 1 || len(history[0].Synonyms) != 0 {
+		t.Errorf("история %+v", history)
+	}
+	// Записи без исправлений не трогаются
+	for _, entry := range slangData.Entries[:2] {
+		if len(slangData.History[entry.ID]) != 0 || entry.LastEditedBy != "" {
+			t.Errorf("%s изменена: %+v", entry.Word, entry)
+		}
+	}
+}
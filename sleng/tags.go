@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Очистка тегов: теги хранятся в нижнем регистре без пробелов по краям,
+// пустые и повторы отбрасываются, порядок первого появления сохраняется
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// Есть ли у записи тег (без учёта регистра)
+func hasTag(entry SlangEntry, tag string) bool {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, t := range entry.Tags {
+		if strings.ToLower(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+type tagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// Все теги с количеством записей: сначала самые частые, при равенстве — по алфавиту
+func countTags(entries []SlangEntry) []tagCount {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		for _, tag := range entry.Tags {
+			counts[strings.ToLower(tag)]++
+		}
+	}
+	result := make([]tagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, tagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result
+}
+
+// GET /api/tags
+func handleTags(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entries := visibleEntries(r, loadSlangData(s).Entries)
+		respondJSON(w, r, http.StatusOK, countTags(entries))
+	}
+}
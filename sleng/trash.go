@@ -0,0 +1,131 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Удалённая запись в корзине
+type trashedEntry struct {
+	SlangEntry
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Удаление записи с номером i (с 0): по умолчанию в корзину, с permanent — насовсем
+func removeEntry(slangData *SlangData, i int, permanent bool) {
+	entry := slangData.Entries[i]
+	slangData.Entries = slices.Delete(slangData.Entries, i, i+1)
+	if permanent {
+		forgetEntry(slangData, entry.ID)
+		return
+	}
+	slangData.Trash = append(slangData.Trash, trashedEntry{SlangEntry: entry, DeletedAt: time.Now().UTC()})
+}
+
+// Удаление связанных с записью голосов и истории — когда её уже нельзя восстановить
+func forgetEntry(slangData *SlangData, id string) {
+	delete(slangData.Votes, id)
+	delete(slangData.History, id)
+}
+
+// Номер (с 0) записи пользователя в корзине или -1
+func findTrashed(slangData *SlangData, owner, id string) int {
+	return slices.IndexFunc(slangData.Trash, func(t trashedEntry) bool {
+		return t.ID == id && t.Owner == owner
+	})
+}
+
+// Очистка корзины от записей, удалённых больше days дней назад. Возвращает число удалённых.
+func purgeTrash(slangData *SlangData, days int, now time.Time) int {
+	cutoff := now.AddDate(0, 0, -days)
+	before := len(slangData.Trash)
+	slangData.Trash = slices.DeleteFunc(slangData.Trash, func(t trashedEntry) bool {
+		if t.DeletedAt.Before(cutoff) {
+			forgetEntry(slangData, t.ID)
+			return true
+		}
+		return false
+	})
+	return before - len(slangData.Trash)
+}
+
+// Очистка корзины при запуске
+func purgeTrashOnStartup(s Store, days int) {
+	var n int
+	err := modifySlangData(s, func(slangData *SlangData) error {
+		if n = purgeTrash(slangData, days, time.Now().UTC()); n == 0 {
+			return errNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("не удалось очистить корзину", "error", err)
+		return
+	}
+	if n > 0 {
+		logger.Info("корзина очищена", "days", days, "deleted", n)
+	}
+}
+
+// GET /api/trash — корзина текущего пользователя
+func handleGetTrash(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		slangData := loadSlangData(s)
+		username := usernameFromContext(r.Context())
+		trash := []trashedEntry{}
+		for _, t := range slangData.Trash {
+			if t.Owner == username {
+				trash = append(trash, t)
+			}
+		}
+		respondJSON(w, r, http.StatusOK, trash)
+	}
+}
+
+// POST /api/trash/{id}/restore — вернуть запись из корзины в конец словаря
+func handleRestoreTrash(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := usernameFromContext(r.Context())
+		var entry SlangEntry
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			i := findTrashed(slangData, username, r.PathValue("id"))
+			if i < 0 {
+				return errTrashNotFound
+			}
+			entry = slangData.Trash[i].SlangEntry
+			// Пока запись лежала в корзине, слово могли добавить заново
+			if ownerWordExists(slangData.Entries, username, entry.Word, -1) {
+				return errWordExists
+			}
+			slangData.Trash = slices.Delete(slangData.Trash, i, i+1)
+			slangData.Entries = append(slangData.Entries, entry)
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondJSON(w, r, http.StatusOK, entry)
+	}
+}
+
+// DELETE /api/trash/{id} — удалить запись из корзины насовсем
+func handleDeleteTrash(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			i := findTrashed(slangData, usernameFromContext(r.Context()), r.PathValue("id"))
+			if i < 0 {
+				return errTrashNotFound
+			}
+			forgetEntry(slangData, slangData.Trash[i].ID)
+			slangData.Trash = slices.Delete(slangData.Trash, i, i+1)
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondJSON(w, r, http.StatusOK, map[string]string{"message": tr(r, msgWordDeletedForever)})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"regexp"
+)
+
+// ————————————————————————
+//         Импорт из Urban Dictionary
+// ————————————————————————
+
+// Определение в формате API Urban Dictionary. Остальные поля (defid, permalink,
+// author, thumbs_up и т.п.) при импорте не нужны и пропускаются.
+type urbanDefinition struct {
+	Word       string `json:"word"`
+	Definition string `json:"definition"`
+	Example    string `json:"example"`
+}
+
+// Ответ API: {"list": [определения]}
+type urbanEnvelope struct {
+	List []urbanDefinition `json:"list"`
+}
+
+var errBadUrbanExport = errors.New("не экспорт Urban Dictionary")
+
+// Записи из экспорта Urban Dictionary. Принимается ответ API {"list": [...]},
+// массив таких ответов (несколько сохранённых страниц) или просто массив определений.
+// Порядок сохраняется: в API определения идут от самых популярных, и при
+// повторах слова импортируется первое.
+func parseUrbanExport(data []byte) ([]SlangEntry, error) {
+	data = bytes.TrimSpace(data)
+	var definitions []urbanDefinition
+	switch {
+	case bytes.HasPrefix(data, []byte("{")):
+		var envelope urbanEnvelope
+		if err := json.Unmarshal(data, &envelope); err != nil || envelope.List == nil {
+			return nil, errBadUrbanExport
+		}
+		definitions = envelope.List
+	case bytes.HasPrefix(data, []byte("[")):
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, errBadUrbanExport
+		}
+		for _, item := range items {
+			var envelope urbanEnvelope
+			if err := json.Unmarshal(item, &envelope); err == nil && envelope.List != nil {
+				definitions = append(definitions, envelope.List...)
+				continue
+			}
+			var definition urbanDefinition
+			if err := json.Unmarshal(item, &definition); err != nil {
+				return nil, errBadUrbanExport
+			}
+			definitions = append(definitions, definition)
+		}
+	default:
+		return nil, errBadUrbanExport
+	}
+
+	entries := make([]SlangEntry, 0, len(definitions))
+	for _, d := range definitions {
+		entries = append(entries, SlangEntry{
+			Word:    stripUrbanLinks(d.Word),
+			Meaning: stripUrbanLinks(d.Definition),
+			Example: stripUrbanLinks(d.Example),
+		})
+	}
+	return entries, nil
+}
+
+// Ссылки на другие слова Urban Dictionary пишет в квадратных скобках: [rizz]
+var urbanLink = regexp.MustCompile(`\[([^\[\]]*)\]`)
+
+// Текст без разметки ссылок: "has [rizz]" -> "has rizz"
+func stripUrbanLinks(s string) string {
+	return urbanLink.ReplaceAllString(s, "$1")
+}
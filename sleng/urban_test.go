@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestStripUrbanLinks(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"[rizz]", "rizz"},
+		{"He has [mad] [rizz], no [cap].", "He has mad rizz, no cap."},
+		{"no links", "no links"},
+		{"[]", ""},
+		{"[unclosed", "[unclosed"},
+		{"[[nested]]", "[nested]"},
+	}
+	for _, tt := range tests {
+		if got := stripUrbanLinks(tt.in); got != tt.want {
+			t.Errorf("stripUrbanLinks(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseUrbanExport(t *testing.T) {
+	definition := `{"definition":"Short for [charisma].","permalink":"http://rizz.urbanup.com/1","thumbs_up":100,"author":"x","word":"rizz","defid":1,"written_on":"2021-01-01T00:00:00.000Z","example":"He has [mad] rizz.","thumbs_down":3}`
+	inputs := map[string]string{
+		"envelope":           `{"list":[` + definition + `]}`,
+		"array of envelopes": `[{"list":[` + definition + `]}, {"list":[]}]`,
+		"array":              `[` + definition + `]`,
+	}
+	for name, input := range inputs {
+		entries, err := parseUrbanExport([]byte(input))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(entries) != 1 {
+			t.Errorf("%s: %d записей, want 1", name, len(entries))
+			continue
+		}
+		e := entries[0]
+		if e.Word != "rizz" || e.Meaning != "Short for charisma." || e.Example != "He has mad rizz." {
+			t.Errorf("%s: %+v", name, e)
+		}
+	}
+
+	for _, bad := range []string{``, `"rizz"`, `{"word":"rizz"}`, `[1, 2]`, `{"list":`} {
+		if _, err := parseUrbanExport([]byte(bad)); err == nil {
+			t.Errorf("%q: ожидалась ошибка", bad)
+		}
+	}
+}
+
+func TestImportUrban(t *testing.T) {
+	srv, store := newTestServer(t)
+	token := seedUser(t, store, "alice", "1234", SlangEntry{Word: "краш", Meaning: "объект симпатии"})
+
+	// «краш» уже есть в словаре, второе определение «rizz» повторяет первое
+	body := `{"list":[
+		{"word":"rizz","definition":"[Charisma], the ability to attract.","example":"He has [mad] rizz."},
+		{"word":"краш","definition":"crush"},
+		{"word":"rizz","definition":"Another definition."},
+		{"word":"no cap","definition":"For real, no [lie]."}
+	]}`
+	resp := doRequest(t, srv, "POST", "/api/entries/import?source=urban", token, body)
+	if resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	var result importResult
+	if err := json.Unmarshal(resp.body, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 2 || result.Skipped != 2 {
+		t.Errorf("added = %d, skipped = %d; want 2, 2", result.Added, result.Skipped)
+	}
+
+	data, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Entries) != 3 {
+		t.Fatalf("записей %d, want 3", len(data.Entries))
+	}
+	if e := data.Entries[1]; e.Word != "rizz" || e.Meaning != "Charisma, the ability to attract." || e.Example != "He has mad rizz." || e.Owner != "alice" {
+		t.Errorf("импортировано: %+v", e)
+	}
+
+	expectError(t, doRequest(t, srv, "POST", "/api/entries/import?source=urban", token, `[{"word":"x"}`), http.StatusBadRequest, errCodeInvalidJSON)
+	expectError(t, doRequest(t, srv, "POST", "/api/entries/import?source=reddit", token, `[]`), http.StatusBadRequest, errCodeInvalidParameter)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ограничения на размер полей записи (в символах, а не байтах, чтобы
+// кириллица не считалась вдвое длиннее). Настраиваются переменными окружения.
+type entryLimits struct {
+	MaxWord     int
+	MaxMeaning  int
+	MaxExample  int
+	MaxOrigin   int
+	MaxSynonym  int
+	MaxSynonyms int
+	MaxTag      int
+	MaxTags     int
+}
+
+var limits = entryLimits{
+	MaxWord:     envInt("SLENG_MAX_WORD", 100),
+	MaxMeaning:  envInt("SLENG_MAX_MEANING", 2000),
+	MaxExample:  envInt("SLENG_MAX_EXAMPLE", 2000),
+	MaxOrigin:   envInt("SLENG_MAX_ORIGIN", 500),
+	MaxSynonym:  envInt("SLENG_MAX_SYNONYM", 100),
+	MaxSynonyms: envInt("SLENG_MAX_SYNONYMS", 20),
+	MaxTag:      envInt("SLENG_MAX_TAG", 50),
+	MaxTags:     envInt("SLENG_MAX_TAGS", 10),
+}
+
+// Требования к паролю; по умолчанию — только минимальная длина, как раньше.
+// Строже настраивается переменными окружения, например SLENG_PASSWORD_REQUIRE_DIGIT=true.
+type passwordRules struct {
+	MinLength        int
+	RequireDigit     bool
+	RequireLetter    bool
+	RequireMixedCase bool // и строчные, и заглавные буквы
+}
+
+var passwordPolicy = passwordRules{
+	MinLength:        envInt("SLENG_PASSWORD_MIN_LENGTH", minPasswordLength),
+	RequireDigit:     envBool("SLENG_PASSWORD_REQUIRE_DIGIT"),
+	RequireLetter:    envBool("SLENG_PASSWORD_REQUIRE_LETTER"),
+	RequireMixedCase: envBool("SLENG_PASSWORD_REQUIRE_MIXED_CASE"),
+}
+
+// Ошибка проверки: какое поле не прошло и почему (текст переводится)
+type ValidationError struct {
+	Field string
+	err   error
+}
+
+func (e *ValidationError) Error() string { return e.err.Error() }
+func (e *ValidationError) Unwrap() error { return e.err }
+
+func invalidField(field string, id msgID, args ...any) error {
+	return &ValidationError{Field: field, err: newMsgError(id, args...)}
+}
+
+// Проверка записи перед сохранением — одна и та же для API и консоли:
+// слово и значение обязательны, поля не длиннее лимитов
+func ValidateEntry(entry SlangEntry) error {
+	if strings.TrimSpace(entry.Word) == "" {
+		return invalidField("word", msgWordAndMeaningRequired)
+	}
+	if strings.TrimSpace(entry.Meaning) == "" {
+		return invalidField("meaning", msgWordAndMeaningRequired)
+	}
+	return checkEntryLimits(entry)
+}
+
+// Проверка нового пользователя (Password — ещё не захешированный пароль)
+func ValidateUser(user User) error {
+	if err := validateUsername(user.Username); err != nil {
+		return err
+	}
+	return validatePassword(user.Password)
+}
+
+func validateUsername(username string) error {
+	if normalizeUsername(username) == "" {
+		return invalidField("username", msgUsernameEmpty)
+	}
+	return nil
+}
+
+// Проверка пароля по passwordPolicy; ошибка называет первое невыполненное требование.
+// Длина считается в символах, как и длина полей записи.
+func validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < passwordPolicy.MinLength {
+		return invalidField("password", msgPasswordMinLength, passwordPolicy.MinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return invalidField("password", msgPasswordTooLong, maxPasswordBytes)
+	}
+	var digit, letter, lower, upper bool
+	for _, r := range password {
+		switch {
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsLetter(r):
+			letter = true
+			lower = lower || unicode.IsLower(r)
+			upper = upper || unicode.IsUpper(r)
+		}
+	}
+	switch {
+	case passwordPolicy.RequireDigit && !digit:
+		return invalidField("password", msgPasswordNeedsDigit)
+	case passwordPolicy.RequireLetter && !letter:
+		return invalidField("password", msgPasswordNeedsLetter)
+	case passwordPolicy.RequireMixedCase && !(lower && upper):
+		return invalidField("password", msgPasswordNeedsMixedCase)
+	}
+	return nil
+}
+
+// Проверка длины полей; ошибка называет поле, которое не прошло проверку
+func checkEntryLimits(entry SlangEntry) error {
+	fields := []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"word", entry.Word, limits.MaxWord},
+		{"meaning", entry.Meaning, limits.MaxMeaning},
+		{"example", entry.Example, limits.MaxExample},
+		{"origin", entry.Origin, limits.MaxOrigin},
+	}
+	for _, f := range fields {
+		if n := utf8.RuneCountInString(f.value); n > f.max {
+			return invalidField(f.name, msgFieldTooLong, f.name, n, f.max)
+		}
+	}
+
+	if len(entry.Synonyms) > limits.MaxSynonyms {
+		return invalidField("synonyms", msgTooManySynonyms, len(entry.Synonyms), limits.MaxSynonyms)
+	}
+	for _, synonym := range entry.Synonyms {
+		if n := utf8.RuneCountInString(synonym); n > limits.MaxSynonym {
+			return invalidField("synonyms", msgSynonymTooLong, synonym, n, limits.MaxSynonym)
+		}
+	}
+
+	if len(entry.Tags) > limits.MaxTags {
+		return invalidField("tags", msgTooManyTags, len(entry.Tags), limits.MaxTags)
+	}
+	for _, tag := range entry.Tags {
+		if n := utf8.RuneCountInString(tag); n > limits.MaxTag {
+			return invalidField("tags", msgTagTooLong, tag, n, limits.MaxTag)
+		}
+	}
+	return nil
+}
+
+// Очистка синонимов: обрезает пробелы, убирает пустые и повторы
+// (без учёта регистра), сохраняя порядок первого появления
+func normalizeSynonyms(synonyms []string) []string {
+	if len(synonyms) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(synonyms))
+	result := make([]string, 0, len(synonyms))
+	for _, synonym := range synonyms {
+		synonym = strings.TrimSpace(synonym)
+		key := strings.ToLower(synonym)
+		if synonym == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, synonym)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// Нормальная форма слова для сравнения: без пробелов по краям, с одиночными
+// пробелами внутри, в нижнем регистре и с «ё», заменённой на «е»
+func normalizeWord(word string) string {
+	word = strings.ToLower(strings.Join(strings.Fields(word), " "))
+	return strings.ReplaceAll(word, "ё", "е")
+}
+
+// Одно и то же ли это слово с точки зрения словаря
+func sameWord(a, b string) bool {
+	return normalizeWord(a) == normalizeWord(b)
+}
+
+// Каноническая форма слова записи: сохранённая, а у записи, которую ещё
+// не сохраняли (например, в тестах), — вычисленная из Word
+func (e SlangEntry) canonical() string {
+	if e.CanonicalWord != "" {
+		return e.CanonicalWord
+	}
+	return normalizeWord(e.Word)
+}
+
+// Заполнение CanonicalWord перед сохранением: так она не расходится со словом,
+// где бы его ни изменили
+func setCanonicalWords(entries []SlangEntry) {
+	for i := range entries {
+		entries[i].CanonicalWord = normalizeWord(entries[i].Word)
+	}
+}
+
+// Есть ли слово в списке; запись с номером skip (с 0) не учитывается, -1 — проверять все
+func wordExists(entries []SlangEntry, word string, skip int) bool {
+	word = normalizeWord(word)
+	for i, e := range entries {
+		if i != skip && e.canonical() == word {
+			return true
+		}
+	}
+	return false
+}
+
+// Номер записи (с 0) с указанным словом или -1, если такого слова нет
+func findEntryIndex(entries []SlangEntry, word string) int {
+	word = normalizeWord(word)
+	for i, e := range entries {
+		if e.canonical() == word {
+			return i
+		}
+	}
+	return -1
+}
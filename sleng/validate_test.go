@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSameWord(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"lit", "lit", true},
+		{"lit ", "lit", true},
+		{"  lit", "lit\t", true},
+		{"LiT", "lit", true},
+		{"Краш", "КРАШ", true},
+		{"ёлка", "елка", true},
+		{"Ёжик", "ежик", true},
+		{"чё как", "че   как", true},
+		{"на  чиле", " на чиле ", true},
+		{"lit", "lite", false},
+		{"на чиле", "начиле", false},
+		{"краш", "крашик", false},
+	}
+	for _, tt := range tests {
+		if got := sameWord(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameWord(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestWordExists(t *testing.T) {
+	entries := []SlangEntry{{Word: "краш"}, {Word: "Лол "}}
+
+	if !wordExists(entries, " КРАШ", -1) {
+		t.Error("expected duplicate for ' КРАШ'")
+	}
+	if !wordExists(entries, "лол", -1) {
+		t.Error("expected duplicate for stored word with trailing space")
+	}
+	if wordExists(entries, "краш", 0) {
+		t.Error("entry being skipped must not count as duplicate")
+	}
+	if wordExists(entries, "кринж", -1) {
+		t.Error("unexpected duplicate for 'кринж'")
+	}
+}
+
+func TestValidateEntry(t *testing.T) {
+	valid := SlangEntry{Word: "краш", Meaning: "объект симпатии"}
+	tests := []struct {
+		name  string
+		edit  func(e *SlangEntry)
+		field string // "" — запись должна пройти проверку
+	}{
+		{"valid", func(e *SlangEntry) {}, ""},
+		{"empty word", func(e *SlangEntry) { e.Word = "" }, "word"},
+		{"blank word", func(e *SlangEntry) { e.Word = " \t " }, "word"},
+		{"empty meaning", func(e *SlangEntry) { e.Meaning = "" }, "meaning"},
+		{"word at limit", func(e *SlangEntry) { e.Word = strings.Repeat("я", limits.MaxWord) }, ""},
+		{"word over limit", func(e *SlangEntry) { e.Word = strings.Repeat("я", limits.MaxWord+1) }, "word"},
+		{"meaning over limit", func(e *SlangEntry) { e.Meaning = strings.Repeat("a", limits.MaxMeaning+1) }, "meaning"},
+		{"example over limit", func(e *SlangEntry) { e.Example = strings.Repeat("a", limits.MaxExample+1) }, "example"},
+		{"origin at limit", func(e *SlangEntry) { e.Origin = strings.Repeat("ё", limits.MaxOrigin) }, ""},
+		{"origin over limit", func(e *SlangEntry) { e.Origin = strings.Repeat("ё", limits.MaxOrigin+1) }, "origin"},
+		{"synonyms at limit", func(e *SlangEntry) { e.Synonyms = make([]string, limits.MaxSynonyms) }, ""},
+		{"too many synonyms", func(e *SlangEntry) { e.Synonyms = make([]string, limits.MaxSynonyms+1) }, "synonyms"},
+		{"synonym over limit", func(e *SlangEntry) { e.Synonyms = []string{strings.Repeat("a", limits.MaxSynonym+1)} }, "synonyms"},
+		{"tags at limit", func(e *SlangEntry) { e.Tags = make([]string, limits.MaxTags) }, ""},
+		{"too many tags", func(e *SlangEntry) { e.Tags = make([]string, limits.MaxTags+1) }, "tags"},
+		{"tag over limit", func(e *SlangEntry) { e.Tags = []string{strings.Repeat("a", limits.MaxTag+1)} }, "tags"},
+	}
+	for _, tt := range tests {
+		entry := valid
+		tt.edit(&entry)
+		err := ValidateEntry(entry)
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) {
+			t.Errorf("%s: expected ValidationError, got %v", tt.name, err)
+			continue
+		}
+		if invalid.Field != tt.field {
+			t.Errorf("%s: field = %q, want %q", tt.name, invalid.Field, tt.field)
+		}
+	}
+}
+
+func TestValidateUser(t *testing.T) {
+	tests := []struct {
+		username, password string
+		field              string
+	}{
+		{"admin", "1234", ""},
+		{"admin", strings.Repeat("x", minPasswordLength), ""},
+		{"admin", strings.Repeat("x", minPasswordLength-1), "password"},
+		{"admin", "", "password"},
+		// Длина в символах: три кириллические буквы — это 6 байт, но всё равно мало
+		{"admin", "абв", "password"},
+		{"admin", "абвг", ""},
+		{"admin", strings.Repeat("x", maxPasswordBytes), ""},
+		// 40 кириллических букв — 80 байт, больше предела bcrypt
+		{"admin", strings.Repeat("ю", 40), "password"},
+		{"", "1234", "username"},
+		{"   ", "1234", "username"},
+		{"", "", "username"},
+	}
+	for _, tt := range tests {
+		err := ValidateUser(User{Username: tt.username, Password: tt.password})
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("ValidateUser(%q, %q): unexpected error %v", tt.username, tt.password, err)
+			}
+			continue
+		}
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) || invalid.Field != tt.field {
+			t.Errorf("ValidateUser(%q, %q) = %v, want error for field %s", tt.username, tt.password, err, tt.field)
+		}
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	defaults := passwordPolicy
+	t.Cleanup(func() { passwordPolicy = defaults })
+	passwordPolicy = passwordRules{MinLength: 8, RequireDigit: true, RequireLetter: true, RequireMixedCase: true}
+
+	tests := []struct {
+		password string
+		want     msgID // "" — пароль подходит
+	}{
+		{"Passw0rd", ""},
+		{"Пароль2024", ""},
+		// Восемь кириллических букв — 16 байт, но длина считается в символах
+		{"Пар0ль1", msgPasswordMinLength},
+		{"Password", msgPasswordNeedsDigit},
+		{"12345678", msgPasswordNeedsLetter},
+		{"passw0rd", msgPasswordNeedsMixedCase},
+		{"PASSW0RD", msgPasswordNeedsMixedCase},
+	}
+	for _, tt := range tests {
+		err := validatePassword(tt.password)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("validatePassword(%q): unexpected error %v", tt.password, err)
+			}
+			continue
+		}
+		var me *msgError
+		if !errors.As(err, &me) || me.id != tt.want {
+			t.Errorf("validatePassword(%q) = %v, want %s", tt.password, err, tt.want)
+		}
+	}
+}
+
+func TestValidationErrorTranslation(t *testing.T) {
+	err := ValidateEntry(SlangEntry{Word: "краш"})
+	if got := errorText(langRU, err); got != "Слово и значение обязательны" {
+		t.Errorf("ru text = %q", got)
+	}
+	if got := errorText(langEN, err); got != "Word and meaning are required" {
+		t.Errorf("en text = %q", got)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import "net/http"
+
+// Пределы рейтинга записи: защищают от переполнения при накрутке голосов
+const (
+	maxScore = 1_000_000
+	minScore = -maxScore
+)
+
+func clampScore(score int) int {
+	return min(max(score, minScore), maxScore)
+}
+
+// Голос пользователя: "up", "down" или "" (не голосовал)
+func voteDirection(vote int) string {
+	switch vote {
+	case 1:
+		return "up"
+	case -1:
+		return "down"
+	}
+	return ""
+}
+
+// Учёт голоса: каждый пользователь голосует за запись один раз. Повторный голос
+// в ту же сторону ничего не меняет, смена стороны сдвигает рейтинг на 2.
+func applyVote(slangData *SlangData, i int, username string, vote int) {
+	entry := &slangData.Entries[i]
+	if slangData.Votes == nil {
+		slangData.Votes = make(map[string]map[string]int)
+	}
+	if slangData.Votes[entry.ID] == nil {
+		slangData.Votes[entry.ID] = make(map[string]int)
+	}
+	previous := slangData.Votes[entry.ID][username]
+	slangData.Votes[entry.ID][username] = vote
+	entry.Score = clampScore(entry.Score + vote - previous)
+}
+
+// POST /api/entries/{index}/vote — {"direction": "up"|"down"}.
+// Номер — как в списке, который видит пользователь (общий словарь — с ?shared=true).
+// Ответ — запись и текущий голос пользователя за неё.
+func handleVote(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, ok := parseEntryIndex(r)
+		if !ok {
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidIndex))
+			return
+		}
+
+		var req struct {
+			Direction string `json:"direction"`
+		}
+		if err := readJSON(w, r, &req); err != nil {
+			respondReadError(w, r, err, msgInvalidJSON)
+			return
+		}
+		var vote int
+		switch req.Direction {
+		case "up":
+			vote = 1
+		case "down":
+			vote = -1
+		default:
+			respondError(w, r, http.StatusBadRequest, errCodeInvalidParameter, tr(r, msgInvalidDirection))
+			return
+		}
+
+		username := usernameFromContext(r.Context())
+		var entry SlangEntry
+		err := modifySlangData(s, func(slangData *SlangData) error {
+			visible := visibleIndexes(r, slangData.Entries)
+			if index > len(visible) {
+				return errEntryNotFound
+			}
+			i := visible[index-1]
+			if slangData.Votes[slangData.Entries[i].ID][username] == vote {
+				entry = slangData.Entries[i]
+				return errNoChanges
+			}
+			applyVote(slangData, i, username, vote)
+			entry = slangData.Entries[i]
+			return nil
+		})
+		if err != nil {
+			respondModifyError(w, r, err)
+			return
+		}
+		respondJSON(w, r, http.StatusOK, map[string]interface{}{
+			"entry": entry,
+			"vote":  voteDirection(vote),
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ————————————————————————
+//         Webhooks
+// ————————————————————————
+
+// Тело POST-запроса на webhook. Поле text — готовое сообщение, поэтому
+// адрес входящего webhook Slack можно указывать напрямую.
+type webhookPayload struct {
+	Event      string      `json:"event"` // added, updated, deleted
+	ID         string      `json:"id"`
+	Index      int         `json:"index,omitempty"`
+	Entry      *SlangEntry `json:"entry,omitempty"`
+	Text       string      `json:"text"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+const (
+	// Очередь доставки на один адрес; при переполнении новые события отбрасываются
+	webhookQueue = 256
+	// Попыток доставки одного события, между ними пауза растёт вдвое
+	webhookAttempts = 4
+	webhookTimeout  = 5 * time.Second
+)
+
+// Отправка событий на адреса из настройки webhooks. У каждого адреса своя
+// очередь и своя горутина: недоступный адрес не задерживает ни API, ни другие адреса.
+type webhookDispatcher struct {
+	targets    []*webhookTarget
+	secret     []byte
+	client     *http.Client
+	retryDelay time.Duration // пауза перед второй попыткой
+}
+
+type webhookTarget struct {
+	url   string
+	queue chan webhookDelivery
+}
+
+type webhookDelivery struct {
+	event string
+	body  []byte
+}
+
+// Настроенные webhooks; nil — не настроены
+var webhooks *webhookDispatcher
+
+// Адреса через запятую; пустая строка — webhooks отключены (возвращается nil).
+// Если задан secret, каждый запрос подписывается HMAC-SHA256 тела.
+func newWebhookDispatcher(urls, secret string) (*webhookDispatcher, error) {
+	d := &webhookDispatcher{
+		secret:     []byte(secret),
+		client:     &http.Client{Timeout: webhookTimeout},
+		retryDelay: time.Second,
+	}
+	for _, raw := range strings.Split(urls, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("неверный адрес webhook: %s", raw)
+		}
+		target := &webhookTarget{url: raw, queue: make(chan webhookDelivery, webhookQueue)}
+		d.targets = append(d.targets, target)
+		go d.run(target)
+	}
+	if len(d.targets) == 0 {
+		return nil, nil
+	}
+	if secret == "" {
+		logger.Warn("SLENG_WEBHOOK_SECRET не задан, запросы на webhooks не подписываются")
+	}
+	return d, nil
+}
+
+// Постановка событий в очереди доставки. Личные записи наружу не отправляются.
+func (d *webhookDispatcher) dispatch(events []entryEvent) {
+	if d == nil {
+		return
+	}
+	now := time.Now().UTC()
+	for _, event := range events {
+		if event.private || event.scoreOnly {
+			continue
+		}
+		body, err := json.Marshal(webhookPayload{
+			Event:      event.Type,
+			ID:         event.ID,
+			Index:      event.Index,
+			Entry:      event.Entry,
+			Text:       webhookText(event),
+			OccurredAt: now,
+		})
+		if err != nil {
+			continue
+		}
+		for _, target := range d.targets {
+			select {
+			case target.queue <- webhookDelivery{event: event.Type, body: body}:
+			default:
+				logger.Warn("очередь webhook переполнена, событие отброшено", "url", target.url, "event", event.Type)
+			}
+		}
+	}
+}
+
+func webhookText(event entryEvent) string {
+	switch event.Type {
+	case "added":
+		return translate(defaultLang, msgWebhookAdded, event.word, event.Entry.Meaning)
+	case "updated":
+		return translate(defaultLang, msgWebhookUpdated, event.word)
+	default:
+		return translate(defaultLang, msgWebhookDeleted, event.word)
+	}
+}
+
+func (d *webhookDispatcher) run(target *webhookTarget) {
+	for delivery := range target.queue {
+		delay := d.retryDelay
+		for attempt := 1; ; attempt++ {
+			err := d.deliver(target.url, delivery)
+			if err == nil {
+				break
+			}
+			if attempt == webhookAttempts {
+				logger.Error("webhook не доставлен", "url", target.url, "event", delivery.event, "attempts", attempt, "error", err)
+				break
+			}
+			logger.Warn("ошибка доставки webhook, повтор", "url", target.url, "event", delivery.event, "attempt", attempt, "error", err)
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+}
+
+// Одна попытка доставки; успех — любой ответ 2xx
+func (d *webhookDispatcher) deliver(target string, delivery webhookDelivery) error {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(delivery.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Sleng-Webhook/1.0")
+	req.Header.Set("X-Sleng-Event", delivery.event)
+	req.Header.Set("X-Sleng-Delivery", uuid.NewString())
+	if len(d.secret) > 0 {
+		req.Header.Set("X-Sleng-Signature", signWebhook(d.secret, delivery.body))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("ответ %d", res.StatusCode)
+	}
+	return nil
+}
+
+// Подпись тела запроса: "sha256=" и HMAC-SHA256 в hex. Получатель считает её
+// тем же общим секретом и сравнивает с заголовком X-Sleng-Signature.
+func signWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type receivedWebhook struct {
+	header  http.Header
+	body    []byte
+	payload webhookPayload
+}
+
+// Получатель webhooks: первые failures запросов получают 500
+func newWebhookReceiver(t *testing.T, failures int32) (*httptest.Server, <-chan receivedWebhook, *atomic.Int32) {
+	t.Helper()
+	received := make(chan receivedWebhook, 16)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		hook := receivedWebhook{header: r.Header, body: body}
+		json.Unmarshal(body, &hook.payload)
+		received <- hook
+	}))
+	t.Cleanup(srv.Close)
+	return srv, received, &calls
+}
+
+func waitWebhook(t *testing.T, received <-chan receivedWebhook) receivedWebhook {
+	t.Helper()
+	select {
+	case hook := <-received:
+		return hook
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook не пришёл")
+	}
+	return receivedWebhook{}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	receiver, received, _ := newWebhookReceiver(t, 0)
+	d, err := newWebhookDispatcher(receiver.URL, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.dispatch(entryChanges(nil, []SlangEntry{
+		{ID: "1", Word: "секрет", Meaning: "личное слово", Private: true},
+		{ID: "2", Word: "краш", Meaning: "объект симпатии"},
+	}))
+
+	// Личное слово не отправляется, поэтому первым приходит «краш»
+	hook := waitWebhook(t, received)
+	if hook.payload.Event != "added" || hook.payload.ID != "2" || hook.payload.Entry == nil || hook.payload.Entry.Word != "краш" {
+		t.Errorf("payload: %+v", hook.payload)
+	}
+	if hook.payload.Text != "Добавлено слово «краш»: объект симпатии" {
+		t.Errorf("text = %q", hook.payload.Text)
+	}
+	if got := hook.header.Get("X-Sleng-Event"); got != "added" {
+		t.Errorf("X-Sleng-Event = %q", got)
+	}
+	if got, want := hook.header.Get("X-Sleng-Signature"), signWebhook([]byte("s3cret"), hook.body); got != want {
+		t.Errorf("X-Sleng-Signature = %q, want %q", got, want)
+	}
+	if hook.header.Get("X-Sleng-Delivery") == "" {
+		t.Error("нет X-Sleng-Delivery")
+	}
+
+	// Голос меняет только счёт: webhook не отправляется, и следующим приходит удаление
+	d.dispatch(entryChanges([]SlangEntry{{ID: "2", Word: "краш", Meaning: "объект симпатии"}},
+		[]SlangEntry{{ID: "2", Word: "краш", Meaning: "объект симпатии", Score: 1}}))
+	d.dispatch(entryChanges([]SlangEntry{{ID: "2", Word: "краш", Meaning: "объект симпатии"}}, nil))
+	hook = waitWebhook(t, received)
+	if hook.payload.Event != "deleted" || hook.payload.Index != 1 || hook.payload.Text != "Удалено слово «краш»" {
+		t.Errorf("удаление: %+v", hook.payload)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	receiver, received, calls := newWebhookReceiver(t, 2)
+	d, err := newWebhookDispatcher(receiver.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.retryDelay = time.Millisecond
+
+	d.dispatch(entryChanges(nil, []SlangEntry{{ID: "1", Word: "краш", Meaning: "объект симпатии"}}))
+	hook := waitWebhook(t, received)
+	if n := calls.Load(); n != 3 {
+		t.Errorf("попыток %d, want 3", n)
+	}
+	if hook.header.Get("X-Sleng-Signature") != "" {
+		t.Error("без секрета подписи быть не должно")
+	}
+}
+
+func TestNewWebhookDispatcher(t *testing.T) {
+	if d, err := newWebhookDispatcher(" , ", "secret"); d != nil || err != nil {
+		t.Errorf("пустой список: %v, %v", d, err)
+	}
+	for _, bad := range []string{"ftp://example.com/hook", "example.com/hook", "http://"} {
+		if _, err := newWebhookDispatcher(bad, ""); err == nil {
+			t.Errorf("%q: ожидалась ошибка", bad)
+		}
+	}
+	// Вызов у nil — webhooks не настроены — ничего не делает
+	var d *webhookDispatcher
+	d.dispatch([]entryEvent{{Type: "added"}})
+}
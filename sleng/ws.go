@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return corsOrigin == "*" || origin == "" || origin == corsOrigin
+	},
+}
+
+// GET /api/ws — поток событий {"type": "added"|"updated"|"deleted", ...} по WebSocket
+func handleWebSocket(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := streamUser(s, r)
+		if err != nil {
+			respondAuthError(w, r, err)
+			return
+		}
+
+		// При ошибке Upgrade сам отвечает клиенту
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		sub, _ := liveEvents.subscribe(username, -1)
+		go wsWriteLoop(conn, sub)
+		wsReadLoop(conn)
+		liveEvents.unsubscribe(sub)
+	}
+}
+
+// Чтение нужно только для ответов на ping и обнаружения отключения;
+// сообщения от клиента игнорируются
+func wsReadLoop(conn *websocket.Conn) {
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func wsWriteLoop(conn *websocket.Conn, sub *subscriber) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+	for {
+		select {
+		case msg, ok := <-sub.send:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, msg.data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func dialEvents(t *testing.T, url, token string) *websocket.Conn {
+	t.Helper()
+	url = "ws" + strings.TrimPrefix(url, "http") + "/api/ws"
+	if token != "" {
+		url += "?token=" + token
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) entryEvent {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event entryEvent
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestWebSocketEvents(t *testing.T) {
+	srv, store := newTestServer(t)
+	alice := seedUser(t, store, "alice", "1234")
+	bob := seedUser(t, store, "bob", "1234")
+
+	anonymous := dialEvents(t, srv.URL, "")
+	owner := dialEvents(t, srv.URL, alice)
+	// Обработчик регистрирует клиента после Upgrade, поэтому ждём, пока оба появятся
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		liveEvents.mu.Lock()
+		n := len(liveEvents.subscribers)
+		liveEvents.mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("клиенты не подключились")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Личное слово видит только владелец
+	if resp := doRequest(t, srv, "POST", "/api/entries", alice, `{"word":"секрет","meaning":"личное слово","private":true}`); resp.status != http.StatusCreated {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	if resp := doRequest(t, srv, "POST", "/api/entries", bob, `{"word":"краш","meaning":"объект симпатии"}`); resp.status != http.StatusCreated {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+
+	event := readEvent(t, owner)
+	if event.Type != "added" || event.Entry == nil || event.Entry.Word != "секрет" {
+		t.Errorf("владелец: %+v", event)
+	}
+	event = readEvent(t, owner)
+	if event.Type != "added" || event.Entry == nil || event.Entry.Word != "краш" {
+		t.Errorf("владелец: %+v", event)
+	}
+	event = readEvent(t, anonymous)
+	if event.Type != "added" || event.Entry == nil || event.Entry.Word != "краш" {
+		t.Errorf("аноним: %+v", event)
+	}
+	added := event.ID
+
+	if resp := doRequest(t, srv, "PATCH", "/api/entries/1", bob, `{"example":"он мой краш"}`); resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	event = readEvent(t, anonymous)
+	if event.Type != "updated" || event.ID != added || event.Entry.Example != "он мой краш" {
+		t.Errorf("изменение: %+v", event)
+	}
+
+	if resp := doRequest(t, srv, "DELETE", "/api/entries/1", bob, ""); resp.status != http.StatusOK {
+		t.Fatalf("status = %d; body %s", resp.status, resp.body)
+	}
+	event = readEvent(t, anonymous)
+	// В общем словаре «краш» был единственным: личное слово не считается
+	if event.Type != "deleted" || event.ID != added || event.Index != 1 || event.Entry != nil {
+		t.Errorf("удаление: %+v", event)
+	}
+}
+
+func TestWebSocketRejectsBadToken(t *testing.T) {
+	srv, _ := newTestServer(t)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/ws?token=bad"
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("ожидалась ошибка подключения")
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("ответ: %+v", resp)
+	}
+}
+
+func TestEntryChanges(t *testing.T) {
+	before := []SlangEntry{
+		{ID: "a", Word: "краш", Meaning: "симпатия"},
+		{ID: "b", Word: "секрет", Meaning: "личное", Private: true},
+		{ID: "c", Word: "кринж", Meaning: "стыд"},
+		{ID: "d", Word: "изи", Meaning: "легко"},
+	}
+	after := []SlangEntry{
+		{ID: "c", Word: "кринж", Meaning: "испанский стыд"},
+		{ID: "e", Word: "рофл", Meaning: "шутка"},
+	}
+	var got []string
+	for _, event := range entryChanges(before, after) {
+		got = append(got, event.Type+" "+event.ID+" "+string(rune('0'+event.Index)))
+	}
+	// Удаления от больших номеров к меньшим; у личной записи номера нет
+	want := []string{"deleted d 3", "deleted b 0", "deleted a 1", "updated c 0", "added e 0"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("события: %v, want %v", got, want)
+	}
+}
+
+// Потоки событий проверяют токен так же, как requireAuth: токен, выданный до смены
+// пароля, и токен удалённого аккаунта поток не открывают
+func TestStreamsRejectStaleToken(t *testing.T) {
+	srv, store := newTestServer(t)
+	seedUser(t, store, "alice", "1234")
+	stale := tokenIssuedMinuteAgo(t, "alice")
+	user, _ := store.GetUser("alice")
+	user.PasswordChangedAt = time.Now()
+	if err := store.SetUser(user); err != nil {
+		t.Fatal(err)
+	}
+	bob := seedUser(t, store, "bob", "1234")
+	if err := modifySlangData(store, func(slangData *SlangData) error {
+		deleteUser(slangData, "bob", false)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"до смены пароля": stale, "удалённый аккаунт": bob} {
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/ws?token=" + token
+		if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s: /api/ws ответ %+v, ошибка %v", name, resp, err)
+		}
+		expectError(t, doRequest(t, srv, "GET", "/api/events?token="+token, "", ""), http.StatusUnauthorized, errCodeUnauthorized)
+	}
+}